@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var historyCommand = cli.Command{
+	Name:   "history",
+	Usage:  "show the history of job runs",
+	Action: historyApp,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "job, j",
+			Usage: "show only the runs of this job",
+		},
+		cli.IntFlag{
+			Name:  "last, n",
+			Usage: "show only the last runs, 0 for all",
+		},
+		cli.BoolFlag{
+			Name:  "json",
+			Usage: "print the runs in JSON with their output",
+		},
+	},
+}
+
+func historyApp(ctx *cli.Context) error {
+	if history == nil {
+		return errors.New("history is disabled, data-dir flag is empty")
+	}
+
+	runs, err := history.Runs(ctx.String("job"))
+	if err != nil {
+		return err
+	}
+
+	if n := ctx.Int("last"); n > 0 && n < len(runs) {
+		runs = runs[len(runs)-n:]
+	}
+
+	out := ctx.App.Writer
+	if ctx.Bool("json") {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(runs)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "START\tJOB\tSOURCE\tDURATION\tEXIT CODE\tERROR")
+	for _, r := range runs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", r.Start.Local().Format(time.RFC3339), r.Job, r.Source, r.Duration.Round(time.Millisecond), r.ExitCode, r.Error)
+	}
+	return w.Flush()
+}
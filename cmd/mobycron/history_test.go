@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pfillion/mobycron/pkg/cron"
+	"github.com/urfave/cli"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestHistoryApp(t *testing.T) {
+	type checkFunc func(*testing.T, string, error)
+	check := func(fns ...checkFunc) []checkFunc { return fns }
+
+	type mockFunc func(*MockHistory)
+
+	hasOutput := func(want string) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.Assert(t, is.Contains(out, want))
+		}
+	}
+
+	hasNotOutput := func(notWant string) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.Assert(t, !bytes.Contains([]byte(out), []byte(notWant)), "actual: %s", out)
+		}
+	}
+
+	hasError := func(want string) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.Assert(t, is.ErrorContains(err, want))
+		}
+	}
+
+	hasNilError := func() checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.NilError(t, err)
+		}
+	}
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+	runs := []cron.Run{
+		{Job: "job1", Source: "file", Start: start, Duration: 1500 * time.Millisecond, ExitCode: 0, Output: "out1"},
+		{Job: "job2", Source: "container", Start: start.Add(time.Minute), Duration: time.Second, ExitCode: 2, Error: "exit status 2"},
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		disabled bool
+		mock     mockFunc
+		checks   []checkFunc
+	}{
+		{
+			name: "table of all runs",
+			args: []string{"history"},
+			mock: func(h *MockHistory) {
+				h.EXPECT().Runs("").Return(runs, nil)
+			},
+			checks: check(
+				hasNilError(),
+				hasOutput("START"),
+				hasOutput("job1"),
+				hasOutput("1.5s"),
+				hasOutput("exit status 2"),
+				hasOutput(start.Format(time.RFC3339)),
+			),
+		},
+		{
+			name: "last runs of one job",
+			args: []string{"history", "--job=job2", "--last=1"},
+			mock: func(h *MockHistory) {
+				h.EXPECT().Runs("job2").Return(runs, nil)
+			},
+			checks: check(
+				hasNilError(),
+				hasOutput("job2"),
+				hasNotOutput("job1"),
+			),
+		},
+		{
+			name: "json",
+			args: []string{"history", "--json"},
+			mock: func(h *MockHistory) {
+				h.EXPECT().Runs("").Return(runs, nil)
+			},
+			checks: check(
+				hasNilError(),
+				hasOutput(`"output": "out1"`),
+				hasOutput(`"exitCode": 2`),
+			),
+		},
+		{
+			name: "history error",
+			args: []string{"history"},
+			mock: func(h *MockHistory) {
+				h.EXPECT().Runs("").Return(nil, errors.New("read error"))
+			},
+			checks: check(
+				hasError("read error"),
+			),
+		},
+		{
+			name:     "history disabled",
+			args:     []string{"history"},
+			disabled: true,
+			checks: check(
+				hasError("history is disabled, data-dir flag is empty"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			h := NewMockHistory(ctrl)
+			if tt.mock != nil {
+				tt.mock(h)
+			}
+
+			out := &bytes.Buffer{}
+			cmdRoot.Writer = out
+			defer func() { cmdRoot.Writer = os.Stdout }()
+			cmdRoot.Before = func(ctx *cli.Context) error {
+				history = h
+				if tt.disabled {
+					history = nil
+				}
+				return nil
+			}
+
+			// Act
+			err := cmdRoot.Run(append([]string{"mobycron"}, tt.args...))
+
+			// Assert
+			for _, check := range tt.checks {
+				check(t, out.String(), err)
+			}
+		})
+	}
+}
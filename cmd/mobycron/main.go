@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"io"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/pfillion/mobycron/pkg/cron"
 	"github.com/pkg/errors"
@@ -16,6 +19,12 @@ import (
 // specified by the schedule. It may be started and stopped.
 type Cronner interface {
 	LoadConfig(filename string) error
+	WatchConfig(filename string, interval time.Duration)
+	CatchUp(limit int)
+	FindJob(name string) (cron.JobInfo, error)
+	RunJob(ID int, w io.Writer) error
+	ValidateConfig(filename string) ([]cron.Validation, error)
+	PreviewConfig(filename string, from time.Time, count int) ([]cron.Preview, error)
 	Start()
 	Stop() context.Context
 }
@@ -26,24 +35,65 @@ type Handler interface {
 	ScanService() error
 	ListenContainer()
 	ListenService()
+	ReconcileContainer(interval time.Duration)
+	ReconcileService(interval time.Duration)
+	ValidateContainers() ([]cron.Validation, error)
+	ValidateServices() ([]cron.Validation, error)
+}
+
+// Server expose the jobs of cron through an HTTP API
+type Server interface {
+	ListenAndServe() error
+	Shutdown(ctx context.Context) error
+}
+
+// History read the runs of jobs recorded by cron
+type History interface {
+	Runs(job string) ([]cron.Run, error)
 }
 
 var (
 	osChan  chan os.Signal
 	handler Handler
 	cronner Cronner
+	server  Server
+	history History
 	cmdRoot *cli.App
 	cfg     = config{}
 )
 
 type config struct {
+	catchupMax  int
 	cfgFile     string
+	cfgFormat   string
+	cfgWatch    time.Duration
+	dataDir     string
 	dockerMode  string
+	dockerSync  time.Duration
+	historyAge  time.Duration
+	historyMax  int
+	httpListen  string
+	mailFrom    string
+	mailOn      string
+	mailSubject string
+	mailTmpl    string
+	mailTo      string
+	notifyOn    string
+	notifyTmpl  string
+	notifyURL   string
 	parseSecond bool
+	smtpHost    string
+	smtpPass    string
+	smtpPort    int
+	smtpTLS     bool
+	smtpUser    string
 }
 
 func initApp(ctx *cli.Context) error {
 	c := cron.NewCron(cfg.parseSecond)
+	if err := c.SetConfigFormat(cfg.cfgFormat); err != nil {
+		return err
+	}
 
 	switch cfg.dockerMode {
 	case "container", "swarm":
@@ -58,6 +108,55 @@ func initApp(ctx *cli.Context) error {
 		return errors.New("docker-mode flag is invalid")
 	}
 
+	server = nil
+	if cfg.httpListen != "" {
+		server = cron.NewServer(c, cfg.httpListen)
+	}
+
+	history = nil
+	if cfg.dataDir != "" {
+		h := cron.NewHistory(cfg.dataDir, cfg.historyMax, cfg.historyAge)
+		c.SetHistory(h)
+		c.SetState(cron.NewState(cfg.dataDir))
+		history = h
+	}
+
+	notify := cron.Notify{On: cfg.notifyOn, Template: cfg.notifyTmpl}
+	for _, u := range strings.Split(cfg.notifyURL, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			notify.URLs = append(notify.URLs, u)
+		}
+	}
+	n, err := cron.NewNotifier(notify)
+	if err != nil {
+		return err
+	}
+	c.SetNotifier(n)
+
+	if cfg.smtpHost != "" {
+		smtp := cron.SMTP{
+			Host:     cfg.smtpHost,
+			Port:     cfg.smtpPort,
+			StartTLS: cfg.smtpTLS,
+			From:     cfg.mailFrom,
+			To:       cfg.mailTo,
+			On:       cfg.mailOn,
+			Subject:  cfg.mailSubject,
+			Template: cfg.mailTmpl,
+		}
+		if smtp.Username, err = secret(cfg.smtpUser, "MOBYCRON_SMTP_USERNAME"); err != nil {
+			return err
+		}
+		if smtp.Password, err = secret(cfg.smtpPass, "MOBYCRON_SMTP_PASSWORD"); err != nil {
+			return err
+		}
+		m, err := cron.NewMailer(smtp)
+		if err != nil {
+			return err
+		}
+		c.SetMailer(m)
+	}
+
 	cronner = c
 	osChan = make(chan os.Signal)
 
@@ -67,6 +166,20 @@ func initApp(ctx *cli.Context) error {
 	return nil
 }
 
+// secret return value, or the content of the file named by the env variable
+// suffixed by '__FILE' when value is empty, like a Docker secret.
+func secret(value string, env string) (string, error) {
+	filename := os.Getenv(env + "__FILE")
+	if value != "" || filename == "" {
+		return value, nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read secret %s__FILE", env)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
 func startApp(ctx *cli.Context) error {
 	sig := []os.Signal{syscall.SIGINT, syscall.SIGTERM}
 
@@ -74,6 +187,9 @@ func startApp(ctx *cli.Context) error {
 		if err := cronner.LoadConfig(cfg.cfgFile); err != nil {
 			return err
 		}
+		if cfg.cfgWatch > 0 {
+			cronner.WatchConfig(cfg.cfgFile, cfg.cfgWatch)
+		}
 	}
 
 	if cfg.dockerMode == "container" {
@@ -81,6 +197,9 @@ func startApp(ctx *cli.Context) error {
 			return err
 		}
 		handler.ListenContainer()
+		if cfg.dockerSync > 0 {
+			handler.ReconcileContainer(cfg.dockerSync)
+		}
 	}
 
 	if cfg.dockerMode == "swarm" {
@@ -88,18 +207,51 @@ func startApp(ctx *cli.Context) error {
 			return err
 		}
 		handler.ListenService()
+		if cfg.dockerSync > 0 {
+			handler.ReconcileService(cfg.dockerSync)
+		}
 	}
 
+	cronner.CatchUp(cfg.catchupMax)
 	cronner.Start()
 
+	if server != nil {
+		go func() {
+			if err := server.ListenAndServe(); err != nil {
+				log.WithFields(log.Fields{
+					"func": "main.startApp",
+				}).WithError(err).Errorln("http server stopped with error")
+			}
+		}()
+	}
+
 	log.WithFields(log.Fields{
 		"func":   "main.startApp",
 		"signal": sig,
 	}).Infoln("cron is running and waiting signal for stop")
 
-	signal.Notify(osChan, sig...)
-	<-osChan
+	signal.Notify(osChan, append(sig, syscall.SIGHUP)...)
+	for s := range osChan {
+		if s != syscall.SIGHUP {
+			break
+		}
+		if cfg.cfgFile == "" {
+			continue
+		}
+
+		log := log.WithFields(log.Fields{
+			"func":   "main.startApp",
+			"signal": s,
+		})
+		log.Infoln("reload config file")
+		if err := cronner.LoadConfig(cfg.cfgFile); err != nil {
+			log.WithError(err).Errorln("config file rejected, previous jobs are kept")
+		}
+	}
 
+	if server != nil {
+		server.Shutdown(context.Background())
+	}
 	cronner.Stop()
 	// TODO: Refactoring of all log. Check if useful and complete. Think if it possible to have class for manage logging OR methods to make all fields correctly
 	// TODO: Refactoring of all test for check log with Fields like handler_test working with output but with field and value
@@ -132,6 +284,13 @@ func init() {
 			Value:       "none",
 			Usage:       "activate docker mode (swarm, container, none)",
 		},
+		cli.DurationFlag{
+			Name:        "docker-reconcile",
+			EnvVar:      "MOBYCRON_DOCKER_RECONCILE",
+			Destination: &cfg.dockerSync,
+			Value:       5 * time.Minute,
+			Usage:       "interval to compare the labeled containers or services with the jobs and fix the jobs missed by docker events, 0 to disable",
+		},
 		cli.BoolFlag{
 			Name:        "parse-second, s",
 			EnvVar:      "MOBYCRON_PARSE_SECOND",
@@ -144,5 +303,141 @@ func init() {
 			Destination: &cfg.cfgFile,
 			Usage:       "set file path to schedule all job like a crontab file",
 		},
+		cli.StringFlag{
+			Name:        "config-format",
+			EnvVar:      "MOBYCRON_CONFIG_FORMAT",
+			Destination: &cfg.cfgFormat,
+			Usage:       "set format of config file (json, yaml, toml, crontab, system-crontab) (default: guessed from file extension)",
+		},
+		cli.DurationFlag{
+			Name:        "config-watch, w",
+			EnvVar:      "MOBYCRON_CONFIG_WATCH",
+			Destination: &cfg.cfgWatch,
+			Value:       10 * time.Second,
+			Usage:       "interval to check the config file for changes and reload it, 0 to disable",
+		},
+		cli.StringFlag{
+			Name:        "http-listen, l",
+			EnvVar:      "MOBYCRON_HTTP_LISTEN",
+			Destination: &cfg.httpListen,
+			Usage:       "set address to listen for the HTTP API, like ':8080' (default: disabled)",
+		},
+		cli.StringFlag{
+			Name:        "data-dir, d",
+			EnvVar:      "MOBYCRON_DATA_DIR",
+			Destination: &cfg.dataDir,
+			Value:       "/var/lib/mobycron",
+			Usage:       "set directory where the history of job runs is stored, empty to disable",
+		},
+		cli.IntFlag{
+			Name:        "history-count",
+			EnvVar:      "MOBYCRON_HISTORY_COUNT",
+			Destination: &cfg.historyMax,
+			Value:       100,
+			Usage:       "number of runs kept in history by job, 0 for unlimited",
+		},
+		cli.DurationFlag{
+			Name:        "history-age",
+			EnvVar:      "MOBYCRON_HISTORY_AGE",
+			Destination: &cfg.historyAge,
+			Value:       30 * 24 * time.Hour,
+			Usage:       "maximum age of runs kept in history, 0 for unlimited",
+		},
+		cli.StringFlag{
+			Name:        "notify-url",
+			EnvVar:      "MOBYCRON_NOTIFY_URL",
+			Destination: &cfg.notifyURL,
+			Usage:       "set comma separated webhook urls notified of job runs (default: disabled)",
+		},
+		cli.StringFlag{
+			Name:        "notify-on",
+			EnvVar:      "MOBYCRON_NOTIFY_ON",
+			Destination: &cfg.notifyOn,
+			Value:       "failure",
+			Usage:       "comma separated conditions to notify a job run (failure, success, recovery, always)",
+		},
+		cli.StringFlag{
+			Name:        "notify-template",
+			EnvVar:      "MOBYCRON_NOTIFY_TEMPLATE",
+			Destination: &cfg.notifyTmpl,
+			Usage:       "set Go template of the webhook request body (default: JSON notification)",
+		},
+		cli.StringFlag{
+			Name:        "smtp-host",
+			EnvVar:      "MOBYCRON_SMTP_HOST",
+			Destination: &cfg.smtpHost,
+			Usage:       "set SMTP server host to mail the output of job runs (default: disabled)",
+		},
+		cli.IntFlag{
+			Name:        "smtp-port",
+			EnvVar:      "MOBYCRON_SMTP_PORT",
+			Destination: &cfg.smtpPort,
+			Value:       25,
+			Usage:       "set SMTP server port",
+		},
+		cli.BoolFlag{
+			Name:        "smtp-starttls",
+			EnvVar:      "MOBYCRON_SMTP_STARTTLS",
+			Destination: &cfg.smtpTLS,
+			Usage:       "upgrade the SMTP connection to TLS with STARTTLS (default: false)",
+		},
+		cli.StringFlag{
+			Name:        "smtp-username",
+			EnvVar:      "MOBYCRON_SMTP_USERNAME",
+			Destination: &cfg.smtpUser,
+			Usage:       "set SMTP username, or read it from the file of MOBYCRON_SMTP_USERNAME__FILE",
+		},
+		cli.StringFlag{
+			Name:        "smtp-password",
+			EnvVar:      "MOBYCRON_SMTP_PASSWORD",
+			Destination: &cfg.smtpPass,
+			Usage:       "set SMTP password, or read it from the file of MOBYCRON_SMTP_PASSWORD__FILE",
+		},
+		cli.StringFlag{
+			Name:        "mail-from",
+			EnvVar:      "MOBYCRON_MAIL_FROM",
+			Destination: &cfg.mailFrom,
+			Value:       "mobycron@localhost",
+			Usage:       "set sender address of the mails",
+		},
+		cli.StringFlag{
+			Name:        "mail-to",
+			EnvVar:      "MOBYCRON_MAIL_TO",
+			Destination: &cfg.mailTo,
+			Usage:       "set comma separated addresses mailed with the output of job runs, like MAILTO",
+		},
+		cli.StringFlag{
+			Name:        "mail-on",
+			EnvVar:      "MOBYCRON_MAIL_ON",
+			Destination: &cfg.mailOn,
+			Value:       "output",
+			Usage:       "condition to mail a job run (output, failure, always, never)",
+		},
+		cli.StringFlag{
+			Name:        "mail-subject",
+			EnvVar:      "MOBYCRON_MAIL_SUBJECT",
+			Destination: &cfg.mailSubject,
+			Usage:       "set Go template of the mail subject",
+		},
+		cli.StringFlag{
+			Name:        "mail-template",
+			EnvVar:      "MOBYCRON_MAIL_TEMPLATE",
+			Destination: &cfg.mailTmpl,
+			Usage:       "set Go template of the mail body",
+		},
+		cli.IntFlag{
+			Name:        "catchup-limit",
+			EnvVar:      "MOBYCRON_CATCHUP_LIMIT",
+			Destination: &cfg.catchupMax,
+			Value:       10,
+			Usage:       "maximum number of missed runs of a job caught up on startup with the 'all' policy",
+		},
+	}
+
+	cmdRoot.Commands = []cli.Command{
+		historyCommand,
+		nextCommand,
+		runCommand,
+		validateCommand,
 	}
 }
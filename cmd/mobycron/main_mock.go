@@ -6,9 +6,12 @@ package main
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	cron "github.com/pfillion/mobycron/pkg/cron"
 )
 
 // MockCronner is a mock of Cronner interface.
@@ -34,6 +37,33 @@ func (m *MockCronner) EXPECT() *MockCronnerMockRecorder {
 	return m.recorder
 }
 
+// CatchUp mocks base method.
+func (m *MockCronner) CatchUp(limit int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CatchUp", limit)
+}
+
+// CatchUp indicates an expected call of CatchUp.
+func (mr *MockCronnerMockRecorder) CatchUp(limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CatchUp", reflect.TypeOf((*MockCronner)(nil).CatchUp), limit)
+}
+
+// FindJob mocks base method.
+func (m *MockCronner) FindJob(name string) (cron.JobInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindJob", name)
+	ret0, _ := ret[0].(cron.JobInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindJob indicates an expected call of FindJob.
+func (mr *MockCronnerMockRecorder) FindJob(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindJob", reflect.TypeOf((*MockCronner)(nil).FindJob), name)
+}
+
 // LoadConfig mocks base method.
 func (m *MockCronner) LoadConfig(filename string) error {
 	m.ctrl.T.Helper()
@@ -48,6 +78,35 @@ func (mr *MockCronnerMockRecorder) LoadConfig(filename interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadConfig", reflect.TypeOf((*MockCronner)(nil).LoadConfig), filename)
 }
 
+// PreviewConfig mocks base method.
+func (m *MockCronner) PreviewConfig(filename string, from time.Time, count int) ([]cron.Preview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewConfig", filename, from, count)
+	ret0, _ := ret[0].([]cron.Preview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewConfig indicates an expected call of PreviewConfig.
+func (mr *MockCronnerMockRecorder) PreviewConfig(filename, from, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewConfig", reflect.TypeOf((*MockCronner)(nil).PreviewConfig), filename, from, count)
+}
+
+// RunJob mocks base method.
+func (m *MockCronner) RunJob(ID int, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunJob", ID, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunJob indicates an expected call of RunJob.
+func (mr *MockCronnerMockRecorder) RunJob(ID, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunJob", reflect.TypeOf((*MockCronner)(nil).RunJob), ID, w)
+}
+
 // Start mocks base method.
 func (m *MockCronner) Start() {
 	m.ctrl.T.Helper()
@@ -74,6 +133,33 @@ func (mr *MockCronnerMockRecorder) Stop() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockCronner)(nil).Stop))
 }
 
+// ValidateConfig mocks base method.
+func (m *MockCronner) ValidateConfig(filename string) ([]cron.Validation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateConfig", filename)
+	ret0, _ := ret[0].([]cron.Validation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateConfig indicates an expected call of ValidateConfig.
+func (mr *MockCronnerMockRecorder) ValidateConfig(filename interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateConfig", reflect.TypeOf((*MockCronner)(nil).ValidateConfig), filename)
+}
+
+// WatchConfig mocks base method.
+func (m *MockCronner) WatchConfig(filename string, interval time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "WatchConfig", filename, interval)
+}
+
+// WatchConfig indicates an expected call of WatchConfig.
+func (mr *MockCronnerMockRecorder) WatchConfig(filename, interval interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchConfig", reflect.TypeOf((*MockCronner)(nil).WatchConfig), filename, interval)
+}
+
 // MockHandler is a mock of Handler interface.
 type MockHandler struct {
 	ctrl     *gomock.Controller
@@ -121,6 +207,30 @@ func (mr *MockHandlerMockRecorder) ListenService() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListenService", reflect.TypeOf((*MockHandler)(nil).ListenService))
 }
 
+// ReconcileContainer mocks base method.
+func (m *MockHandler) ReconcileContainer(interval time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReconcileContainer", interval)
+}
+
+// ReconcileContainer indicates an expected call of ReconcileContainer.
+func (mr *MockHandlerMockRecorder) ReconcileContainer(interval interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileContainer", reflect.TypeOf((*MockHandler)(nil).ReconcileContainer), interval)
+}
+
+// ReconcileService mocks base method.
+func (m *MockHandler) ReconcileService(interval time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReconcileService", interval)
+}
+
+// ReconcileService indicates an expected call of ReconcileService.
+func (mr *MockHandlerMockRecorder) ReconcileService(interval interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileService", reflect.TypeOf((*MockHandler)(nil).ReconcileService), interval)
+}
+
 // ScanContainer mocks base method.
 func (m *MockHandler) ScanContainer() error {
 	m.ctrl.T.Helper()
@@ -148,3 +258,122 @@ func (mr *MockHandlerMockRecorder) ScanService() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanService", reflect.TypeOf((*MockHandler)(nil).ScanService))
 }
+
+// ValidateContainers mocks base method.
+func (m *MockHandler) ValidateContainers() ([]cron.Validation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateContainers")
+	ret0, _ := ret[0].([]cron.Validation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateContainers indicates an expected call of ValidateContainers.
+func (mr *MockHandlerMockRecorder) ValidateContainers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateContainers", reflect.TypeOf((*MockHandler)(nil).ValidateContainers))
+}
+
+// ValidateServices mocks base method.
+func (m *MockHandler) ValidateServices() ([]cron.Validation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateServices")
+	ret0, _ := ret[0].([]cron.Validation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateServices indicates an expected call of ValidateServices.
+func (mr *MockHandlerMockRecorder) ValidateServices() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateServices", reflect.TypeOf((*MockHandler)(nil).ValidateServices))
+}
+
+// MockServer is a mock of Server interface.
+type MockServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockServerMockRecorder
+}
+
+// MockServerMockRecorder is the mock recorder for MockServer.
+type MockServerMockRecorder struct {
+	mock *MockServer
+}
+
+// NewMockServer creates a new mock instance.
+func NewMockServer(ctrl *gomock.Controller) *MockServer {
+	mock := &MockServer{ctrl: ctrl}
+	mock.recorder = &MockServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockServer) EXPECT() *MockServerMockRecorder {
+	return m.recorder
+}
+
+// ListenAndServe mocks base method.
+func (m *MockServer) ListenAndServe() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListenAndServe")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListenAndServe indicates an expected call of ListenAndServe.
+func (mr *MockServerMockRecorder) ListenAndServe() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListenAndServe", reflect.TypeOf((*MockServer)(nil).ListenAndServe))
+}
+
+// Shutdown mocks base method.
+func (m *MockServer) Shutdown(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shutdown", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockServerMockRecorder) Shutdown(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockServer)(nil).Shutdown), ctx)
+}
+
+// MockHistory is a mock of History interface.
+type MockHistory struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryMockRecorder
+}
+
+// MockHistoryMockRecorder is the mock recorder for MockHistory.
+type MockHistoryMockRecorder struct {
+	mock *MockHistory
+}
+
+// NewMockHistory creates a new mock instance.
+func NewMockHistory(ctrl *gomock.Controller) *MockHistory {
+	mock := &MockHistory{ctrl: ctrl}
+	mock.recorder = &MockHistoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHistory) EXPECT() *MockHistoryMockRecorder {
+	return m.recorder
+}
+
+// Runs mocks base method.
+func (m *MockHistory) Runs(job string) ([]cron.Run, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Runs", job)
+	ret0, _ := ret[0].([]cron.Run)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Runs indicates an expected call of Runs.
+func (mr *MockHistoryMockRecorder) Runs(job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Runs", reflect.TypeOf((*MockHistory)(nil).Runs), job)
+}
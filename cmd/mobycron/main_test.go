@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"errors"
 	"os"
+	"path/filepath"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
@@ -52,6 +54,130 @@ func TestInitAppModeContainer(t *testing.T) {
 	assert.Assert(t, ok)
 }
 
+func TestInitAppHTTPListen(t *testing.T) {
+	cmdRoot.Before = initApp
+	cmdRoot.Action = func(ctx *cli.Context) error { return nil }
+
+	// Act
+	err := cmdRoot.Run([]string{"mobycron", "--http-listen=:8080"})
+
+	// Assert
+	assert.NilError(t, err)
+	assert.Assert(t, server != nil)
+
+	// Act
+	err = cmdRoot.Run([]string{"mobycron"})
+
+	// Assert
+	assert.NilError(t, err)
+	assert.Assert(t, is.Nil(server))
+}
+
+func TestInitAppDataDir(t *testing.T) {
+	cmdRoot.Before = initApp
+	cmdRoot.Action = func(ctx *cli.Context) error { return nil }
+
+	// Act
+	err := cmdRoot.Run([]string{"mobycron", "--data-dir=/tmp/mobycron"})
+
+	// Assert
+	assert.NilError(t, err)
+	assert.Assert(t, history != nil)
+
+	// Act
+	err = cmdRoot.Run([]string{"mobycron", "--data-dir="})
+
+	// Assert
+	assert.NilError(t, err)
+	assert.Assert(t, is.Nil(history))
+}
+
+func TestInitAppConfigFormat(t *testing.T) {
+	cmdRoot.Before = initApp
+	cmdRoot.Action = func(ctx *cli.Context) error { return nil }
+
+	// Act
+	err := cmdRoot.Run([]string{"mobycron", "--config-format=yaml"})
+
+	// Assert
+	assert.NilError(t, err)
+
+	// Act
+	err = cmdRoot.Run([]string{"mobycron", "--config-format=xml"})
+
+	// Assert
+	assert.Error(t, err, "invalid config format, only 'json', 'yaml', 'toml', 'crontab' and 'system-crontab' are permitted")
+}
+
+func TestInitAppNotify(t *testing.T) {
+	cmdRoot.Before = initApp
+	cmdRoot.Action = func(ctx *cli.Context) error { return nil }
+
+	// Act
+	err := cmdRoot.Run([]string{"mobycron", "--notify-url=http://hooks.local/a, http://hooks.local/b", "--notify-on=failure,recovery"})
+
+	// Assert
+	assert.NilError(t, err)
+
+	// Act
+	err = cmdRoot.Run([]string{"mobycron", "--notify-on=never"})
+
+	// Assert
+	assert.Error(t, err, "invalid notify condition, only 'failure', 'success', 'recovery' and 'always' are permitted")
+
+	// Act
+	err = cmdRoot.Run([]string{"mobycron", "--notify-url=ftp://hooks.local", "--notify-on=failure"})
+
+	// Assert
+	assert.Error(t, err, "invalid notify url 'ftp://hooks.local', only http and https urls are permitted")
+}
+
+func TestInitAppMail(t *testing.T) {
+	cmdRoot.Before = initApp
+	cmdRoot.Action = func(ctx *cli.Context) error { return nil }
+
+	// Act
+	err := cmdRoot.Run([]string{"mobycron", "--smtp-host=smtp.local", "--mail-to=ops@example.com", "--mail-on=failure"})
+
+	// Assert
+	assert.NilError(t, err)
+
+	// Act
+	err = cmdRoot.Run([]string{"mobycron", "--smtp-host=smtp.local", "--mail-on=sometimes"})
+
+	// Assert
+	assert.Error(t, err, "invalid mail condition, only 'output', 'failure', 'always' and 'never' are permitted")
+
+	// Act
+	os.Setenv("MOBYCRON_SMTP_PASSWORD__FILE", "/invalid/secret")
+	defer os.Unsetenv("MOBYCRON_SMTP_PASSWORD__FILE")
+	err = cmdRoot.Run([]string{"mobycron", "--smtp-host=smtp.local", "--mail-on=output"})
+
+	// Assert
+	assert.ErrorContains(t, err, "failed to read secret MOBYCRON_SMTP_PASSWORD__FILE")
+}
+
+func TestSecret(t *testing.T) {
+	// Arrange
+	filename := filepath.Join(t.TempDir(), "password")
+	os.WriteFile(filename, []byte("s3cret\n"), 0600)
+	os.Setenv("TEST_SECRET__FILE", filename)
+	defer os.Unsetenv("TEST_SECRET__FILE")
+
+	// Act
+	fromFile, err1 := secret("", "TEST_SECRET")
+	fromValue, err2 := secret("value", "TEST_SECRET")
+	empty, err3 := secret("", "TEST_NO_SECRET")
+
+	// Assert
+	assert.NilError(t, err1)
+	assert.Equal(t, fromFile, "s3cret")
+	assert.NilError(t, err2)
+	assert.Equal(t, fromValue, "value")
+	assert.NilError(t, err3)
+	assert.Equal(t, empty, "")
+}
+
 func TestInitAppHandlerError(t *testing.T) {
 	cmdRoot.Before = initApp
 	cmdRoot.Action = func(ctx *cli.Context) error { return nil }
@@ -166,9 +292,11 @@ func TestStartApp(t *testing.T) {
 	tests := []struct {
 		name   string
 		osChan chan os.Signal
+		hup    bool
 		sing   os.Signal
 		args   []string
 		mock   mockFunc
+		server func(*MockServer)
 		checks []checkFunc
 	}{
 		{
@@ -179,6 +307,8 @@ func TestStartApp(t *testing.T) {
 			mock: func(c *MockCronner, h *MockHandler) {
 				h.EXPECT().ScanService()
 				h.EXPECT().ListenService()
+				h.EXPECT().ReconcileService(5 * time.Minute)
+				c.EXPECT().CatchUp(10)
 				c.EXPECT().Start()
 				c.EXPECT().Stop()
 			},
@@ -195,6 +325,8 @@ func TestStartApp(t *testing.T) {
 			mock: func(c *MockCronner, h *MockHandler) {
 				h.EXPECT().ScanContainer()
 				h.EXPECT().ListenContainer()
+				h.EXPECT().ReconcileContainer(5 * time.Minute)
+				c.EXPECT().CatchUp(10)
 				c.EXPECT().Start()
 				c.EXPECT().Stop()
 			},
@@ -203,12 +335,29 @@ func TestStartApp(t *testing.T) {
 				hasOutput("cron is running and waiting signal for stop"),
 			),
 		},
+		{
+			name:   "run docker mode - container without reconcile",
+			osChan: make(chan os.Signal),
+			sing:   syscall.SIGINT,
+			args:   []string{"mobycron", "--docker-mode=container", "--docker-reconcile=0"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				h.EXPECT().ScanContainer()
+				h.EXPECT().ListenContainer()
+				c.EXPECT().CatchUp(10)
+				c.EXPECT().Start()
+				c.EXPECT().Stop()
+			},
+			checks: check(
+				hasNilError(),
+			),
+		},
 		{
 			name:   "run docker mode - none",
 			osChan: make(chan os.Signal),
 			sing:   syscall.SIGINT,
 			args:   []string{"mobycron", "--docker-mode=none"},
 			mock: func(c *MockCronner, h *MockHandler) {
+				c.EXPECT().CatchUp(10)
 				c.EXPECT().Start()
 				c.EXPECT().Stop()
 			},
@@ -217,6 +366,22 @@ func TestStartApp(t *testing.T) {
 				hasOutput("cron is running and waiting signal for stop"),
 			),
 		},
+		{
+			name:   "catch up missed runs",
+			osChan: make(chan os.Signal),
+			sing:   syscall.SIGINT,
+			args:   []string{"mobycron", "--docker-mode=none", "--catchup-limit=3"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				gomock.InOrder(
+					c.EXPECT().CatchUp(3),
+					c.EXPECT().Start(),
+				)
+				c.EXPECT().Stop()
+			},
+			checks: check(
+				hasNilError(),
+			),
+		},
 		{
 			name:   "run config file",
 			osChan: make(chan os.Signal),
@@ -224,6 +389,8 @@ func TestStartApp(t *testing.T) {
 			args:   []string{"mobycron", "--docker-mode=none", "--config-file=/etc/mobycron/config.json"},
 			mock: func(c *MockCronner, h *MockHandler) {
 				c.EXPECT().LoadConfig("/etc/mobycron/config.json").Return(nil)
+				c.EXPECT().WatchConfig("/etc/mobycron/config.json", 10*time.Second)
+				c.EXPECT().CatchUp(10)
 				c.EXPECT().Start()
 				c.EXPECT().Stop()
 			},
@@ -232,6 +399,92 @@ func TestStartApp(t *testing.T) {
 				hasOutput("cron is running and waiting signal for stop"),
 			),
 		},
+		{
+			name:   "run config file without watch",
+			osChan: make(chan os.Signal),
+			sing:   syscall.SIGINT,
+			args:   []string{"mobycron", "--docker-mode=none", "--config-file=/etc/mobycron/config.json", "--config-watch=0"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				c.EXPECT().LoadConfig("/etc/mobycron/config.json").Return(nil)
+				c.EXPECT().CatchUp(10)
+				c.EXPECT().Start()
+				c.EXPECT().Stop()
+			},
+			checks: check(
+				hasNilError(),
+			),
+		},
+		{
+			name:   "reload config file on SIGHUP",
+			osChan: make(chan os.Signal),
+			hup:    true,
+			sing:   syscall.SIGINT,
+			args:   []string{"mobycron", "--docker-mode=none", "--config-file=/etc/mobycron/config.json", "--config-watch=1m"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				c.EXPECT().LoadConfig("/etc/mobycron/config.json").Return(nil).Times(2)
+				c.EXPECT().WatchConfig("/etc/mobycron/config.json", time.Minute)
+				c.EXPECT().CatchUp(10)
+				c.EXPECT().Start()
+				c.EXPECT().Stop()
+			},
+			checks: check(
+				hasNilError(),
+				hasOutput("reload config file"),
+			),
+		},
+		{
+			name:   "reload config file in error on SIGHUP",
+			osChan: make(chan os.Signal),
+			hup:    true,
+			sing:   syscall.SIGINT,
+			args:   []string{"mobycron", "--docker-mode=none", "--config-file=/etc/mobycron/config.json", "--config-watch=0"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				c.EXPECT().LoadConfig("/etc/mobycron/config.json").Return(nil)
+				c.EXPECT().LoadConfig("/etc/mobycron/config.json").Return(errors.New("config error"))
+				c.EXPECT().CatchUp(10)
+				c.EXPECT().Start()
+				c.EXPECT().Stop()
+			},
+			checks: check(
+				hasNilError(),
+				hasOutput("config file rejected, previous jobs are kept"),
+				hasOutput("config error"),
+			),
+		},
+		{
+			name:   "SIGHUP without config file",
+			osChan: make(chan os.Signal),
+			hup:    true,
+			sing:   syscall.SIGINT,
+			args:   []string{"mobycron", "--docker-mode=none"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				c.EXPECT().CatchUp(10)
+				c.EXPECT().Start()
+				c.EXPECT().Stop()
+			},
+			checks: check(
+				hasNilError(),
+			),
+		},
+		{
+			name:   "run http server",
+			osChan: make(chan os.Signal),
+			sing:   syscall.SIGINT,
+			args:   []string{"mobycron", "--docker-mode=none"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				c.EXPECT().CatchUp(10)
+				c.EXPECT().Start()
+				c.EXPECT().Stop()
+			},
+			server: func(s *MockServer) {
+				s.EXPECT().ListenAndServe().AnyTimes()
+				s.EXPECT().Shutdown(gomock.Any())
+			},
+			checks: check(
+				hasNilError(),
+				hasOutput("cron is running and waiting signal for stop"),
+			),
+		},
 		{
 			name: "run config file in error",
 			args: []string{"mobycron", "--docker-mode=none", "--config-file=/etc/mobycron/config.json"},
@@ -273,6 +526,9 @@ func TestStartApp(t *testing.T) {
 
 			// Send terminating signal
 			go func() {
+				if tt.hup {
+					tt.osChan <- syscall.SIGHUP
+				}
 				tt.osChan <- tt.sing
 			}()
 
@@ -284,12 +540,19 @@ func TestStartApp(t *testing.T) {
 			if tt.mock != nil {
 				tt.mock(mc, mh)
 			}
+			var ms Server
+			if tt.server != nil {
+				m := NewMockServer(ctrl)
+				tt.server(m)
+				ms = m
+			}
 
 			// Inject mocks
 			cmdRoot.Before = func(ctx *cli.Context) error {
 				cronner = mc
 				osChan = tt.osChan
 				handler = mh
+				server = ms
 				return nil
 			}
 			cmdRoot.Action = startApp
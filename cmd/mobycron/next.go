@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pfillion/mobycron/pkg/cron"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var nextCommand = cli.Command{
+	Name:      "next",
+	Usage:     "show the description and the next times of a schedule or of the jobs of the config file",
+	ArgsUsage: "[schedule]",
+	Action:    nextApp,
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "count, n",
+			Value: 5,
+			Usage: "number of next times to show",
+		},
+		cli.StringFlag{
+			Name:  "timezone, z",
+			Value: "Local",
+			Usage: "time zone of the times shown, like 'America/New_York'",
+		},
+	},
+}
+
+func nextApp(ctx *cli.Context) error {
+	loc, err := time.LoadLocation(ctx.String("timezone"))
+	if err != nil {
+		return errors.Wrap(err, "invalid timezone flag")
+	}
+	count := ctx.Int("count")
+	if count <= 0 {
+		return errors.New("count flag must be a positive integer")
+	}
+
+	var previews []cron.Preview
+	switch spec := ctx.Args().First(); {
+	case spec != "":
+		p, err := cron.PreviewSchedule(spec, cfg.parseSecond, time.Now(), count)
+		if err != nil {
+			return err
+		}
+		previews = []cron.Preview{p}
+	case cfg.cfgFile != "":
+		previews, err = cronner.PreviewConfig(cfg.cfgFile, time.Now(), count)
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.New("schedule or config file is required")
+	}
+
+	out := ctx.App.Writer
+	for i, p := range previews {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		if p.Name != "" {
+			fmt.Fprintln(out, p.Name)
+		}
+		fmt.Fprintf(out, "  schedule:    %s\n", p.Schedule)
+		fmt.Fprintf(out, "  description: %s\n", p.Description)
+		if len(p.Next) == 0 {
+			fmt.Fprintln(out, "  next:        never")
+		}
+		for k, next := range p.Next {
+			label := "next:"
+			if k > 0 {
+				label = ""
+			}
+			fmt.Fprintf(out, "  %-12s %s\n", label, next.In(loc).Format(time.RFC3339))
+		}
+	}
+	return nil
+}
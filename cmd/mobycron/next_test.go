@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pfillion/mobycron/pkg/cron"
+	"github.com/urfave/cli"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestNextApp(t *testing.T) {
+	type checkFunc func(*testing.T, string, error)
+	check := func(fns ...checkFunc) []checkFunc { return fns }
+
+	hasOutput := func(want ...string) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			for _, w := range want {
+				assert.Assert(t, is.Contains(out, w))
+			}
+		}
+	}
+
+	hasLines := func(want int) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.Equal(t, strings.Count(out, "\n"), want)
+		}
+	}
+
+	hasError := func(want string) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.Assert(t, is.ErrorContains(err, want))
+		}
+	}
+
+	hasNilError := func() checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.NilError(t, err)
+		}
+	}
+
+	next := time.Date(2020, 1, 1, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		args   []string
+		mock   func(*MockCronner)
+		checks []checkFunc
+	}{
+		{
+			name: "schedule",
+			args: []string{"next", "0 2 * * 1-5"},
+			checks: check(
+				hasNilError(),
+				hasOutput("  schedule:    0 2 * * 1-5\n", "  description: At 02:00, on Monday through Friday\n", "  next:        "),
+				hasLines(7),
+			),
+		},
+		{
+			name: "schedule with seconds",
+			args: []string{"--parse-second", "next", "--count=2", "*/10 * * * * *"},
+			checks: check(
+				hasNilError(),
+				hasOutput("  description: Every 10 seconds\n"),
+				hasLines(4),
+			),
+		},
+		{
+			name: "invalid schedule",
+			args: []string{"next", "0 25 * * *"},
+			checks: check(
+				hasError("invalid schedule"),
+			),
+		},
+		{
+			name: "config file in a time zone",
+			args: []string{"--config-file=/etc/mobycron.json", "next", "--timezone=America/New_York", "--count=1"},
+			mock: func(c *MockCronner) {
+				c.EXPECT().PreviewConfig("/etc/mobycron.json", gomock.Any(), 1).Return([]cron.Preview{
+					{Name: "backup.sh", Schedule: "0 7 * * *", Description: "At 07:00", Next: []time.Time{next}},
+					{Name: "never.sh", Schedule: "0 0 30 2 *", Description: "At 00:00, on day 30 of the month, in February", Next: []time.Time{}},
+				}, nil)
+			},
+			checks: check(
+				hasNilError(),
+				hasOutput("backup.sh\n  schedule:    0 7 * * *\n  description: At 07:00\n  next:        2020-01-01T02:00:00-05:00\n\nnever.sh\n"),
+				hasOutput("  next:        never\n"),
+			),
+		},
+		{
+			name: "config file error",
+			args: []string{"--config-file=/etc/mobycron.json", "next"},
+			mock: func(c *MockCronner) {
+				c.EXPECT().PreviewConfig("/etc/mobycron.json", gomock.Any(), 5).Return(nil, errors.New("failed to read config file"))
+			},
+			checks: check(
+				hasError("failed to read config file"),
+			),
+		},
+		{
+			name: "nothing to preview",
+			args: []string{"next"},
+			checks: check(
+				hasError("schedule or config file is required"),
+			),
+		},
+		{
+			name: "invalid timezone",
+			args: []string{"next", "--timezone=Mars/Olympus", "@daily"},
+			checks: check(
+				hasError("invalid timezone flag"),
+			),
+		},
+		{
+			name: "invalid count",
+			args: []string{"next", "--count=0", "@daily"},
+			checks: check(
+				hasError("count flag must be a positive integer"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			c := NewMockCronner(ctrl)
+			if tt.mock != nil {
+				tt.mock(c)
+			}
+
+			out := &bytes.Buffer{}
+			cmdRoot.Writer = out
+			defer func() { cmdRoot.Writer = os.Stdout }()
+			cmdRoot.Before = func(ctx *cli.Context) error {
+				cronner = c
+				return nil
+			}
+
+			// Act
+			err := cmdRoot.Run(append([]string{"mobycron"}, tt.args...))
+
+			// Assert
+			for _, check := range tt.checks {
+				check(t, out.String(), err)
+			}
+		})
+	}
+}
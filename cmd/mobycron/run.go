@@ -0,0 +1,86 @@
+package main
+
+import (
+	"github.com/pfillion/mobycron/pkg/cron"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// runRemote run a job through the HTTP API of a daemon, it is replaced in
+// tests.
+var runRemote = cron.RunRemote
+
+var runCommand = cli.Command{
+	Name:      "run",
+	Usage:     "run a job now in the foreground and exit with its exit code",
+	ArgsUsage: "<job name or ID>",
+	Action:    runApp,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "scan",
+			Usage: "also load the jobs of labeled containers or services in docker mode",
+		},
+	},
+}
+
+func runApp(ctx *cli.Context) error {
+	name := ctx.Args().First()
+	if name == "" {
+		return errors.New("job name or ID is required")
+	}
+
+	// Keep the standard output for the output of the job.
+	log.SetOutput(cli.ErrWriter)
+	out := ctx.App.Writer
+
+	if cfg.httpListen != "" {
+		err := runRemote(cron.APIURL(cfg.httpListen), name, out)
+		if errors.Cause(err) != cron.ErrDaemonUnavailable {
+			return exitError(err)
+		}
+		log.WithFields(log.Fields{
+			"func": "main.runApp",
+		}).WithError(err).Infoln("daemon not available, run job locally")
+	}
+
+	if cfg.cfgFile != "" {
+		if err := cronner.LoadConfig(cfg.cfgFile); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Bool("scan") {
+		switch cfg.dockerMode {
+		case "container":
+			if err := handler.ScanContainer(); err != nil {
+				return err
+			}
+		case "swarm":
+			if err := handler.ScanService(); err != nil {
+				return err
+			}
+		}
+	}
+
+	job, err := cronner.FindJob(name)
+	if err != nil {
+		return err
+	}
+	err = cronner.RunJob(job.ID, out)
+	cronner.Stop()
+	return exitError(err)
+}
+
+// exitError return the error of a job run exiting with its exit code, or 1
+// when the run has no exit code.
+func exitError(err error) error {
+	if err == nil {
+		return nil
+	}
+	code := cron.ExitCode(err)
+	if code <= 0 {
+		code = 1
+	}
+	return cli.NewExitError(err.Error(), code)
+}
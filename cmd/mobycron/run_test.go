@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pfillion/mobycron/pkg/cron"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/urfave/cli"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestRunApp(t *testing.T) {
+	type checkFunc func(*testing.T, string, int, error)
+	check := func(fns ...checkFunc) []checkFunc { return fns }
+
+	type mockFunc func(*MockCronner, *MockHandler)
+
+	hasOutput := func(want string) checkFunc {
+		return func(t *testing.T, out string, code int, err error) {
+			assert.Equal(t, out, want)
+		}
+	}
+
+	hasExitCode := func(want int) checkFunc {
+		return func(t *testing.T, out string, code int, err error) {
+			assert.Equal(t, code, want)
+		}
+	}
+
+	hasError := func(want string) checkFunc {
+		return func(t *testing.T, out string, code int, err error) {
+			assert.Assert(t, is.ErrorContains(err, want))
+		}
+	}
+
+	hasNilError := func() checkFunc {
+		return func(t *testing.T, out string, code int, err error) {
+			assert.NilError(t, err)
+		}
+	}
+
+	runJob := func(output string, err error) func(int, io.Writer) error {
+		return func(ID int, w io.Writer) error {
+			io.WriteString(w, output)
+			return err
+		}
+	}
+
+	// Error of a process completed with the exit code 3
+	exit3 := exec.Command("sh", "-c", "exit 3").Run()
+
+	tests := []struct {
+		name   string
+		args   []string
+		remote func(string, string, io.Writer) error
+		mock   mockFunc
+		checks []checkFunc
+	}{
+		{
+			name: "job name required",
+			args: []string{"run"},
+			checks: check(
+				hasError("job name or ID is required"),
+			),
+		},
+		{
+			name: "run job from config file",
+			args: []string{"--config-file=/etc/mobycron.json", "run", "backup.sh"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				c.EXPECT().LoadConfig("/etc/mobycron.json")
+				c.EXPECT().FindJob("backup.sh").Return(cron.JobInfo{ID: 2}, nil)
+				c.EXPECT().RunJob(2, gomock.Any()).DoAndReturn(runJob("done\n", nil))
+				c.EXPECT().Stop()
+			},
+			checks: check(
+				hasNilError(),
+				hasOutput("done\n"),
+				hasExitCode(0),
+			),
+		},
+		{
+			name: "run job with exit code",
+			args: []string{"--config-file=/etc/mobycron.json", "run", "backup.sh"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				c.EXPECT().LoadConfig("/etc/mobycron.json")
+				c.EXPECT().FindJob("backup.sh").Return(cron.JobInfo{ID: 2}, nil)
+				c.EXPECT().RunJob(2, gomock.Any()).DoAndReturn(runJob("failed\n", exit3))
+				c.EXPECT().Stop()
+			},
+			checks: check(
+				hasError("exit status 3"),
+				hasOutput("failed\n"),
+				hasExitCode(3),
+			),
+		},
+		{
+			name: "run job without exit code",
+			args: []string{"--docker-mode=container", "run", "--scan", "db-backup"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				h.EXPECT().ScanContainer()
+				c.EXPECT().FindJob("db-backup").Return(cron.JobInfo{ID: 1}, nil)
+				c.EXPECT().RunJob(1, gomock.Any()).Return(errors.New("container not found"))
+				c.EXPECT().Stop()
+			},
+			checks: check(
+				hasError("container not found"),
+				hasExitCode(1),
+			),
+		},
+		{
+			name: "scan services",
+			args: []string{"--docker-mode=swarm", "run", "--scan", "web-update"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				h.EXPECT().ScanService().Return(errors.New("scan error"))
+			},
+			checks: check(
+				hasError("scan error"),
+			),
+		},
+		{
+			name: "job not found",
+			args: []string{"run", "unknown"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				c.EXPECT().FindJob("unknown").Return(cron.JobInfo{}, cron.ErrJobNotFound)
+			},
+			checks: check(
+				hasError("job not found"),
+			),
+		},
+		{
+			name: "config file error",
+			args: []string{"--config-file=/etc/mobycron.json", "run", "backup.sh"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				c.EXPECT().LoadConfig("/etc/mobycron.json").Return(errors.New("invalid config"))
+			},
+			checks: check(
+				hasError("invalid config"),
+			),
+		},
+		{
+			name: "forward to daemon",
+			args: []string{"--http-listen=:8080", "run", "backup.sh"},
+			remote: func(url string, name string, w io.Writer) error {
+				io.WriteString(w, url+" "+name)
+				return exit3
+			},
+			checks: check(
+				hasError("exit status 3"),
+				hasOutput("http://127.0.0.1:8080 backup.sh"),
+				hasExitCode(3),
+			),
+		},
+		{
+			name: "daemon not available",
+			args: []string{"--http-listen=:8080", "run", "backup.sh"},
+			remote: func(url string, name string, w io.Writer) error {
+				return pkgerrors.Wrap(cron.ErrDaemonUnavailable, "connection refused")
+			},
+			mock: func(c *MockCronner, h *MockHandler) {
+				c.EXPECT().FindJob("backup.sh").Return(cron.JobInfo{ID: 2}, nil)
+				c.EXPECT().RunJob(2, gomock.Any()).DoAndReturn(runJob("local\n", nil))
+				c.EXPECT().Stop()
+			},
+			checks: check(
+				hasNilError(),
+				hasOutput("local\n"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			c := NewMockCronner(ctrl)
+			h := NewMockHandler(ctrl)
+			if tt.mock != nil {
+				tt.mock(c, h)
+			}
+
+			defer func(r func(string, string, io.Writer) error) { runRemote = r }(runRemote)
+			runRemote = tt.remote
+
+			code := 0
+			defer func(e func(int)) { cli.OsExiter = e }(cli.OsExiter)
+			cli.OsExiter = func(c int) { code = c }
+			defer func(w io.Writer) { cli.ErrWriter = w }(cli.ErrWriter)
+			cli.ErrWriter = &bytes.Buffer{}
+
+			out := &bytes.Buffer{}
+			cmdRoot.Writer = out
+			defer func() { cmdRoot.Writer = os.Stdout }()
+			cmdRoot.Before = func(ctx *cli.Context) error {
+				cronner = c
+				handler = h
+				return nil
+			}
+
+			// Act
+			err := cmdRoot.Run(append([]string{"mobycron"}, tt.args...))
+
+			// Assert
+			for _, check := range tt.checks {
+				check(t, out.String(), code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pfillion/mobycron/pkg/cron"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+var validateCommand = cli.Command{
+	Name:      "validate",
+	Usage:     "validate the jobs of a config file and of labeled containers or services in docker mode, and show their next times",
+	ArgsUsage: "[config file]",
+	Action:    validateApp,
+}
+
+func validateApp(ctx *cli.Context) error {
+	filename := ctx.Args().First()
+	if filename == "" {
+		filename = cfg.cfgFile
+	}
+	if filename == "" && handler == nil {
+		return errors.New("nothing to validate, config file or docker-mode flag is required")
+	}
+
+	// Keep the standard output for the report.
+	log.SetOutput(cli.ErrWriter)
+
+	validations := []cron.Validation{}
+	if filename != "" {
+		v, err := cronner.ValidateConfig(filename)
+		if err != nil {
+			return err
+		}
+		validations = append(validations, v...)
+	}
+
+	switch cfg.dockerMode {
+	case "container":
+		v, err := handler.ValidateContainers()
+		if err != nil {
+			return err
+		}
+		validations = append(validations, v...)
+	case "swarm":
+		v, err := handler.ValidateServices()
+		if err != nil {
+			return err
+		}
+		validations = append(validations, v...)
+	}
+
+	invalid := 0
+	w := tabwriter.NewWriter(ctx.App.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tJOB\tSCHEDULE\tNEXT")
+	for _, v := range validations {
+		if v.Err != nil {
+			invalid++
+			fmt.Fprintf(w, "%s\t%s\t%s\tinvalid: %s\n", v.Source, v.Name, v.Schedule, v.Err)
+			continue
+		}
+		if len(v.Next) == 0 {
+			fmt.Fprintf(w, "%s\t%s\t%s\tnever\n", v.Source, v.Name, v.Schedule)
+			continue
+		}
+		for i, next := range v.Next {
+			if i == 0 {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", v.Source, v.Name, v.Schedule, next.Local().Format(time.RFC3339))
+			} else {
+				fmt.Fprintf(w, "\t\t\t%s\n", next.Local().Format(time.RFC3339))
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if invalid > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d of %d jobs are invalid", invalid, len(validations)), 1)
+	}
+	fmt.Fprintf(ctx.App.Writer, "%d jobs are valid\n", len(validations))
+	return nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pfillion/mobycron/pkg/cron"
+	"github.com/urfave/cli"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestValidateApp(t *testing.T) {
+	type checkFunc func(*testing.T, string, int, error)
+	check := func(fns ...checkFunc) []checkFunc { return fns }
+
+	type mockFunc func(*MockCronner, *MockHandler)
+
+	hasOutput := func(want ...string) checkFunc {
+		return func(t *testing.T, out string, code int, err error) {
+			for _, w := range want {
+				assert.Assert(t, is.Contains(out, w))
+			}
+		}
+	}
+
+	hasExitCode := func(want int) checkFunc {
+		return func(t *testing.T, out string, code int, err error) {
+			assert.Equal(t, code, want)
+		}
+	}
+
+	hasError := func(want string) checkFunc {
+		return func(t *testing.T, out string, code int, err error) {
+			assert.Assert(t, is.ErrorContains(err, want))
+		}
+	}
+
+	hasNilError := func() checkFunc {
+		return func(t *testing.T, out string, code int, err error) {
+			assert.NilError(t, err)
+		}
+	}
+
+	next := time.Date(2026, 10, 18, 2, 0, 0, 0, time.Local)
+	valid := cron.Validation{Name: "backup.sh", Source: "file", Schedule: "0 2 * * *", Next: []time.Time{next, next.AddDate(0, 0, 1)}}
+	invalid := cron.Validation{Name: "db-kill", Source: "container", Schedule: "@daily", Err: errors.New("invalid container action")}
+
+	tests := []struct {
+		name    string
+		args    []string
+		handler bool
+		mock    mockFunc
+		checks  []checkFunc
+	}{
+		{
+			name: "nothing to validate",
+			args: []string{"validate"},
+			checks: check(
+				hasError("nothing to validate, config file or docker-mode flag is required"),
+			),
+		},
+		{
+			name: "valid config file",
+			args: []string{"validate", "/etc/mobycron.json"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				c.EXPECT().ValidateConfig("/etc/mobycron.json").Return([]cron.Validation{valid}, nil)
+			},
+			checks: check(
+				hasNilError(),
+				hasOutput(
+					"file    backup.sh  0 2 * * *  "+next.Format(time.RFC3339)+"\n",
+					"                             "+next.AddDate(0, 0, 1).Format(time.RFC3339)+"\n",
+					"1 jobs are valid\n",
+				),
+				hasExitCode(0),
+			),
+		},
+		{
+			name: "config file from flag",
+			args: []string{"--config-file=/etc/mobycron.json", "validate"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				c.EXPECT().ValidateConfig("/etc/mobycron.json").Return([]cron.Validation{}, nil)
+			},
+			checks: check(
+				hasNilError(),
+				hasOutput("0 jobs are valid\n"),
+			),
+		},
+		{
+			name: "invalid config file",
+			args: []string{"validate", "/etc/mobycron.json"},
+			mock: func(c *MockCronner, h *MockHandler) {
+				c.EXPECT().ValidateConfig("/etc/mobycron.json").Return(nil, errors.New("failed to parse JSON data from config file"))
+			},
+			checks: check(
+				hasError("failed to parse JSON data from config file"),
+			),
+		},
+		{
+			name:    "invalid container job",
+			args:    []string{"--docker-mode=container", "validate", "/etc/mobycron.json"},
+			handler: true,
+			mock: func(c *MockCronner, h *MockHandler) {
+				c.EXPECT().ValidateConfig("/etc/mobycron.json").Return([]cron.Validation{valid}, nil)
+				h.EXPECT().ValidateContainers().Return([]cron.Validation{invalid}, nil)
+			},
+			checks: check(
+				hasError("1 of 2 jobs are invalid"),
+				hasOutput("container  db-kill    @daily     invalid: invalid container action\n"),
+				hasExitCode(1),
+			),
+		},
+		{
+			name:    "service jobs",
+			args:    []string{"--docker-mode=swarm", "validate"},
+			handler: true,
+			mock: func(c *MockCronner, h *MockHandler) {
+				h.EXPECT().ValidateServices().Return([]cron.Validation{{Name: "web-update", Source: "service", Schedule: "0 0 30 2 *"}}, nil)
+			},
+			checks: check(
+				hasNilError(),
+				hasOutput("service  web-update  0 0 30 2 *  never\n"),
+			),
+		},
+		{
+			name:    "docker error",
+			args:    []string{"--docker-mode=swarm", "validate"},
+			handler: true,
+			mock: func(c *MockCronner, h *MockHandler) {
+				h.EXPECT().ValidateServices().Return(nil, errors.New("docker not available"))
+			},
+			checks: check(
+				hasError("docker not available"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			c := NewMockCronner(ctrl)
+			h := NewMockHandler(ctrl)
+			if tt.mock != nil {
+				tt.mock(c, h)
+			}
+
+			code := 0
+			defer func(e func(int)) { cli.OsExiter = e }(cli.OsExiter)
+			cli.OsExiter = func(c int) { code = c }
+			defer func(w io.Writer) { cli.ErrWriter = w }(cli.ErrWriter)
+			cli.ErrWriter = &bytes.Buffer{}
+
+			out := &bytes.Buffer{}
+			cmdRoot.Writer = out
+			defer func() { cmdRoot.Writer = os.Stdout }()
+			cmdRoot.Before = func(ctx *cli.Context) error {
+				cronner = c
+				handler = nil
+				if tt.handler {
+					handler = h
+				}
+				return nil
+			}
+
+			// Act
+			err := cmdRoot.Run(append([]string{"mobycron"}, tt.args...))
+
+			// Assert
+			for _, check := range tt.checks {
+				check(t, out.String(), code, err)
+			}
+		})
+	}
+}
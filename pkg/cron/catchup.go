@@ -0,0 +1,199 @@
+package cron
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	cron "github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// Catch-up policies applied on startup to the runs missed while mobycron was
+// not running.
+const (
+	CatchupNone = "none"
+	CatchupOnce = "once"
+	CatchupAll  = "all"
+)
+
+// stateFile is the name of the file in the data directory keeping the last
+// successful run of each job.
+const stateFile = "state.json"
+
+// scheduledJob is a job of any source scheduled in Cron.
+type scheduledJob interface {
+	cron.Job
+	labels() prometheus.Labels
+	id() string
+	scheduleSpec() string
+	catchupPolicy() string
+	notifyPolicy() Notify
+	mailPolicy() (string, string)
+	runWith(w io.Writer) error
+	validate() error
+}
+
+func validateCatchup(policy string) error {
+	switch policy {
+	case "", CatchupNone, CatchupOnce, CatchupAll:
+		return nil
+	default:
+		return errors.New("invalid catch-up policy, only 'none', 'once' and 'all' are permitted")
+	}
+}
+
+// State persist the time of the last successful run of each job, by job
+// identity, in the data directory.
+type State struct {
+	fs       afero.Fs
+	filename string
+	last     map[string]time.Time
+	mu       sync.Mutex
+}
+
+// NewState returns a state stored in dir.
+func NewState(dir string) *State {
+	return &State{
+		fs:       afero.NewOsFs(),
+		filename: filepath.Join(dir, stateFile),
+	}
+}
+
+// LastSuccess return the start time of the last successful run of the job
+// with the identity id, the zero time when the job never succeeded.
+func (s *State) LastSuccess(id string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return time.Time{}, err
+	}
+	return s.last[id], nil
+}
+
+// SetLastSuccess set the start time of the last successful run of the job
+// with the identity id.
+func (s *State) SetLastSuccess(id string, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.last[id] = t
+
+	data, err := json.MarshalIndent(s.last, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode state")
+	}
+	if err := s.fs.MkdirAll(filepath.Dir(s.filename), 0750); err != nil {
+		return errors.Wrap(err, "failed to create data directory")
+	}
+
+	// Replace the file at once so that a crash never leave a partial state.
+	tmp := s.filename + ".tmp"
+	if err := afero.WriteFile(s.fs, tmp, data, 0640); err != nil {
+		return errors.Wrap(err, "failed to write state file")
+	}
+	if err := s.fs.Rename(tmp, s.filename); err != nil {
+		return errors.Wrap(err, "failed to write state file")
+	}
+	return nil
+}
+
+// load read the state file once.
+func (s *State) load() error {
+	if s.last != nil {
+		return nil
+	}
+
+	last := map[string]time.Time{}
+	data, err := afero.ReadFile(s.fs, s.filename)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to read state file")
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &last); err != nil {
+			return errors.Wrap(err, "failed to parse state file")
+		}
+	}
+	s.last = last
+	return nil
+}
+
+// SetState set the state where the last successful run of each job is kept.
+func (c *Cron) SetState(s *State) {
+	c.state = s
+}
+
+// CatchUp run the jobs that missed runs on their schedule since their last
+// successful run, according to their catch-up policy. The 'all' policy run
+// at most limit missed runs of a job, one after the other. Jobs that never
+// succeeded are not caught up.
+func (c *Cron) CatchUp(limit int) {
+	if c.state == nil {
+		return
+	}
+
+	now := time.Now()
+	for _, entry := range c.runner.Entries() {
+		job, ok := entry.Job.(scheduledJob)
+		if !ok {
+			continue
+		}
+		policy := job.catchupPolicy()
+		if policy == "" || policy == CatchupNone {
+			continue
+		}
+
+		log := log.WithFields(log.Fields{
+			"func":    "Cron.CatchUp",
+			"job":     job.id(),
+			"catchup": policy,
+		})
+
+		last, err := c.state.LastSuccess(job.id())
+		if err != nil {
+			log.WithError(err).Errorln("failed to read last successful run")
+			continue
+		}
+		if last.IsZero() {
+			continue
+		}
+
+		max := 1
+		if policy == CatchupAll {
+			max = limit
+		}
+		missed := missedRuns(entry.Schedule, last, now, max)
+		if missed == 0 {
+			continue
+		}
+
+		log.WithField("last", last).WithField("missed", missed).Infoln("catch up missed runs")
+
+		c.sync.Add(1)
+		go func(j cron.Job, n int) {
+			defer c.sync.Done()
+			for i := 0; i < n; i++ {
+				j.Run()
+			}
+		}(entry.Job, missed)
+	}
+}
+
+// missedRuns count the runs of schedule after last and before now, up to max.
+func missedRuns(schedule cron.Schedule, last time.Time, now time.Time, max int) int {
+	n := 0
+	for t := schedule.Next(last); n < max && !t.IsZero() && t.Before(now); t = schedule.Next(t) {
+		n++
+	}
+	return n
+}
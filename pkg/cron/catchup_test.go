@@ -0,0 +1,221 @@
+package cron
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	cron "github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func newTestState() *State {
+	s := NewState("/data")
+	s.fs = afero.NewMemMapFs()
+	return s
+}
+
+func TestValidateCatchup(t *testing.T) {
+	for _, policy := range []string{"", CatchupNone, CatchupOnce, CatchupAll} {
+		assert.NilError(t, validateCatchup(policy))
+	}
+	assert.Error(t, validateCatchup("always"), "invalid catch-up policy, only 'none', 'once' and 'all' are permitted")
+}
+
+func TestState(t *testing.T) {
+	// Arrange
+	s := newTestState()
+	now := time.Now().Round(0)
+
+	// Act
+	err := s.SetLastSuccess("file/a", now)
+
+	// Assert
+	assert.NilError(t, err)
+
+	reloaded := NewState("/data")
+	reloaded.fs = s.fs
+	last, err := reloaded.LastSuccess("file/a")
+	assert.NilError(t, err)
+	assert.Assert(t, last.Equal(now))
+
+	last, err = reloaded.LastSuccess("file/b")
+	assert.NilError(t, err)
+	assert.Assert(t, last.IsZero())
+}
+
+func TestStateErrors(t *testing.T) {
+	// Arrange
+	s := newTestState()
+	afero.WriteFile(s.fs, s.filename, []byte("{"), 0640)
+
+	// Act
+	_, err := s.LastSuccess("file/a")
+
+	// Assert
+	assert.Assert(t, is.ErrorContains(err, "failed to parse state file"))
+
+	// Arrange
+	s = newTestState()
+	s.fs = afero.NewReadOnlyFs(afero.NewMemMapFs())
+
+	// Act
+	err = s.SetLastSuccess("file/a", time.Now())
+
+	// Assert
+	assert.Assert(t, is.ErrorContains(err, "failed to"))
+}
+
+func TestMissedRuns(t *testing.T) {
+	hourly, _ := cron.ParseStandard("0 * * * *")
+	last := time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, missedRuns(hourly, last, last.Add(30*time.Minute), 10), 0)
+	assert.Equal(t, missedRuns(hourly, last, last.Add(3*time.Hour+time.Minute), 10), 3)
+	assert.Equal(t, missedRuns(hourly, last, last.Add(3*time.Hour+time.Minute), 2), 2)
+}
+
+func TestCronCatchUp(t *testing.T) {
+	type checkFunc func(*testing.T, string)
+	check := func(fns ...checkFunc) []checkFunc { return fns }
+
+	hasLog := func(want string) checkFunc {
+		return func(t *testing.T, out string) {
+			assert.Assert(t, is.Contains(out, want))
+		}
+	}
+
+	hasNoLog := func(want string) checkFunc {
+		return func(t *testing.T, out string) {
+			assert.Assert(t, !strings.Contains(out, want), out)
+		}
+	}
+
+	hasRuns := func(want int) checkFunc {
+		return func(t *testing.T, out string) {
+			assert.Equal(t, strings.Count(out, "job completed successfully"), want)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		policy string
+		ago    time.Duration
+		limit  int
+		checks []checkFunc
+	}{
+		{
+			name:   "no policy",
+			ago:    3*time.Hour + time.Minute,
+			limit:  10,
+			checks: check(hasNoLog("catch up missed runs"), hasRuns(0)),
+		},
+		{
+			name:   "none",
+			policy: CatchupNone,
+			ago:    3*time.Hour + time.Minute,
+			limit:  10,
+			checks: check(hasNoLog("catch up missed runs"), hasRuns(0)),
+		},
+		{
+			name:   "once",
+			policy: CatchupOnce,
+			ago:    3*time.Hour + time.Minute,
+			limit:  10,
+			checks: check(hasLog("\"missed\":1"), hasRuns(1)),
+		},
+		{
+			name:   "all",
+			policy: CatchupAll,
+			ago:    3*time.Hour + time.Minute,
+			limit:  10,
+			checks: check(hasLog("\"missed\":3"), hasRuns(3)),
+		},
+		{
+			name:   "all up to limit",
+			policy: CatchupAll,
+			ago:    3*time.Hour + time.Minute,
+			limit:  2,
+			checks: check(hasLog("\"missed\":2"), hasRuns(2)),
+		},
+		{
+			name:   "nothing missed",
+			policy: CatchupAll,
+			ago:    time.Second,
+			limit:  10,
+			checks: check(hasNoLog("catch up missed runs"), hasRuns(0)),
+		},
+		{
+			name:   "never succeeded",
+			policy: CatchupAll,
+			limit:  10,
+			checks: check(hasNoLog("catch up missed runs"), hasRuns(0)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			out := &bytes.Buffer{}
+			log.SetOutput(out)
+			log.SetFormatter(&log.JSONFormatter{})
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			r := NewMockRunner(ctrl)
+			s := &sync.WaitGroup{}
+
+			c := &Cron{runner: r, sync: s, state: newTestState()}
+			schedule := cron.Every(time.Hour)
+			j := &Job{Schedule: "@every 1h", Command: "echo", Args: []string{"caught"}, Catchup: tt.policy, cron: c, guard: newGuard("")}
+			if tt.ago > 0 {
+				c.state.SetLastSuccess(j.id(), time.Now().Add(-tt.ago))
+			}
+			r.EXPECT().Entries().Return([]cron.Entry{{ID: 1, Schedule: schedule, Job: j}})
+
+			// Act
+			c.CatchUp(tt.limit)
+			s.Wait()
+
+			// Assert
+			for _, check := range tt.checks {
+				check(t, out.String())
+			}
+		})
+	}
+}
+
+func TestCronCatchUpWithoutState(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	r := NewMockRunner(ctrl)
+	c := &Cron{runner: r}
+
+	// Act
+	c.CatchUp(10)
+
+	// Assert
+	// The runner entries are never read.
+}
+
+func TestJobID(t *testing.T) {
+	a := &Job{Schedule: "0 2 * * *", Command: "backup.sh", Args: []string{"full"}}
+	b := &Job{Schedule: "0 2 * * *", Command: "backup.sh", Args: []string{"full"}, Timeout: "1h", Catchup: CatchupOnce}
+	c := &Job{Schedule: "0 3 * * *", Command: "backup.sh", Args: []string{"full"}}
+
+	assert.Assert(t, strings.HasPrefix(a.id(), "file/"))
+	assert.Equal(t, len(a.id()), 17)
+	assert.Equal(t, a.id(), b.id())
+	assert.Assert(t, a.id() != c.id())
+
+	s := &ServiceJob{ServiceName: "db", Action: "update"}
+	assert.Equal(t, s.id(), fmt.Sprintf("service/%s-%s", "db", "update"))
+}
@@ -0,0 +1,68 @@
+package cron
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Trailers of a job run waited through the HTTP API.
+const (
+	ExitCodeTrailer = "Mobycron-Exit-Code"
+	ErrorTrailer    = "Mobycron-Error"
+)
+
+// ErrDaemonUnavailable is returned when no daemon answer on the HTTP API.
+var ErrDaemonUnavailable = errors.New("mobycron daemon is not available")
+
+// APIURL return the base URL of the HTTP API listening on addr, like ':8080'.
+func APIURL(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "http://" + addr
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+// RunRemote run the job with the ID or name through the HTTP API at baseURL,
+// streaming its output to w, and return its error. A run completed with a non
+// zero exit code return an error with the same ExitCode.
+func RunRemote(baseURL string, name string, w io.Writer) error {
+	resp, err := http.Post(baseURL+"/jobs/"+url.PathEscape(name)+"/run?wait=true", "text/plain", nil)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return errors.Wrap(ErrDaemonUnavailable, err.Error())
+		}
+		return errors.Wrap(err, "failed to run job through the daemon")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return errors.Errorf("daemon refused to run job: %s", body.Error)
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return errors.Wrap(err, "failed to read job output from the daemon")
+	}
+	msg := resp.Trailer.Get(ErrorTrailer)
+	if msg == "" {
+		return nil
+	}
+	if code, _ := strconv.Atoi(resp.Trailer.Get(ExitCodeTrailer)); code > 0 {
+		return &exitError{code}
+	}
+	return errors.New(msg)
+}
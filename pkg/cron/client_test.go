@@ -0,0 +1,117 @@
+package cron
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestAPIURL(t *testing.T) {
+	assert.Equal(t, APIURL(":8080"), "http://127.0.0.1:8080")
+	assert.Equal(t, APIURL("0.0.0.0:8080"), "http://127.0.0.1:8080")
+	assert.Equal(t, APIURL("[::]:8080"), "http://127.0.0.1:8080")
+	assert.Equal(t, APIURL("cron.local:8080"), "http://cron.local:8080")
+	assert.Equal(t, APIURL("cron.local"), "http://cron.local")
+}
+
+func TestRunRemote(t *testing.T) {
+	tests := []struct {
+		name string
+		job  string
+		mock func(*MockScheduler)
+		err  string
+		code int
+	}{
+		{
+			name: "success",
+			job:  "/usr/local/bin/backup.sh",
+			mock: func(s *MockScheduler) {
+				s.EXPECT().FindJob("/usr/local/bin/backup.sh").Return(JobInfo{ID: 1}, nil)
+				s.EXPECT().Job(1).Return(JobInfo{ID: 1}, nil)
+				s.EXPECT().RunJob(1, gomock.Any()).DoAndReturn(func(ID int, w io.Writer) error {
+					io.WriteString(w, "done\n")
+					return nil
+				})
+			},
+		},
+		{
+			name: "exit code",
+			job:  "1",
+			mock: func(s *MockScheduler) {
+				s.EXPECT().Job(1).Return(JobInfo{ID: 1}, nil)
+				s.EXPECT().RunJob(1, gomock.Any()).DoAndReturn(func(ID int, w io.Writer) error {
+					io.WriteString(w, "done\n")
+					return &exitError{4}
+				})
+			},
+			err:  "exit status 4",
+			code: 4,
+		},
+		{
+			name: "error",
+			job:  "1",
+			mock: func(s *MockScheduler) {
+				s.EXPECT().Job(1).Return(JobInfo{ID: 1}, nil)
+				s.EXPECT().RunJob(1, gomock.Any()).DoAndReturn(func(ID int, w io.Writer) error {
+					io.WriteString(w, "done\n")
+					return errors.New("timed out after 1s")
+				})
+			},
+			err:  "timed out after 1s",
+			code: -1,
+		},
+		{
+			name: "job not found",
+			job:  "unknown",
+			mock: func(s *MockScheduler) {
+				s.EXPECT().FindJob("unknown").Return(JobInfo{}, ErrJobNotFound)
+			},
+			err:  "daemon refused to run job: job not found",
+			code: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			s := NewMockScheduler(ctrl)
+			tt.mock(s)
+
+			api := httptest.NewServer(NewServer(s, ":0").srv.Handler)
+			defer api.Close()
+			out := &bytes.Buffer{}
+
+			// Act
+			err := RunRemote(api.URL, tt.job, out)
+
+			// Assert
+			assert.Equal(t, ExitCode(err), tt.code)
+			if tt.err == "" {
+				assert.NilError(t, err)
+				assert.Equal(t, out.String(), "done\n")
+			} else {
+				assert.Error(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRunRemoteUnavailable(t *testing.T) {
+	// Arrange
+	api := httptest.NewServer(nil)
+	api.Close()
+
+	// Act
+	err := RunRemote(api.URL, "backup.sh", &bytes.Buffer{})
+
+	// Assert
+	assert.Assert(t, is.Equal(errors.Cause(err), ErrDaemonUnavailable))
+}
@@ -0,0 +1,134 @@
+package cron
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Concurrency policies applied when a job is triggered while a previous run
+// of the same job is still in progress.
+const (
+	ConcurrencyAllow   = "allow"
+	ConcurrencySkip    = "skip"
+	ConcurrencyQueue   = "queue"
+	ConcurrencyReplace = "replace"
+)
+
+// guard serialises the runs of a single job according to its concurrency
+// policy. With the 'replace' policy, cancel stop the run holding running and
+// last count the runs, so only the last of the runs waiting for running start.
+type guard struct {
+	policy  string
+	running sync.Mutex
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	last    uint64
+	paused  bool
+}
+
+func newGuard(policy string) *guard {
+	if policy == "" {
+		policy = ConcurrencyAllow
+	}
+	return &guard{policy: policy}
+}
+
+func validateConcurrency(policy string) error {
+	switch policy {
+	case "", ConcurrencyAllow, ConcurrencySkip, ConcurrencyQueue, ConcurrencyReplace:
+		return nil
+	default:
+		return errors.New("invalid concurrency policy, only 'allow', 'skip', 'queue' and 'replace' are permitted")
+	}
+}
+
+// acquire wait or refuse to run according to the policy. When ok is true, the
+// returned context is canceled if the run is replaced and release must be
+// called once the run is completed.
+func (g *guard) acquire(log *log.Entry) (ctx context.Context, release func(), ok bool) {
+	if g.isPaused() {
+		log.Warnln("job run skipped, job is paused")
+		return nil, nil, false
+	}
+
+	if g == nil || g.policy == ConcurrencyAllow {
+		return context.Background(), func() {}, true
+	}
+
+	log = log.WithField("concurrency", g.policy)
+
+	switch g.policy {
+	case ConcurrencySkip:
+		if !g.running.TryLock() {
+			log.Warnln("job run skipped, previous run still in progress")
+			return nil, nil, false
+		}
+	case ConcurrencyQueue:
+		if !g.running.TryLock() {
+			log.Warnln("job run queued, waiting for previous run to complete")
+			g.running.Lock()
+		}
+	case ConcurrencyReplace:
+		g.mu.Lock()
+		g.last++
+		run := g.last
+		if g.cancel != nil {
+			g.cancel()
+		}
+		g.mu.Unlock()
+		if !g.running.TryLock() {
+			log.Warnln("job run replacing previous run still in progress")
+			g.running.Lock()
+		}
+
+		// A newer run canceled the run holding running before this one
+		// got it, so this one is replaced before it starts.
+		g.mu.Lock()
+		if g.last != run {
+			g.mu.Unlock()
+			g.running.Unlock()
+			log.Warnln("job run skipped, replaced by a newer run")
+			return nil, nil, false
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		g.cancel = cancel
+		g.mu.Unlock()
+		return ctx, g.release(cancel), true
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g.mu.Lock()
+	g.cancel = cancel
+	g.mu.Unlock()
+	return ctx, g.release(cancel), true
+}
+
+// release return the function completing the run with the context canceled
+// by cancel.
+func (g *guard) release(cancel context.CancelFunc) func() {
+	return func() {
+		g.mu.Lock()
+		g.cancel = nil
+		g.mu.Unlock()
+		cancel()
+		g.running.Unlock()
+	}
+}
+
+func (g *guard) setPaused(paused bool) {
+	g.mu.Lock()
+	g.paused = paused
+	g.mu.Unlock()
+}
+
+func (g *guard) isPaused() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
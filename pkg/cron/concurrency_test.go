@@ -0,0 +1,265 @@
+package cron
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestValidateConcurrency(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		err    string
+	}{
+		{name: "empty", policy: ""},
+		{name: "allow", policy: "allow"},
+		{name: "skip", policy: "skip"},
+		{name: "queue", policy: "queue"},
+		{name: "replace", policy: "replace"},
+		{name: "invalid", policy: "invalid", err: "invalid concurrency policy, only 'allow', 'skip', 'queue' and 'replace' are permitted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := validateConcurrency(tt.policy)
+
+			// Assert
+			if tt.err != "" {
+				assert.Error(t, err, tt.err)
+			} else {
+				assert.NilError(t, err)
+			}
+		})
+	}
+}
+
+func TestGuardAcquire(t *testing.T) {
+	type checkFunc func(*testing.T, string, []string)
+	check := func(fns ...checkFunc) []checkFunc { return fns }
+
+	hasLogField := func(field string, want string) checkFunc {
+		return func(t *testing.T, out string, runs []string) {
+			assert.Assert(t, is.Contains(out, fmt.Sprintf("\"%s\":\"%s\"", field, want)))
+		}
+	}
+
+	hasNoLog := func() checkFunc {
+		return func(t *testing.T, out string, runs []string) {
+			assert.Assert(t, is.Len(out, 0))
+		}
+	}
+
+	hasRuns := func(want ...string) checkFunc {
+		return func(t *testing.T, out string, runs []string) {
+			assert.Assert(t, is.DeepEqual(runs, want))
+		}
+	}
+
+	tests := []struct {
+		name   string
+		guard  *guard
+		checks []checkFunc
+	}{
+		{
+			name:  "nil guard allow all runs",
+			guard: nil,
+			checks: check(
+				hasNoLog(),
+				hasRuns("start 1", "start 2", "end 2", "end 1"),
+			),
+		},
+		{
+			name:  "allow",
+			guard: newGuard("allow"),
+			checks: check(
+				hasNoLog(),
+				hasRuns("start 1", "start 2", "end 2", "end 1"),
+			),
+		},
+		{
+			name:  "skip",
+			guard: newGuard("skip"),
+			checks: check(
+				hasLogField("level", "warning"),
+				hasLogField("concurrency", "skip"),
+				hasLogField("msg", "job run skipped, previous run still in progress"),
+				hasRuns("start 1", "end 1"),
+			),
+		},
+		{
+			name:  "queue",
+			guard: newGuard("queue"),
+			checks: check(
+				hasLogField("level", "warning"),
+				hasLogField("concurrency", "queue"),
+				hasLogField("msg", "job run queued, waiting for previous run to complete"),
+				hasRuns("start 1", "end 1", "start 2", "end 2"),
+			),
+		},
+		{
+			name:  "replace",
+			guard: newGuard("replace"),
+			checks: check(
+				hasLogField("level", "warning"),
+				hasLogField("concurrency", "replace"),
+				hasLogField("msg", "job run replacing previous run still in progress"),
+				hasRuns("start 1", "canceled 1", "start 2", "end 2"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			out := &bytes.Buffer{}
+			log.SetOutput(out)
+			log.SetFormatter(&log.JSONFormatter{})
+
+			var mu sync.Mutex
+			runs := []string{}
+			record := func(s string) {
+				mu.Lock()
+				runs = append(runs, s)
+				mu.Unlock()
+			}
+
+			started := make(chan struct{})
+			finish := make(chan struct{})
+			wg := sync.WaitGroup{}
+			wg.Add(2)
+
+			// Act
+			go func() {
+				defer wg.Done()
+				ctx, release, ok := tt.guard.acquire(log.WithField("run", 1))
+				if !ok {
+					return
+				}
+				defer release()
+				record("start 1")
+				close(started)
+				select {
+				case <-ctx.Done():
+					record("canceled 1")
+				case <-finish:
+					record("end 1")
+				}
+			}()
+
+			<-started
+			go func() {
+				defer wg.Done()
+				_, release, ok := tt.guard.acquire(log.WithField("run", 2))
+				if !ok {
+					close(finish)
+					return
+				}
+				defer release()
+				record("start 2")
+				if tt.guard == nil || tt.guard.policy == ConcurrencyAllow {
+					record("end 2")
+					close(finish)
+					return
+				}
+				record("end 2")
+			}()
+
+			if tt.guard != nil && tt.guard.policy == ConcurrencyQueue {
+				time.Sleep(5 * time.Millisecond)
+				close(finish)
+			}
+			wg.Wait()
+
+			// Assert
+			for _, check := range tt.checks {
+				check(t, out.String(), runs)
+			}
+		})
+	}
+}
+
+func TestGuardReplaceQueued(t *testing.T) {
+	// Arrange
+	log.SetOutput(&bytes.Buffer{})
+	g := newGuard("replace")
+	runs := make(chan int, 3)
+	last := func() uint64 {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		return g.last
+	}
+
+	ctx, release, ok := g.acquire(log.WithField("run", 1))
+	assert.Assert(t, ok)
+
+	wg := sync.WaitGroup{}
+	run := func(n int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, release, ok := g.acquire(log.WithField("run", n))
+			if !ok {
+				return
+			}
+			defer release()
+			runs <- n
+
+			// The last run is never replaced.
+			assert.NilError(t, ctx.Err())
+		}()
+	}
+
+	// Act, two runs wait for the first one still running after its cancel
+	run(2)
+	for last() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	run(3)
+	for last() != 3 {
+		time.Sleep(time.Millisecond)
+	}
+	<-ctx.Done()
+	release()
+	wg.Wait()
+	close(runs)
+
+	// Assert
+	started := []int{}
+	for n := range runs {
+		started = append(started, n)
+	}
+	assert.Assert(t, is.DeepEqual(started, []int{3}))
+}
+
+func TestGuardPaused(t *testing.T) {
+	// Arrange
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+	log.SetFormatter(&log.JSONFormatter{})
+
+	g := newGuard("")
+	g.setPaused(true)
+
+	// Act
+	_, _, ok := g.acquire(log.WithField("run", 1))
+
+	// Assert
+	assert.Assert(t, !ok)
+	assert.Assert(t, is.Contains(out.String(), "job run skipped, job is paused"))
+
+	// Act
+	g.setPaused(false)
+	_, release, ok := g.acquire(log.WithField("run", 2))
+	release()
+
+	// Assert
+	assert.Assert(t, ok)
+}
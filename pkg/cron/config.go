@@ -0,0 +1,134 @@
+package cron
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of config file.
+const (
+	FormatJSON    = "json"
+	FormatYAML    = "yaml"
+	FormatTOML    = "toml"
+	FormatCrontab = "crontab"
+
+	// FormatSystemCrontab is a crontab with a user column, like /etc/crontab
+	// and the files of /etc/cron.d.
+	FormatSystemCrontab = "system-crontab"
+)
+
+// Config is the content of a config file. Global settings apply to every
+// job that does not override them.
+type Config struct {
+	Timeout     string `json:"timeout"`
+	Concurrency string `json:"concurrency"`
+	Catchup     string `json:"catchup"`
+	Notify      Notify `json:"notify"`
+	MailTo      string `json:"mailto"`
+	MailOn      string `json:"mailon"`
+	Jobs        []Job  `json:"jobs"`
+}
+
+// SetConfigFormat force the format of config file, otherwise it is guessed
+// from the file extension.
+func (c *Cron) SetConfigFormat(format string) error {
+	switch format {
+	case "", FormatJSON, FormatYAML, FormatTOML, FormatCrontab, FormatSystemCrontab:
+		c.format = format
+		return nil
+	default:
+		return errors.New("invalid config format, only 'json', 'yaml', 'toml', 'crontab' and 'system-crontab' are permitted")
+	}
+}
+
+// configFormat return the format of filename.
+func (c *Cron) configFormat(filename string) string {
+	if c.format != "" {
+		return c.format
+	}
+
+	switch dir := filepath.Base(filepath.Dir(filename)); {
+	case filepath.Base(filename) == "crontab", dir == "cron.d":
+		return FormatSystemCrontab
+	case dir == "crontabs":
+		return FormatCrontab
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	case ".cron", ".crontab":
+		return FormatCrontab
+	default:
+		return FormatJSON
+	}
+}
+
+// parseConfig read jobs from data in format. The document is either a list
+// of jobs or a Config, unless it is a crontab.
+func parseConfig(data []byte, format string, parseSecond bool) ([]Job, error) {
+	config := Config{}
+
+	switch format {
+	case FormatCrontab:
+		return parseCrontab(data, parseSecond, false)
+	case FormatSystemCrontab:
+		return parseCrontab(data, parseSecond, true)
+	case FormatYAML:
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, errors.Wrap(err, "failed to parse YAML data from config file")
+		}
+		var err error
+		if len(node.Content) > 0 && node.Content[0].Kind == yaml.SequenceNode {
+			err = node.Decode(&config.Jobs)
+		} else {
+			err = node.Decode(&config)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse YAML data from config file")
+		}
+	case FormatTOML:
+		if err := toml.Unmarshal(data, &config); err != nil {
+			return nil, errors.Wrap(err, "failed to parse TOML data from config file")
+		}
+	default:
+		var err error
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+			err = json.Unmarshal(data, &config.Jobs)
+		} else {
+			err = json.Unmarshal(data, &config)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse JSON data from config file")
+		}
+	}
+
+	for i := range config.Jobs {
+		if config.Jobs[i].Timeout == "" {
+			config.Jobs[i].Timeout = config.Timeout
+		}
+		if config.Jobs[i].Concurrency == "" {
+			config.Jobs[i].Concurrency = config.Concurrency
+		}
+		if config.Jobs[i].Catchup == "" {
+			config.Jobs[i].Catchup = config.Catchup
+		}
+		config.Jobs[i].Notify = config.Jobs[i].Notify.merge(config.Notify)
+		if config.Jobs[i].MailTo == "" {
+			config.Jobs[i].MailTo = config.MailTo
+		}
+		if config.Jobs[i].MailOn == "" {
+			config.Jobs[i].MailOn = config.MailOn
+		}
+	}
+	return config.Jobs, nil
+}
@@ -0,0 +1,204 @@
+package cron
+
+import (
+	"testing"
+
+	gocmp "github.com/google/go-cmp/cmp"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestSetConfigFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		err    string
+	}{
+		{name: "none", format: ""},
+		{name: "json", format: "json"},
+		{name: "yaml", format: "yaml"},
+		{name: "toml", format: "toml"},
+		{name: "crontab", format: "crontab"},
+		{name: "system crontab", format: "system-crontab"},
+		{name: "invalid", format: "xml", err: "invalid config format, only 'json', 'yaml', 'toml', 'crontab' and 'system-crontab' are permitted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			c := &Cron{}
+
+			// Act
+			err := c.SetConfigFormat(tt.format)
+
+			// Assert
+			if tt.err != "" {
+				assert.Error(t, err, tt.err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, c.format, tt.format)
+		})
+	}
+}
+
+func TestConfigFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		filename string
+		want     string
+	}{
+		{name: "json extension", filename: "config.json", want: "json"},
+		{name: "yaml extension", filename: "config.yaml", want: "yaml"},
+		{name: "yml extension", filename: "config.YML", want: "yaml"},
+		{name: "toml extension", filename: "config.toml", want: "toml"},
+		{name: "unknown extension", filename: "config", want: "json"},
+		{name: "crontab extension", filename: "jobs.cron", want: "crontab"},
+		{name: "crontab file", filename: "/etc/crontab", want: "system-crontab"},
+		{name: "cron.d directory", filename: "/etc/cron.d/backup", want: "system-crontab"},
+		{name: "crontabs directory", filename: "/var/spool/cron/crontabs/root", want: "crontab"},
+		{name: "forced format", format: "toml", filename: "config.json", want: "toml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			c := &Cron{format: tt.format}
+
+			// Act
+			got := c.configFormat(tt.filename)
+
+			// Assert
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		want   []Job
+		err    string
+	}{
+		{
+			name:   "json list",
+			format: "json",
+			data:   `[{"schedule": "1 * * * *", "command": "echo", "args": ["a"]}]`,
+			want:   []Job{{Schedule: "1 * * * *", Command: "echo", Args: []string{"a"}}},
+		},
+		{
+			name:   "json document",
+			format: "json",
+			data: `{
+				"timeout": "1m",
+				"concurrency": "skip",
+				"catchup": "once",
+				"jobs": [
+					{"schedule": "1 * * * *", "command": "echo"},
+					{"schedule": "2 * * * *", "command": "echo", "timeout": "2m", "concurrency": "queue", "catchup": "all"}
+				]
+			}`,
+			want: []Job{
+				{Schedule: "1 * * * *", Command: "echo", Timeout: "1m", Concurrency: "skip", Catchup: "once"},
+				{Schedule: "2 * * * *", Command: "echo", Timeout: "2m", Concurrency: "queue", Catchup: "all"},
+			},
+		},
+		{
+			name:   "json not valid",
+			format: "json",
+			data:   `{error}`,
+			err:    "failed to parse JSON data from config file",
+		},
+		{
+			name:   "yaml list",
+			format: "yaml",
+			data:   "- schedule: 1 * * * *\n  command: echo\n  args: [a]\n",
+			want:   []Job{{Schedule: "1 * * * *", Command: "echo", Args: []string{"a"}}},
+		},
+		{
+			name:   "yaml retry",
+			format: "yaml",
+			data:   "- schedule: 1 * * * *\n  command: curl\n  retry:\n    attempts: 3\n    delay: 5s\n    multiplier: 1.5\n    maxdelay: 1m\n    exitcodes: [7]\n",
+			want:   []Job{{Schedule: "1 * * * *", Command: "curl", Retry: Retry{Attempts: 3, Delay: "5s", Multiplier: 1.5, MaxDelay: "1m", ExitCodes: []int{7}}}},
+		},
+		{
+			name:   "yaml notify",
+			format: "yaml",
+			data:   "notify:\n  urls: [http://hooks/a]\n  on: failure\njobs:\n  - schedule: 1 * * * *\n    command: echo\n  - schedule: 2 * * * *\n    command: echo\n    notify:\n      on: always\n",
+			want: []Job{
+				{Schedule: "1 * * * *", Command: "echo", Notify: Notify{URLs: []string{"http://hooks/a"}, On: "failure"}},
+				{Schedule: "2 * * * *", Command: "echo", Notify: Notify{URLs: []string{"http://hooks/a"}, On: "always"}},
+			},
+		},
+		{
+			name:   "toml mailto",
+			format: "toml",
+			data:   "mailto = \"ops@example.com\"\nmailon = \"failure\"\n\n[[jobs]]\nschedule = \"1 * * * *\"\ncommand = \"echo\"\n\n[[jobs]]\nschedule = \"2 * * * *\"\ncommand = \"echo\"\nmailto = \"dba@example.com\"\n",
+			want: []Job{
+				{Schedule: "1 * * * *", Command: "echo", MailTo: "ops@example.com", MailOn: "failure"},
+				{Schedule: "2 * * * *", Command: "echo", MailTo: "dba@example.com", MailOn: "failure"},
+			},
+		},
+		{
+			name:   "yaml document",
+			format: "yaml",
+			data:   "timeout: 1m\njobs:\n  - schedule: 1 * * * *\n    command: echo\n    concurrency: replace\n",
+			want:   []Job{{Schedule: "1 * * * *", Command: "echo", Timeout: "1m", Concurrency: "replace"}},
+		},
+		{
+			name:   "yaml not valid",
+			format: "yaml",
+			data:   "jobs: {schedule",
+			err:    "failed to parse YAML data from config file",
+		},
+		{
+			name:   "yaml wrong type",
+			format: "yaml",
+			data:   "jobs: echo",
+			err:    "failed to parse YAML data from config file",
+		},
+		{
+			name:   "toml document",
+			format: "toml",
+			data:   "concurrency = \"skip\"\n\n[[jobs]]\nschedule = \"1 * * * *\"\ncommand = \"echo\"\nargs = [\"a\"]\ntimeout = \"30s\"\n",
+			want:   []Job{{Schedule: "1 * * * *", Command: "echo", Args: []string{"a"}, Timeout: "30s", Concurrency: "skip"}},
+		},
+		{
+			name:   "crontab",
+			format: "crontab",
+			data:   "0 1 * * * echo hello",
+			want:   []Job{{Schedule: "0 1 * * *", Command: "/bin/sh", Args: []string{"-c", "echo hello"}, raw: true}},
+		},
+		{
+			name:   "system crontab",
+			format: "system-crontab",
+			data:   "0 1 * * * mail echo hello",
+			want:   []Job{{Schedule: "0 1 * * *", Command: "/bin/sh", Args: []string{"-c", "echo hello"}, User: "mail", raw: true}},
+		},
+		{
+			name:   "toml not valid",
+			format: "toml",
+			data:   "[[jobs]\n",
+			err:    "failed to parse TOML data from config file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := parseConfig([]byte(tt.data), tt.format, false)
+
+			// Assert
+			if tt.err != "" {
+				assert.Assert(t, is.ErrorContains(err, tt.err))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Assert(t, is.DeepEqual(got, tt.want, gocmp.AllowUnexported(Job{})))
+		})
+	}
+}
@@ -2,34 +2,72 @@ package cron
 
 import (
 	context "context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 )
 
-// ContainerJob run a docker container on a schedule.
+// ContainerJob run a docker container on a schedule. With the 'run' action, a
+// new container is created from Image, or from the image of Container. With
+// Wait, the 'start' action wait for the container to exit and Timeout is its
+// maximum run time. Label is the name of the job in the labels of the
+// container and Name the name of the job, by default the container name and
+// Label or Action, which follow the container when it is renamed.
 type ContainerJob struct {
-	Schedule  string
-	Action    string
-	Timeout   string
-	Command   string
-	Container container.Summary
-	cron      *Cron
-	cli       DockerClient
+	Name        string
+	Label       string
+	Schedule    string
+	Action      string
+	Timeout     string
+	Command     string
+	Concurrency string
+	Wait        bool
+	Image       string
+	Env         []string
+	Mounts      []string
+	Network     string
+	AutoRemove  bool
+	Catchup     string
+	Retry       Retry
+	Notify      Notify
+	Ping        Ping
+	MailTo      string
+	MailOn      string
+	Container   container.Summary
+	cron        *Cron
+	cli         DockerClient
+	guard       *guard
+	defaultName bool
 }
 
 // Run a docker container and log the output.
 func (j *ContainerJob) Run() {
+	j.runWith(nil)
+}
+
+// runWith run the job like the scheduler, streaming its output to w when set,
+// and return its error.
+func (j *ContainerJob) runWith(w io.Writer) error {
 	log := log.WithFields(log.Fields{
 		"func":            "ContainerJob.Run",
+		"name":            j.labels()["name"],
 		"schedule":        j.Schedule,
 		"action":          j.Action,
 		"timeout":         j.Timeout,
 		"command":         j.Command,
+		"concurrency":     j.Concurrency,
+		"wait":            j.Wait,
+		"image":           j.Image,
 		"container.ID":    j.Container.ID,
 		"container.Names": strings.Join(j.Container.Names, ","),
 	})
@@ -37,44 +75,210 @@ func (j *ContainerJob) Run() {
 
 	j.cron.sync.Add(1)
 	defer j.cron.sync.Done()
+
+	ctx, release, ok := j.guard.acquire(log)
+	if !ok {
+		return ErrJobSkipped
+	}
+	defer release()
+
+	jobsRunning.Inc()
+	defer jobsRunning.Dec()
+	start := time.Now()
+
 	defer j.cli.Close()
-	var err error
 
+	j.Ping.start(log)
+	out, attempt, err := j.Retry.do(ctx, log, func(ctx context.Context) (string, error) {
+		return j.attempt(ctx, w)
+	})
+
+	if out != "" {
+		log = log.WithField("output", out)
+	}
+	if j.Retry.Attempts > 1 {
+		log = log.WithField("attempt", attempt)
+	}
+	if err != nil {
+		log.WithError(err).Errorln("container job completed with error")
+	} else {
+		log.Infoln("container action completed successfully")
+	}
+
+	j.Ping.finish(log, out, err)
+	j.cron.record(j, start, out, err)
+	return err
+}
+
+// attempt do the action of the job once, streaming its output to w when set.
+func (j *ContainerJob) attempt(ctx context.Context, w io.Writer) (string, error) {
 	switch j.Action {
 	case "start":
-		err = j.start()
+		return j.start(ctx, w)
 	case "restart":
-		err = j.restart()
+		return "", j.restart(ctx)
 	case "stop":
-		err = j.stop()
+		return "", j.stop(ctx)
 	case "exec":
-		var out string
-		if out, err = j.exec(); out != "" {
-			log = log.WithField("output", out)
+		return j.exec(ctx, w)
+	case "run":
+		return j.run(ctx, w)
+	}
+	return "", nil
+}
+
+// validate check the fields of the container job.
+func (j *ContainerJob) validate() error {
+	if err := validateName(j.Name); err != nil {
+		return err
+	}
+
+	if j.Schedule == "" {
+		return errors.New("schedule is required")
+	}
+
+	if err := validateConcurrency(j.Concurrency); err != nil {
+		return err
+	}
+
+	if err := validateCatchup(j.Catchup); err != nil {
+		return err
+	}
+
+	if err := j.Retry.validate(); err != nil {
+		return err
+	}
+
+	if err := j.Notify.validate(); err != nil {
+		return err
+	}
+
+	if err := j.Ping.validate(); err != nil {
+		return err
+	}
+
+	if err := validateMailTo(j.MailTo); err != nil {
+		return err
+	}
+
+	if err := validateMailOn(j.MailOn); err != nil {
+		return err
+	}
+
+	if j.Timeout != "" {
+		if _, err := strconv.ParseInt(j.Timeout, 10, 0); err != nil {
+			return errors.New("invalid container timeout, only integer are permitted")
 		}
 	}
 
-	if err != nil {
-		log.WithError(err).Errorln("container job completed with error")
-	} else {
-		log.Infoln("container action completed successfully")
+	if j.Wait && j.Action != "start" {
+		return errors.New("wait can be specified only with 'start' action")
+	}
+
+	switch j.Action {
+	case "start", "restart", "stop":
+		if j.Command != "" {
+			return errors.New("a command can be specified only with 'exec' and 'run' actions")
+		}
+	case "exec":
+		if j.Command == "" {
+			return errors.New("command is required")
+		}
+	case "run":
+		if j.Image == "" && j.Container.Image == "" {
+			return errors.New("image is required")
+		}
+	default:
+		return errors.New("invalid container action, only 'start', 'restart', 'stop', 'exec' and 'run' are permitted")
+	}
+	return nil
+}
+
+// labels identify the job in metrics.
+func (j *ContainerJob) labels() prometheus.Labels {
+	name := containerName(j.Container)
+	job := j.Name
+	if job == "" {
+		suffix := j.Action
+		if j.Label != "" {
+			suffix = j.Label
+		}
+		job = name + "-" + suffix
 	}
+	return prometheus.Labels{"name": job, "source": "container", "container": name, "service": ""}
+}
+
+// id identify the job across restarts by its container name and its label name
+// or action. A container recreated by 'docker compose up' is renamed to the
+// name of the old one, so its jobs keep the id of the jobs of the old one.
+func (j *ContainerJob) id() string {
+	return "container/" + j.labels()["name"]
+}
+
+// scheduleSpec return the schedule of the job.
+func (j *ContainerJob) scheduleSpec() string {
+	return j.Schedule
+}
+
+// catchupPolicy return the policy applied to the runs missed by the job.
+func (j *ContainerJob) catchupPolicy() string {
+	return j.Catchup
+}
+
+// notifyPolicy return the webhooks notified of the runs of the job.
+func (j *ContainerJob) notifyPolicy() Notify {
+	return j.Notify
 }
 
-func (j *ContainerJob) start() error {
-	return j.cli.ContainerStart(context.Background(), j.Container.ID, container.StartOptions{})
+// mailPolicy return the addresses and the condition of the mails of the runs
+// of the job.
+func (j *ContainerJob) mailPolicy() (string, string) {
+	return j.MailTo, j.MailOn
+}
+
+func (j *ContainerJob) start(ctx context.Context, w io.Writer) (string, error) {
+	if !j.Wait {
+		return "", j.cli.ContainerStart(ctx, j.Container.ID, container.StartOptions{})
+	}
+
+	if j.Timeout != "" {
+		timeout, _ := strconv.Atoi(j.Timeout)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	since := time.Now()
+	options := container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Since:      fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond()),
+	}
+	out, err := startAndWait(ctx, j.cli, j.Container.ID, options, w)
+	if ctx.Err() == context.DeadlineExceeded {
+		return out, errors.Errorf("timed out after %ss", j.Timeout)
+	}
+	return out, err
 }
 
-func (j *ContainerJob) restart() error {
-	return j.cli.ContainerRestart(context.Background(), j.Container.ID, *j.getStopOption())
+func (j *ContainerJob) restart(ctx context.Context) error {
+	return j.cli.ContainerRestart(ctx, j.Container.ID, *j.getStopOption())
 }
 
-func (j *ContainerJob) stop() error {
-	return j.cli.ContainerStop(context.Background(), j.Container.ID, *j.getStopOption())
+func (j *ContainerJob) stop(ctx context.Context) error {
+	return j.cli.ContainerStop(ctx, j.Container.ID, *j.getStopOption())
 }
 
-func (j *ContainerJob) exec() (string, error) {
-	ctx := context.Background()
+// execMarker is set in the environment of the command of an exec, so its
+// processes can be found in the container and killed when the run is
+// canceled, as docker can not stop an exec.
+const execMarker = "MOBYCRON_EXEC"
+
+// killScript send SIGTERM to the processes of the container with the marker
+// $1 in their environment. It is run by sh in the container.
+const killScript = `for p in /proc/[0-9]*; do tr '\0' '\n' < "$p/environ" 2>/dev/null | grep -qxF "$1" && kill -TERM "${p#/proc/}"; done`
+
+func (j *ContainerJob) exec(ctx context.Context, w io.Writer) (string, error) {
 	cmd := strings.Fields(j.Command)
 
 	// We need to inspect before we do the ContainerExecCreate, because
@@ -85,7 +289,8 @@ func (j *ContainerJob) exec() (string, error) {
 		return "", err
 	}
 
-	createResp, err := j.cli.ContainerExecCreate(ctx, j.Container.ID, container.ExecOptions{AttachStdout: true, AttachStderr: true, Cmd: cmd})
+	marker := execMarker + "=" + newExecToken()
+	createResp, err := j.cli.ContainerExecCreate(ctx, j.Container.ID, container.ExecOptions{AttachStdout: true, AttachStderr: true, Cmd: cmd, Env: []string{marker}})
 	if err != nil {
 		return "", err
 	}
@@ -102,8 +307,29 @@ func (j *ContainerJob) exec() (string, error) {
 	defer attachResp.CloseWrite()
 	defer attachResp.Close()
 
+	// Closing the connection end the read of the output, the command keeps
+	// running in the container until it is killed.
+	killed := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(killed)
+		attachResp.Close()
+		if err := killExec(j.cli, j.Container.ID, marker); err != nil {
+			log.WithFields(log.Fields{
+				"func":         "ContainerJob.exec",
+				"container.ID": j.Container.ID,
+			}).WithError(err).Errorln("failed to kill canceled exec")
+		}
+	})
+	defer stop()
+
 	var out strings.Builder
-	if _, err = stdcopy.StdCopy(&out, &out, attachResp.Reader); err != nil {
+	stream := outputWriter(&out, w)
+	_, err = stdcopy.StdCopy(stream, stream, attachResp.Reader)
+	if ctx.Err() != nil {
+		<-killed
+		return out.String(), ctx.Err()
+	}
+	if err != nil {
 		return "", err
 	}
 
@@ -113,12 +339,63 @@ func (j *ContainerJob) exec() (string, error) {
 	}
 
 	if inspectResp.ExitCode != 0 {
-		return out.String(), errors.Errorf("exit status %d", inspectResp.ExitCode)
+		return out.String(), &exitError{inspectResp.ExitCode}
 	}
 
 	return out.String(), nil
 }
 
+// newExecToken return a random token identifying an exec, it is replaced in
+// tests.
+var newExecToken = func() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// killExec kill the processes of the exec with the marker in the container
+// with the ID.
+func killExec(cli DockerClient, ID string, marker string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	createResp, err := cli.ContainerExecCreate(ctx, ID, container.ExecOptions{AttachStdout: true, AttachStderr: true, Cmd: []string{"sh", "-c", killScript, "sh", marker}})
+	if err != nil {
+		return err
+	}
+	attachResp, err := cli.ContainerExecAttach(ctx, createResp.ID, container.ExecStartOptions{})
+	if err != nil {
+		return err
+	}
+	defer attachResp.Close()
+
+	_, err = io.Copy(io.Discard, attachResp.Reader)
+	return err
+}
+
+func (j *ContainerJob) run(ctx context.Context, w io.Writer) (string, error) {
+	image := j.Image
+	if image == "" {
+		image = j.Container.Image
+	}
+	return runContainer(ctx, j.cli, runOptions{
+		Image:      image,
+		Cmd:        strings.Fields(j.Command),
+		Env:        j.Env,
+		Mounts:     j.Mounts,
+		Network:    j.Network,
+		AutoRemove: j.AutoRemove,
+	}, w)
+}
+
+// containerName return the first name of a container, or its ID when unnamed.
+func containerName(c container.Summary) string {
+	if len(c.Names) == 0 {
+		return c.ID
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
 func (j *ContainerJob) getStopOption() *container.StopOptions {
 	var value = 10
 	if j.Timeout != "" {
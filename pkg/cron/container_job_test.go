@@ -5,6 +5,7 @@ import (
 	"bytes"
 	context "context"
 	"encoding/json"
+	"io"
 	"net"
 	"testing"
 
@@ -56,6 +57,7 @@ func TestContainerJobRun(t *testing.T) {
 		action    string
 		timeout   string
 		command   string
+		wait      bool
 		container types.Container
 		mock      mockFunc
 		checks    []checkFunc
@@ -98,6 +100,74 @@ func TestContainerJobRun(t *testing.T) {
 				hasLogField("msg", "container job completed with error"),
 			),
 		},
+		{
+			name:      "ContainerStart and wait",
+			action:    "start",
+			wait:      true,
+			container: types.Container{ID: "id1"},
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				s.EXPECT().Add(1)
+				cli.EXPECT().ContainerWait(gomock.Any(), "id1", container.WaitConditionNextExit).Return(waitResult(container.WaitResponse{}, nil))
+				cli.EXPECT().ContainerStart(gomock.Any(), "id1", container.StartOptions{})
+				cli.EXPECT().ContainerLogs(gomock.Any(), "id1", gomock.Any()).DoAndReturn(func(ctx context.Context, ID string, options container.LogsOptions) (io.ReadCloser, error) {
+					assert.Assert(t, options.ShowStdout && options.ShowStderr && options.Follow)
+					assert.Assert(t, options.Since != "")
+					return logStream("batch done\n", ""), nil
+				})
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasNilError(),
+				hasLogField("output", "batch done\n"),
+			),
+		},
+		{
+			name:      "ContainerStart and wait exit code",
+			action:    "start",
+			wait:      true,
+			container: types.Container{ID: "id1"},
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				s.EXPECT().Add(1)
+				cli.EXPECT().ContainerWait(gomock.Any(), gomock.Any(), gomock.Any()).Return(waitResult(container.WaitResponse{StatusCode: 1}, nil))
+				cli.EXPECT().ContainerStart(gomock.Any(), gomock.Any(), gomock.Any())
+				cli.EXPECT().ContainerLogs(gomock.Any(), gomock.Any(), gomock.Any()).Return(logStream("", "batch failed\n"), nil)
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasError("exit status 1"),
+				hasLogField("output", "batch failed\n"),
+			),
+		},
+		{
+			name:      "ContainerStart and wait timed out",
+			action:    "start",
+			wait:      true,
+			timeout:   "1",
+			container: types.Container{ID: "id1"},
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				s.EXPECT().Add(1)
+				cli.EXPECT().ContainerWait(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, ID string, condition container.WaitCondition) (<-chan container.WaitResponse, <-chan error) {
+					errCh := make(chan error, 1)
+					go func() {
+						<-ctx.Done()
+						errCh <- ctx.Err()
+					}()
+					return make(chan container.WaitResponse), errCh
+				})
+				cli.EXPECT().ContainerStart(gomock.Any(), gomock.Any(), gomock.Any())
+				cli.EXPECT().ContainerLogs(gomock.Any(), "id1", gomock.Any()).DoAndReturn(func(ctx context.Context, ID string, options container.LogsOptions) (io.ReadCloser, error) {
+					return followStream(ctx), nil
+				})
+				cli.EXPECT().ContainerStop(context.Background(), "id1", container.StopOptions{})
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasError("timed out after 1s"),
+			),
+		},
 		{
 			name:      "ContainerRestart default timeout",
 			action:    "restart",
@@ -208,7 +278,7 @@ func TestContainerJobRun(t *testing.T) {
 
 				s.EXPECT().Add(1)
 				cli.EXPECT().ContainerInspect(context.Background(), "id1").Return(types.ContainerJSON{}, nil)
-				cli.EXPECT().ContainerExecCreate(context.Background(), "id1", container.ExecOptions{AttachStdout: true, AttachStderr: true, Cmd: []string{"echo", "'hello", "bob'"}}).Return(types.IDResponse{ID: "execid1"}, nil)
+				cli.EXPECT().ContainerExecCreate(context.Background(), "id1", container.ExecOptions{AttachStdout: true, AttachStderr: true, Cmd: []string{"echo", "'hello", "bob'"}, Env: []string{"MOBYCRON_EXEC=token"}}).Return(types.IDResponse{ID: "execid1"}, nil)
 				cli.EXPECT().ContainerExecAttach(context.Background(), "execid1", container.ExecStartOptions{}).Return(types.HijackedResponse{Conn: client, Reader: buf}, nil)
 				cli.EXPECT().ContainerExecInspect(context.Background(), "execid1").Return(container.ExecInspect{ExitCode: 0}, nil)
 				cli.EXPECT().Close()
@@ -219,6 +289,41 @@ func TestContainerJobRun(t *testing.T) {
 				hasLogField("output", "exec stdout exec stderr"),
 			),
 		},
+		{
+			name:      "ContainerRun with image of container",
+			action:    "run",
+			command:   "echo hello",
+			container: types.Container{ID: "id1", Image: "alpine"},
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				config := &container.Config{Image: "alpine", Cmd: []string{"echo", "hello"}, Labels: map[string]string{"mobycron.run": "true"}}
+
+				s.EXPECT().Add(1)
+				cli.EXPECT().ContainerCreate(gomock.Any(), config, gomock.Any(), gomock.Any(), gomock.Any(), "").Return(container.CreateResponse{ID: "run1"}, nil)
+				cli.EXPECT().ContainerWait(gomock.Any(), "run1", gomock.Any()).Return(waitResult(container.WaitResponse{}, nil))
+				cli.EXPECT().ContainerStart(gomock.Any(), "run1", gomock.Any())
+				cli.EXPECT().ContainerLogs(gomock.Any(), "run1", gomock.Any()).Return(logStream("hello\n", ""), nil)
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasNilError(),
+				hasLogField("output", "hello\n"),
+			),
+		},
+		{
+			name:      "ContainerRun error",
+			action:    "run",
+			container: types.Container{ID: "id1", Image: "alpine"},
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				s.EXPECT().Add(1)
+				cli.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(container.CreateResponse{}, errors.New("no such image"))
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasError("failed to create container: no such image"),
+			),
+		},
 		{
 			name:      "ContainerInspect error",
 			action:    "exec",
@@ -372,6 +477,9 @@ func TestContainerJobRun(t *testing.T) {
 		},
 	}
 
+	defer func(f func() string) { newExecToken = f }(newExecToken)
+	newExecToken = func() string { return "token" }
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Arrange
@@ -389,12 +497,13 @@ func TestContainerJobRun(t *testing.T) {
 				tt.mock(s, cli)
 			}
 
-			c := &Cron{nil, s, nil, nil, nil}
+			c := &Cron{sync: s}
 			j := &ContainerJob{
 				Schedule:  tt.schedule,
 				Action:    tt.action,
 				Timeout:   tt.timeout,
 				Command:   tt.command,
+				Wait:      tt.wait,
 				Container: tt.container,
 				cron:      c,
 				cli:       cli,
@@ -414,3 +523,75 @@ func TestContainerJobRun(t *testing.T) {
 		})
 	}
 }
+
+func TestContainerJobExecCanceled(t *testing.T) {
+	// Arrange
+	defer func(f func() string) { newExecToken = f }(newExecToken)
+	newExecToken = func() string { return "token" }
+	log.SetOutput(&bytes.Buffer{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cli := NewMockDockerClient(ctrl)
+
+	server, client := net.Pipe()
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cli.EXPECT().ContainerInspect(gomock.Any(), "id1").Return(types.ContainerJSON{}, nil)
+	cli.EXPECT().ContainerExecCreate(gomock.Any(), "id1", container.ExecOptions{AttachStdout: true, AttachStderr: true, Cmd: []string{"sleep", "60"}, Env: []string{"MOBYCRON_EXEC=token"}}).Return(types.IDResponse{ID: "exec1"}, nil)
+	cli.EXPECT().ContainerExecAttach(gomock.Any(), "exec1", container.ExecStartOptions{}).DoAndReturn(func(context.Context, string, container.ExecStartOptions) (types.HijackedResponse, error) {
+		cancel()
+		return types.HijackedResponse{Conn: client, Reader: bufio.NewReader(client)}, nil
+	})
+	cli.EXPECT().ContainerExecCreate(gomock.Any(), "id1", container.ExecOptions{AttachStdout: true, AttachStderr: true, Cmd: []string{"sh", "-c", killScript, "sh", "MOBYCRON_EXEC=token"}}).Return(types.IDResponse{ID: "kill1"}, nil)
+	killServer, killClient := net.Pipe()
+	killServer.Close()
+	cli.EXPECT().ContainerExecAttach(gomock.Any(), "kill1", container.ExecStartOptions{}).Return(types.HijackedResponse{Conn: killClient, Reader: bufio.NewReader(killClient)}, nil)
+
+	j := &ContainerJob{Action: "exec", Command: "sleep 60", Container: types.Container{ID: "id1"}, cli: cli}
+
+	// Act
+	_, err := j.exec(ctx, nil)
+
+	// Assert
+	assert.Equal(t, err, context.Canceled)
+}
+
+func TestContainerJobRunRetry(t *testing.T) {
+	// Arrange
+	defer noRetryDelay()()
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+	log.SetFormatter(&log.JSONFormatter{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	s := NewMockJobSynchroniser(ctrl)
+	cli := NewMockDockerClient(ctrl)
+	s.EXPECT().Add(1)
+	gomock.InOrder(
+		cli.EXPECT().ContainerStart(gomock.Any(), "id1", container.StartOptions{}).Return(errors.New("transient error")),
+		cli.EXPECT().ContainerStart(gomock.Any(), "id1", container.StartOptions{}),
+	)
+	cli.EXPECT().Close()
+	s.EXPECT().Done()
+
+	j := &ContainerJob{
+		Schedule:  "1 * * * *",
+		Action:    "start",
+		Retry:     Retry{Attempts: 3},
+		Container: types.Container{ID: "id1"},
+		cron:      &Cron{sync: s},
+		cli:       cli,
+	}
+
+	// Act
+	j.Run()
+
+	// Assert
+	assert.Assert(t, is.Contains(out.String(), "job attempt failed, retry after delay"))
+	assert.Assert(t, is.Contains(out.String(), "transient error"))
+	assert.Assert(t, is.Contains(out.String(), "\"attempt\":2"))
+	assert.Assert(t, is.Contains(out.String(), "container action completed successfully"))
+}
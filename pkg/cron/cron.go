@@ -1,70 +1,141 @@
 package cron
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/swarm"
 	"github.com/pkg/errors"
 	cron "github.com/robfig/cron/v3"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 )
 
+// Errors returned when a job is inspected or controlled.
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobPaused   = errors.New("job is paused")
+	ErrJobSkipped  = errors.New("job run skipped")
+)
+
 // Cron keeps track of any number of jobs, invoking the associated Job as
 // specified by the schedule. It may be started and stopped.
 type Cron struct {
 	runner   Runner
 	sync     JobSynchroniser
 	fs       afero.Fs
-	cEntries map[string]cron.EntryID
-	sEntries map[string]cron.EntryID
+	cEntries map[string][]cron.EntryID
+	sEntries map[string][]cron.EntryID
+	fEntries map[string]cron.EntryID
+	names    map[string]cron.EntryID
+	paused   map[string]bool
+	format   string
+	second   bool
+	history  *History
+	state    *State
+	notifier *Notifier
+	mailer   *Mailer
+	mu       sync.Mutex
+}
+
+// JobInfo describe a job scheduled in Cron.
+type JobInfo struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	Source    string    `json:"source"`
+	Schedule  string    `json:"schedule"`
+	Command   string    `json:"command,omitempty"`
+	Args      []string  `json:"args,omitempty"`
+	Action    string    `json:"action,omitempty"`
+	Container string    `json:"container,omitempty"`
+	Service   string    `json:"service,omitempty"`
+	Paused    bool      `json:"paused"`
+	Next      time.Time `json:"next"`
+	Prev      time.Time `json:"prev"`
 }
 
 // NewCron return a new Cron job runner.
 func NewCron(parseSecond bool) *Cron {
+	return &Cron{
+		runner:   cron.New(cron.WithParser(newParser(parseSecond))),
+		sync:     &sync.WaitGroup{},
+		fs:       afero.NewOsFs(),
+		cEntries: make(map[string][]cron.EntryID),
+		sEntries: make(map[string][]cron.EntryID),
+		fEntries: make(map[string]cron.EntryID),
+		names:    make(map[string]cron.EntryID),
+		paused:   make(map[string]bool),
+		second:   parseSecond,
+	}
+}
+
+// newParser return the parser of the schedules, with an optional seconds
+// field when parseSecond is true.
+func newParser(parseSecond bool) cron.Parser {
 	option := cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor
 	if parseSecond {
 		option = option | cron.Second
 	}
-
-	return &Cron{
-		cron.New(cron.WithParser(cron.NewParser(option))),
-		&sync.WaitGroup{},
-		afero.NewOsFs(),
-		make(map[string]cron.EntryID),
-		make(map[string]cron.EntryID),
-	}
+	return cron.NewParser(option)
 }
 
 // AddJob adds a Job to the Cron to be run on the given schedule.
 func (c *Cron) AddJob(job Job) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := c.addJob(job)
+	return err
+}
+
+// addJob must be called with c.mu held.
+func (c *Cron) addJob(job Job) (cron.EntryID, error) {
 	log := log.WithFields(log.Fields{
-		"func":     "Cron.AddJob",
-		"schedule": job.Schedule,
-		"command":  job.Command,
-		"args":     strings.Join(job.Args, " "),
+		"func":        "Cron.AddJob",
+		"name":        job.labels()["name"],
+		"schedule":    job.Schedule,
+		"action":      job.Action,
+		"command":     job.Command,
+		"args":        strings.Join(job.Args, " "),
+		"timeout":     job.Timeout,
+		"concurrency": job.Concurrency,
+		"catchup":     job.Catchup,
 	})
 
-	if job.Schedule == "" {
-		return errors.New("schedule is required")
+	if err := job.validate(); err != nil {
+		return 0, err
 	}
 
-	if job.Command == "" {
-		return errors.New("command is required")
+	name, err := c.uniqueName(job.labels()["name"], job.Name != "")
+	if err != nil {
+		return 0, err
+	}
+	if name != job.labels()["name"] {
+		job.Name = name
+		log = log.WithField("name", name)
 	}
 
 	job.cron = c
+	job.guard = newGuard(job.Concurrency)
+	c.keepPaused(name, job.guard)
 
-	if _, err := c.runner.AddJob(job.Schedule, &job); err != nil {
-		return errors.Wrap(err, "failed to add job in cron")
+	ID, err := c.runner.AddJob(job.Schedule, &job)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to add job in cron")
 	}
+	c.reserveName(name, ID)
 
 	log.Infoln("add job to cron")
 
-	return nil
+	return ID, nil
 }
 
 // AddJobs adds jobs to the Cron.
@@ -84,46 +155,62 @@ func (c *Cron) AddJobs(jobs []Job) error {
 func (c *Cron) AddContainerJob(job ContainerJob) error {
 	log := log.WithFields(log.Fields{
 		"func":            "Cron.AddContainerJob",
+		"name":            job.labels()["name"],
 		"schedule":        job.Schedule,
 		"action":          job.Action,
 		"timeout":         job.Timeout,
 		"command":         job.Command,
+		"concurrency":     job.Concurrency,
+		"catchup":         job.Catchup,
+		"wait":            job.Wait,
+		"image":           job.Image,
 		"container.ID":    job.Container.ID,
 		"container.Names": job.Container.Names,
 	})
 
-	if job.Schedule == "" {
-		return errors.New("schedule is required")
+	if err := job.validate(); err != nil {
+		return err
 	}
 
-	if job.Timeout != "" {
-		if _, err := strconv.ParseInt(job.Timeout, 10, 0); err != nil {
-			return errors.New("invalid container timeout, only integer are permitted")
-		}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The docker events and the reconcile of the containers may both add the
+	// jobs of a new container.
+	if c.containerJobAdded(job.Container.ID, job.Label) {
+		log.Infoln("container job already in cron")
+		return nil
 	}
+	c.replaceContainerJob(log, job)
 
-	switch job.Action {
-	case "start", "restart", "stop":
-		if job.Command != "" {
-			return errors.New("a command can be specified only with 'exec' action")
-		}
-	case "exec":
-		if job.Command == "" {
-			return errors.New("command is required")
-		}
-	default:
-		return errors.New("invalid container action, only 'start', 'restart', 'stop' and 'exec' are permitted")
+	job.defaultName = job.Name == ""
+	job.guard = newGuard(job.Concurrency)
+	return c.addContainerJob(log, job)
+}
+
+// addContainerJob add the container job to the runner under a unique name. It
+// must be called with c.mu held.
+func (c *Cron) addContainerJob(log *log.Entry, job ContainerJob) error {
+	name, err := c.uniqueName(job.labels()["name"], !job.defaultName)
+	if err != nil {
+		return err
+	}
+	if name != job.labels()["name"] {
+		job.Name = name
+		log = log.WithField("name", name)
 	}
 
 	log.Infoln("add container job to cron")
 
 	job.cron = c
+	c.keepPaused(name, job.guard)
 	ID, err := c.runner.AddJob(job.Schedule, &job)
 	if err != nil {
 		return errors.Wrap(err, "failed to add container job in cron")
 	}
 
-	c.cEntries[job.Container.ID] = ID
+	c.reserveName(name, ID)
+	c.cEntries[job.Container.ID] = append(c.cEntries[job.Container.ID], ID)
 
 	return nil
 }
@@ -132,64 +219,230 @@ func (c *Cron) AddContainerJob(job ContainerJob) error {
 func (c *Cron) AddServiceJob(job ServiceJob) error {
 	log := log.WithFields(log.Fields{
 		"func":              "Cron.AddServiceJob",
+		"name":              job.labels()["name"],
 		"schedule":          job.Schedule,
 		"action":            job.Action,
+		"concurrency":       job.Concurrency,
+		"catchup":           job.Catchup,
 		"service.ID":        job.ServiceID,
 		"service.Name":      job.ServiceName,
 		"service.Version":   job.ServiceVersion,
 		"service.CreatedAt": job.ServiceCreatedAt,
 	})
 
-	if job.Schedule == "" {
-		return errors.New("schedule is required")
+	if err := job.validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The docker events and the reconcile of the services may both add the
+	// jobs of a new or updated service.
+	if c.serviceJobAdded(job.ServiceID, job.Action) {
+		log.Infoln("service job already in cron")
+		return nil
 	}
 
-	if job.Action != "update" {
-		return errors.New("invalid service action, only 'update' and 'exec' are permitted")
+	name, err := c.uniqueName(job.labels()["name"], job.Name != "")
+	if err != nil {
+		return err
+	}
+	if name != job.labels()["name"] {
+		job.Name = name
+		log = log.WithField("name", name)
 	}
 
 	log.Infoln("add service job to cron")
 
 	job.cron = c
+	job.guard = newGuard(job.Concurrency)
+	c.keepPaused(name, job.guard)
 	ID, err := c.runner.AddJob(job.Schedule, &job)
 	if err != nil {
 		return errors.Wrap(err, "failed to add service job in cron")
 	}
 
-	c.sEntries[job.ServiceID] = ID
+	c.reserveName(name, ID)
+	c.sEntries[job.ServiceID] = append(c.sEntries[job.ServiceID], ID)
 
 	return nil
 }
 
-// RemoveContainerJob remove container job from Cron.
+// containerJobAdded tell if the job with the label of the container with the
+// ID is in Cron. It must be called with c.mu held.
+func (c *Cron) containerJobAdded(ID string, label string) bool {
+	for _, entry := range c.cEntries[ID] {
+		if j, ok := c.runner.Entry(entry).Job.(*ContainerJob); ok && j.Label == label {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceContainerJob remove the job with the name of job when it belongs to
+// the container recreated by the container of job. 'docker compose up' create
+// the new container with a temporary name before the old one is destroyed, so
+// the name set on a job of the old container is given to the job of the new
+// one. It must be called with c.mu held.
+func (c *Cron) replaceContainerJob(log *log.Entry, job ContainerJob) {
+	ID, ok := c.names[job.labels()["name"]]
+	if !ok {
+		return
+	}
+	old, ok := c.runner.Entry(ID).Job.(*ContainerJob)
+	if !ok || !recreates(job.Container, old.Container) {
+		return
+	}
+
+	c.removeEntry(ID)
+	removeID(c.cEntries, ID)
+	log.WithField("replaced.container.ID", old.Container.ID).Infoln("replace container job of recreated container")
+}
+
+// recreates tell if the container replacement is the container created by
+// 'docker compose up' to recreate old. It is named after the short ID and the
+// name of old until old is destroyed, then it is renamed to the name of old.
+func recreates(replacement container.Summary, old container.Summary) bool {
+	return len(old.ID) >= 12 && containerName(replacement) == old.ID[:12]+"_"+containerName(old)
+}
+
+// serviceJobAdded tell if the job with the action of the service with the ID
+// is in Cron. It must be called with c.mu held.
+func (c *Cron) serviceJobAdded(ID string, action string) bool {
+	for _, entry := range c.sEntries[ID] {
+		if j, ok := c.runner.Entry(entry).Job.(*ServiceJob); ok && j.Action == action {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveContainerJob remove all container jobs of a container from Cron.
 func (c *Cron) RemoveContainerJob(ID string) {
-	if entry, ok := c.cEntries[ID]; ok {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entries, ok := c.cEntries[ID]; ok {
 		delete(c.cEntries, ID)
-		c.runner.Remove(entry)
+		names := []string{}
+		for _, entry := range entries {
+			names = append(names, c.removeEntry(entry))
+		}
 
 		log := log.WithFields(log.Fields{
 			"func":         "Cron.RemoveContainerJob",
 			"container.ID": ID,
+			"names":        strings.Join(names, ","),
 		})
 		log.Infoln("remove container job from cron")
 	}
 }
 
-// RemoveServiceJob remove service job from Cron.
+// RenameContainerJob update the jobs of the container with the ID after the
+// container is renamed to name. The default names of its jobs follow the name
+// of the container and its jobs keep their guard, so a paused or running job
+// is not affected.
+func (c *Cron) RenameContainerJob(ID string, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, ok := c.cEntries[ID]
+	if !ok {
+		return
+	}
+
+	log := log.WithFields(log.Fields{
+		"func":           "Cron.RenameContainerJob",
+		"container.ID":   ID,
+		"container.Name": name,
+	})
+
+	// Release all the names first, so that the jobs can swap their names.
+	delete(c.cEntries, ID)
+	jobs := []ContainerJob{}
+	for _, entry := range entries {
+		if j, ok := c.runner.Entry(entry).Job.(*ContainerJob); ok {
+			jobs = append(jobs, *j)
+		}
+		c.removeEntry(entry)
+	}
+
+	for _, job := range jobs {
+		if job.defaultName {
+			job.Name = ""
+		}
+		job.Container.Names = []string{"/" + name}
+		if err := c.addContainerJob(log.WithField("name", job.labels()["name"]), job); err != nil {
+			log.WithError(err).WithField("label", job.Label).Errorln("rename container job is in error")
+		}
+	}
+}
+
+// RemoveServiceJob remove all service jobs of a service from Cron.
 func (c *Cron) RemoveServiceJob(ID string) {
-	if entry, ok := c.sEntries[ID]; ok {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entries, ok := c.sEntries[ID]; ok {
 		delete(c.sEntries, ID)
-		c.runner.Remove(entry)
+		names := []string{}
+		for _, entry := range entries {
+			names = append(names, c.removeEntry(entry))
+		}
 
 		log := log.WithFields(log.Fields{
 			"func":       "Cron.RemoveServiceJob",
 			"service.ID": ID,
+			"names":      strings.Join(names, ","),
 		})
 		log.Infoln("remove service job from cron")
 	}
 }
 
-// LoadConfig read Job from file in JSON format and add them to Cron.
+// Containers return the label names of the jobs in Cron, sorted by container
+// ID.
+func (c *Cron) Containers() map[string][]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	labels := make(map[string][]string)
+	for ID, entries := range c.cEntries {
+		for _, entry := range entries {
+			if j, ok := c.runner.Entry(entry).Job.(*ContainerJob); ok {
+				labels[ID] = append(labels[ID], j.Label)
+			}
+		}
+		sort.Strings(labels[ID])
+	}
+	return labels
+}
+
+// Services return the version of the services with jobs in Cron, by service
+// ID, when their jobs were added.
+func (c *Cron) Services() map[string]swarm.Version {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	versions := make(map[string]swarm.Version)
+	for ID, entries := range c.sEntries {
+		for _, entry := range entries {
+			if j, ok := c.runner.Entry(entry).Job.(*ServiceJob); ok {
+				versions[ID] = j.ServiceVersion
+			}
+		}
+	}
+	return versions
+}
+
+// SetHistory set the history where the runs of all jobs are recorded.
+func (c *Cron) SetHistory(h *History) {
+	c.history = h
+}
+
+// LoadConfig read Job from file in JSON, YAML, TOML or crontab format and add
+// them to Cron. When called again, only the jobs added or removed since the previous
+// load are changed. An invalid config is rejected and the previous jobs are kept.
 func (c *Cron) LoadConfig(filename string) error {
 	log := log.WithFields(log.Fields{
 		"func":     "Cron.LoadConfig",
@@ -202,24 +455,312 @@ func (c *Cron) LoadConfig(filename string) error {
 		return errors.Wrap(err, "failed to read config file")
 	}
 
-	j := []Job{}
-	if err := json.Unmarshal([]byte(config), &j); err != nil {
-		return errors.Wrap(err, "failed to parse JSON data from config file")
+	j, err := parseConfig(config, c.configFormat(filename), c.second)
+	if err != nil {
+		return err
 	}
 
-	if err := c.AddJobs(j); err != nil {
+	if err := c.syncJobs(j); err != nil {
 		return errors.Wrap(err, "failed to add jobs fron config file")
 	}
 	return nil
 }
 
+// WatchConfig poll the config file at each interval and load it again when
+// its content change.
+func (c *Cron) WatchConfig(filename string, interval time.Duration) {
+	c.watchConfig(filename, interval, nil)
+}
+
+// watchConfig watch the config file like WatchConfig until stop is closed and
+// return a channel closed once the watch is stopped.
+func (c *Cron) watchConfig(filename string, interval time.Duration, stop <-chan struct{}) <-chan struct{} {
+	log := log.WithFields(log.Fields{
+		"func":     "Cron.WatchConfig",
+		"filename": filename,
+		"interval": interval.String(),
+	})
+	log.Infoln("watch config file for changes")
+
+	last, _ := afero.ReadFile(c.fs, filename)
+
+	done := make(chan struct{})
+	watch := func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			config, err := afero.ReadFile(c.fs, filename)
+			if err != nil || bytes.Equal(config, last) {
+				continue
+			}
+			last = config
+
+			log.Infoln("config file changed")
+			if err := c.LoadConfig(filename); err != nil {
+				log.WithError(err).Errorln("config file rejected, previous jobs are kept")
+			}
+		}
+	}
+	go watch()
+	return done
+}
+
+// syncJobs replace the jobs loaded from a config file by jobs. Jobs already
+// in Cron are left untouched and if any job is invalid, none are changed.
+func (c *Cron) syncJobs(jobs []Job) error {
+	if jobs == nil {
+		return errors.New("jobs is required")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]string, len(jobs))
+	kept := make(map[string]bool)
+	for i, job := range jobs {
+		key := job.key()
+		for n := 1; kept[key]; n++ {
+			key = fmt.Sprintf("%s#%d", job.key(), n)
+		}
+		keys[i] = key
+		kept[key] = true
+	}
+
+	// Release the names of the removed jobs first, so that a changed job can
+	// keep its name.
+	released := make(map[string]cron.EntryID)
+	for key, ID := range c.fEntries {
+		if !kept[key] {
+			if name := c.releaseName(ID); name != "" {
+				released[name] = ID
+			}
+		}
+	}
+
+	entries := make(map[string]cron.EntryID)
+	added := []cron.EntryID{}
+	for i, job := range jobs {
+		key := keys[i]
+		if ID, ok := c.fEntries[key]; ok {
+			entries[key] = ID
+			continue
+		}
+
+		ID, err := c.addJob(job)
+		if err != nil {
+			for _, ID := range added {
+				c.removeEntry(ID)
+			}
+			for name, ID := range released {
+				c.reserveName(name, ID)
+			}
+			return err
+		}
+		added = append(added, ID)
+		entries[key] = ID
+	}
+
+	for key, ID := range c.fEntries {
+		if _, ok := entries[key]; !ok {
+			c.runner.Remove(ID)
+			log.WithFields(log.Fields{
+				"func": "Cron.syncJobs",
+				"job":  key,
+			}).Infoln("remove job from cron")
+		}
+	}
+	c.fEntries = entries
+
+	return nil
+}
+
+// Jobs return all jobs scheduled in Cron.
+func (c *Cron) Jobs() []JobInfo {
+	jobs := []JobInfo{}
+	for _, entry := range c.runner.Entries() {
+		if info, _, ok := newJobInfo(entry); ok {
+			jobs = append(jobs, info)
+		}
+	}
+	return jobs
+}
+
+// Job return the job scheduled in Cron with the ID.
+func (c *Cron) Job(ID int) (JobInfo, error) {
+	info, _, ok := newJobInfo(c.runner.Entry(cron.EntryID(ID)))
+	if !ok {
+		return JobInfo{}, ErrJobNotFound
+	}
+	return info, nil
+}
+
+// TriggerJob run immediately the job with the ID, outside of its schedule.
+func (c *Cron) TriggerJob(ID int) error {
+	entry := c.runner.Entry(cron.EntryID(ID))
+	info, g, ok := newJobInfo(entry)
+	if !ok {
+		return ErrJobNotFound
+	}
+	if g.isPaused() {
+		return ErrJobPaused
+	}
+
+	log.WithFields(log.Fields{
+		"func":     "Cron.TriggerJob",
+		"id":       ID,
+		"source":   info.Source,
+		"schedule": info.Schedule,
+	}).Infoln("trigger job")
+
+	go entry.Job.Run()
+	return nil
+}
+
+// FindJob return the job scheduled in Cron with the ID or the name, like the
+// name of its runs in the history.
+func (c *Cron) FindJob(name string) (JobInfo, error) {
+	if ID, err := strconv.Atoi(name); err == nil {
+		if info, err := c.Job(ID); err == nil {
+			return info, nil
+		}
+	}
+
+	for _, info := range c.Jobs() {
+		if info.Name == name {
+			return info, nil
+		}
+	}
+	return JobInfo{}, ErrJobNotFound
+}
+
+// RunJob run the job with the ID in the foreground, exactly like on its
+// schedule, and return its error. The output of the job is written to w.
+func (c *Cron) RunJob(ID int, w io.Writer) error {
+	entry := c.runner.Entry(cron.EntryID(ID))
+	info, g, ok := newJobInfo(entry)
+	if !ok {
+		return ErrJobNotFound
+	}
+	if g.isPaused() {
+		return ErrJobPaused
+	}
+
+	log.WithFields(log.Fields{
+		"func":     "Cron.RunJob",
+		"id":       ID,
+		"name":     info.Name,
+		"source":   info.Source,
+		"schedule": info.Schedule,
+	}).Infoln("run job")
+
+	return entry.Job.(scheduledJob).runWith(w)
+}
+
+// PauseJob stop running the job with the ID until it is resumed.
+func (c *Cron) PauseJob(ID int) error {
+	return c.setPaused(ID, true, "pause job")
+}
+
+// ResumeJob run again on schedule the job with the ID.
+func (c *Cron) ResumeJob(ID int) error {
+	return c.setPaused(ID, false, "resume job")
+}
+
+func (c *Cron) setPaused(ID int, paused bool, msg string) error {
+	info, g, ok := newJobInfo(c.runner.Entry(cron.EntryID(ID)))
+	if !ok || g == nil {
+		return ErrJobNotFound
+	}
+	g.setPaused(paused)
+
+	c.mu.Lock()
+	if c.paused == nil {
+		c.paused = make(map[string]bool)
+	}
+	if paused {
+		c.paused[info.Name] = true
+	} else {
+		delete(c.paused, info.Name)
+	}
+	c.mu.Unlock()
+
+	log.WithFields(log.Fields{
+		"func":     "Cron.setPaused",
+		"id":       ID,
+		"source":   info.Source,
+		"schedule": info.Schedule,
+	}).Infoln(msg)
+	return nil
+}
+
+// keepPaused pause the guard of the job added with the name when a job with
+// the name was paused, so a job added again, like the jobs of a service on
+// each update of the service, stay paused. A paused guard kept by a renamed
+// job pause its new name. It must be called with c.mu held.
+func (c *Cron) keepPaused(name string, g *guard) {
+	if c.paused[name] {
+		g.setPaused(true)
+	} else if g.isPaused() {
+		if c.paused == nil {
+			c.paused = make(map[string]bool)
+		}
+		c.paused[name] = true
+	}
+}
+
+func newJobInfo(entry cron.Entry) (JobInfo, *guard, bool) {
+	info := JobInfo{
+		ID:   int(entry.ID),
+		Next: entry.Next,
+		Prev: entry.Prev,
+	}
+
+	var g *guard
+	switch j := entry.Job.(type) {
+	case *Job:
+		info.Source = "file"
+		info.Schedule = j.Schedule
+		info.Command = j.Command
+		info.Args = j.Args
+		g = j.guard
+	case *ContainerJob:
+		info.Source = "container"
+		info.Schedule = j.Schedule
+		info.Command = j.Command
+		info.Action = j.Action
+		info.Container = containerName(j.Container)
+		g = j.guard
+	case *ServiceJob:
+		info.Source = "service"
+		info.Schedule = j.Schedule
+		info.Action = j.Action
+		info.Service = j.ServiceName
+		g = j.guard
+	default:
+		return JobInfo{}, nil, false
+	}
+	info.Name = entry.Job.(scheduledJob).labels()["name"]
+	info.Paused = g.isPaused()
+
+	return info, g, true
+}
+
 // Start the Cron scheduler.
 func (c *Cron) Start() {
 	log.WithFields(log.Fields{"func": "Cron.Start"}).Infoln("start cron")
 	c.runner.Start()
 }
 
-// Stop the Cron scheduler.
+// Stop the Cron scheduler, waiting for the running jobs and the delivery of
+// their notifications and mails.
 func (c *Cron) Stop() context.Context {
 	log := log.WithFields(log.Fields{"func": "Cron.Stop"})
 
@@ -227,6 +768,12 @@ func (c *Cron) Stop() context.Context {
 	ctx := c.runner.Stop()
 
 	c.sync.Wait()
+	if c.notifier != nil {
+		c.notifier.close()
+	}
+	if c.mailer != nil {
+		c.mailer.close()
+	}
 	log.Infoln("cron is stopped, all jobs are completed")
 
 	// TODO: See if the new stop context can be handy
@@ -3,9 +3,13 @@ package cron
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/swarm"
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
 	cron "github.com/robfig/cron/v3"
@@ -47,9 +51,9 @@ func TestAddJob(t *testing.T) {
 	}{
 		{
 			name: "valid job",
-			job:  Job{"3 * * * *", "/bin/bash", []string{"-c echo 1"}, nil},
+			job:  Job{Schedule: "3 * * * *", Command: "/bin/bash", Args: []string{"-c echo 1"}},
 			mock: func(r *MockRunner, c *Cron) {
-				r.EXPECT().AddJob("3 * * * *", &Job{"3 * * * *", "/bin/bash", []string{"-c echo 1"}, c})
+				r.EXPECT().AddJob("3 * * * *", &Job{Schedule: "3 * * * *", Command: "/bin/bash", Args: []string{"-c echo 1"}, cron: c, guard: newGuard("")})
 			},
 			checks: check(
 				hasNilError(),
@@ -58,23 +62,137 @@ func TestAddJob(t *testing.T) {
 		},
 		{
 			name: "job with empty schedule",
-			job:  Job{"", "/bin/bash", []string{"-c echo 1"}, nil},
+			job:  Job{Command: "/bin/bash", Args: []string{"-c echo 1"}},
 			checks: check(
 				hasError("schedule is required"),
 			),
 		},
 		{
 			name: "job with empty command",
-			job:  Job{"3 * * * *", "", []string{"-c echo 1"}, nil},
+			job:  Job{Schedule: "3 * * * *", Args: []string{"-c echo 1"}},
 			checks: check(
 				hasError("command is required"),
 			),
 		},
+		{
+			name: "job with run action",
+			job:  Job{Schedule: "3 * * * *", Action: "run", Image: "alpine"},
+			mock: func(r *MockRunner, c *Cron) {
+				r.EXPECT().AddJob("3 * * * *", &Job{Schedule: "3 * * * *", Action: "run", Image: "alpine", cron: c, guard: newGuard("")})
+			},
+			checks: check(
+				hasNilError(),
+				hasLogField("action", "run"),
+			),
+		},
+		{
+			name: "job with run action and empty image",
+			job:  Job{Schedule: "3 * * * *", Action: "run", Command: "echo"},
+			checks: check(
+				hasError("image is required"),
+			),
+		},
+		{
+			name: "job with invalid action",
+			job:  Job{Schedule: "3 * * * *", Action: "start", Command: "echo"},
+			checks: check(
+				hasError("invalid job action, only 'run' is permitted"),
+			),
+		},
+		{
+			name: "job with concurrency policy",
+			job:  Job{Schedule: "3 * * * *", Command: "/bin/bash", Concurrency: "skip"},
+			mock: func(r *MockRunner, c *Cron) {
+				r.EXPECT().AddJob("3 * * * *", &Job{Schedule: "3 * * * *", Command: "/bin/bash", Concurrency: "skip", cron: c, guard: newGuard("skip")})
+			},
+			checks: check(
+				hasNilError(),
+				hasLogField("concurrency", "skip"),
+			),
+		},
+		{
+			name: "job with invalid concurrency policy",
+			job:  Job{Schedule: "3 * * * *", Command: "/bin/bash", Concurrency: "invalid"},
+			checks: check(
+				hasError("invalid concurrency policy, only 'allow', 'skip', 'queue' and 'replace' are permitted"),
+			),
+		},
+		{
+			name: "job with catch-up policy",
+			job:  Job{Schedule: "3 * * * *", Command: "/bin/bash", Catchup: "once"},
+			mock: func(r *MockRunner, c *Cron) {
+				r.EXPECT().AddJob("3 * * * *", &Job{Schedule: "3 * * * *", Command: "/bin/bash", Catchup: "once", cron: c, guard: newGuard("")})
+			},
+			checks: check(
+				hasNilError(),
+				hasLogField("catchup", "once"),
+			),
+		},
+		{
+			name: "job with invalid catch-up policy",
+			job:  Job{Schedule: "3 * * * *", Command: "/bin/bash", Catchup: "invalid"},
+			checks: check(
+				hasError("invalid catch-up policy, only 'none', 'once' and 'all' are permitted"),
+			),
+		},
+		{
+			name: "job with invalid retry",
+			job:  Job{Schedule: "3 * * * *", Command: "/bin/bash", Retry: Retry{Attempts: -1}},
+			checks: check(
+				hasError("invalid retry attempts, only positive integer are permitted"),
+			),
+		},
+		{
+			name: "job with invalid ping",
+			job:  Job{Schedule: "3 * * * *", Command: "/bin/bash", Ping: Ping{URL: "hc-ping.com/uuid"}},
+			checks: check(
+				hasError("invalid ping url 'hc-ping.com/uuid', only http and https urls are permitted"),
+			),
+		},
+		{
+			name: "job with invalid mailto",
+			job:  Job{Schedule: "3 * * * *", Command: "/bin/bash", MailTo: "ops@"},
+			checks: check(
+				hasError("invalid mail address 'ops@'"),
+			),
+		},
+		{
+			name: "job with invalid notify",
+			job:  Job{Schedule: "3 * * * *", Command: "/bin/bash", Notify: Notify{On: "never"}},
+			checks: check(
+				hasError("invalid notify condition, only 'failure', 'success', 'recovery' and 'always' are permitted"),
+			),
+		},
+		{
+			name: "job with timeout",
+			job:  Job{Schedule: "3 * * * *", Command: "/bin/bash", Timeout: "30s"},
+			mock: func(r *MockRunner, c *Cron) {
+				r.EXPECT().AddJob("3 * * * *", &Job{Schedule: "3 * * * *", Command: "/bin/bash", Timeout: "30s", cron: c, guard: newGuard("")})
+			},
+			checks: check(
+				hasNilError(),
+				hasLogField("timeout", "30s"),
+			),
+		},
+		{
+			name: "job with invalid timeout",
+			job:  Job{Schedule: "3 * * * *", Command: "/bin/bash", Timeout: "30"},
+			checks: check(
+				hasError("invalid job timeout, only positive duration like '30s' or '5m' are permitted"),
+			),
+		},
+		{
+			name: "job with negative timeout",
+			job:  Job{Schedule: "3 * * * *", Command: "/bin/bash", Timeout: "-1s"},
+			checks: check(
+				hasError("invalid job timeout, only positive duration like '30s' or '5m' are permitted"),
+			),
+		},
 		{
 			name: "job with empty args",
-			job:  Job{"3 * * * *", "/bin/bash", []string{""}, nil},
+			job:  Job{Schedule: "3 * * * *", Command: "/bin/bash", Args: []string{""}},
 			mock: func(r *MockRunner, c *Cron) {
-				r.EXPECT().AddJob(gomock.Any(), &Job{"3 * * * *", "/bin/bash", []string{""}, c})
+				r.EXPECT().AddJob(gomock.Any(), &Job{Schedule: "3 * * * *", Command: "/bin/bash", Args: []string{""}, cron: c, guard: newGuard("")})
 			},
 			checks: check(
 				hasNilError(),
@@ -83,9 +201,9 @@ func TestAddJob(t *testing.T) {
 		},
 		{
 			name: "job with nil args",
-			job:  Job{"3 * * * *", "/bin/bash", nil, nil},
+			job:  Job{Schedule: "3 * * * *", Command: "/bin/bash"},
 			mock: func(r *MockRunner, c *Cron) {
-				r.EXPECT().AddJob("3 * * * *", &Job{"3 * * * *", "/bin/bash", nil, c})
+				r.EXPECT().AddJob("3 * * * *", &Job{Schedule: "3 * * * *", Command: "/bin/bash", cron: c, guard: newGuard("")})
 			},
 			checks: check(
 				hasNilError(),
@@ -94,7 +212,7 @@ func TestAddJob(t *testing.T) {
 		},
 		{
 			name: "CronRunner.AddJob return error",
-			job:  Job{"3 * * * *", "/bin/bash", nil, nil},
+			job:  Job{Schedule: "3 * * * *", Command: "/bin/bash"},
 			mock: func(r *MockRunner, c *Cron) {
 				r.EXPECT().AddJob(gomock.Any(), gomock.Any()).Return(cron.EntryID(0), fmt.Errorf("a error"))
 			},
@@ -115,7 +233,7 @@ func TestAddJob(t *testing.T) {
 			defer ctrl.Finish()
 			r := NewMockRunner(ctrl)
 
-			c := &Cron{r, nil, nil, nil, nil}
+			c := &Cron{runner: r}
 			if tt.mock != nil {
 				tt.mock(r, c)
 			}
@@ -164,9 +282,9 @@ func TestAddJobs(t *testing.T) {
 		},
 		{
 			name: "one job",
-			jobs: []Job{{"3 * * * *", "echo", []string{"1"}, nil}},
+			jobs: []Job{{Schedule: "3 * * * *", Command: "echo", Args: []string{"1"}}},
 			mock: func(r *MockRunner, c *Cron) {
-				r.EXPECT().AddJob("3 * * * *", &Job{"3 * * * *", "echo", []string{"1"}, c})
+				r.EXPECT().AddJob("3 * * * *", &Job{Schedule: "3 * * * *", Command: "echo", Args: []string{"1"}, cron: c, guard: newGuard("")})
 			},
 			checks: check(
 				hasNilError(),
@@ -175,12 +293,12 @@ func TestAddJobs(t *testing.T) {
 		{
 			name: "many jobs",
 			jobs: []Job{
-				{"1 * * * *", "echo1", []string{"1"}, nil},
-				{"2 * * * *", "echo2", []string{"2"}, nil},
+				{Schedule: "1 * * * *", Command: "echo1", Args: []string{"1"}},
+				{Schedule: "2 * * * *", Command: "echo2", Args: []string{"2"}},
 			},
 			mock: func(r *MockRunner, c *Cron) {
-				r.EXPECT().AddJob("1 * * * *", &Job{"1 * * * *", "echo1", []string{"1"}, c})
-				r.EXPECT().AddJob("2 * * * *", &Job{"2 * * * *", "echo2", []string{"2"}, c})
+				r.EXPECT().AddJob("1 * * * *", &Job{Schedule: "1 * * * *", Command: "echo1", Args: []string{"1"}, cron: c, guard: newGuard("")})
+				r.EXPECT().AddJob("2 * * * *", &Job{Schedule: "2 * * * *", Command: "echo2", Args: []string{"2"}, cron: c, guard: newGuard("")})
 			},
 			checks: check(
 				hasNilError(),
@@ -188,7 +306,7 @@ func TestAddJobs(t *testing.T) {
 		},
 		{
 			name: "AddJob return error",
-			jobs: []Job{{"3 * * * *", "echo", []string{"1"}, nil}},
+			jobs: []Job{{Schedule: "3 * * * *", Command: "echo", Args: []string{"1"}}},
 			mock: func(r *MockRunner, c *Cron) {
 				r.EXPECT().AddJob(gomock.Any(), gomock.Any()).Return(cron.EntryID(1), fmt.Errorf("a error"))
 			},
@@ -215,7 +333,7 @@ func TestAddJobs(t *testing.T) {
 			defer ctrl.Finish()
 			r := NewMockRunner(ctrl)
 
-			c := &Cron{r, nil, nil, nil, nil}
+			c := &Cron{runner: r}
 			if tt.mock != nil {
 				tt.mock(r, c)
 			}
@@ -255,9 +373,9 @@ func TestAddContainerJob(t *testing.T) {
 		}
 	}
 
-	hasEntries := func(key string, want cron.EntryID) checkFunc {
+	hasEntries := func(key string, want ...cron.EntryID) checkFunc {
 		return func(t *testing.T, c *Cron, out string, err error) {
-			assert.Assert(t, is.Equal(c.cEntries[key], want))
+			assert.Assert(t, is.DeepEqual(c.cEntries[key], want))
 		}
 	}
 
@@ -278,7 +396,7 @@ func TestAddContainerJob(t *testing.T) {
 			name: "one container",
 			job1: ContainerJob{Schedule: "1 * * * *", Action: "start", Container: types.Container{ID: "ID1"}},
 			mock: func(r *MockRunner, c *Cron) {
-				j := &ContainerJob{Schedule: "1 * * * *", Action: "start", Container: types.Container{ID: "ID1"}, cron: c}
+				j := &ContainerJob{Schedule: "1 * * * *", Action: "start", Container: types.Container{ID: "ID1"}, cron: c, guard: newGuard(""), defaultName: true}
 				id := cron.EntryID(1)
 				r.EXPECT().AddJob("1 * * * *", j).Return(id, nil)
 			},
@@ -293,9 +411,9 @@ func TestAddContainerJob(t *testing.T) {
 			job1: ContainerJob{Schedule: "1 * * * *", Action: "start", Container: types.Container{ID: "ID1"}},
 			job2: &ContainerJob{Schedule: "2 * * * *", Action: "start", Container: types.Container{ID: "ID2"}},
 			mock: func(r *MockRunner, c *Cron) {
-				j1 := &ContainerJob{Schedule: "1 * * * *", Action: "start", Container: types.Container{ID: "ID1"}, cron: c}
+				j1 := &ContainerJob{Schedule: "1 * * * *", Action: "start", Container: types.Container{ID: "ID1"}, cron: c, guard: newGuard(""), defaultName: true}
 				id1 := cron.EntryID(1)
-				j2 := &ContainerJob{Schedule: "2 * * * *", Action: "start", Container: types.Container{ID: "ID2"}, cron: c}
+				j2 := &ContainerJob{Schedule: "2 * * * *", Action: "start", Container: types.Container{ID: "ID2"}, cron: c, guard: newGuard(""), defaultName: true}
 				id2 := cron.EntryID(2)
 
 				r.EXPECT().AddJob("1 * * * *", j1).Return(id1, nil)
@@ -308,6 +426,24 @@ func TestAddContainerJob(t *testing.T) {
 				hasLogField("msg", "add container job to cron"),
 			),
 		},
+		{
+			name: "many jobs for one container",
+			job1: ContainerJob{Name: "backup", Schedule: "1 * * * *", Action: "exec", Command: "dump", Container: types.Container{ID: "ID1"}},
+			job2: &ContainerJob{Name: "restart", Label: "restart", Schedule: "2 * * * *", Action: "restart", Container: types.Container{ID: "ID1"}},
+			mock: func(r *MockRunner, c *Cron) {
+				j1 := &ContainerJob{Name: "backup", Schedule: "1 * * * *", Action: "exec", Command: "dump", Container: types.Container{ID: "ID1"}, cron: c, guard: newGuard("")}
+				j2 := &ContainerJob{Name: "restart", Label: "restart", Schedule: "2 * * * *", Action: "restart", Container: types.Container{ID: "ID1"}, cron: c, guard: newGuard("")}
+
+				r.EXPECT().AddJob("1 * * * *", j1).Return(cron.EntryID(1), nil)
+				r.EXPECT().Entry(cron.EntryID(1)).Return(cron.Entry{ID: 1, Job: j1})
+				r.EXPECT().AddJob("2 * * * *", j2).Return(cron.EntryID(2), nil)
+			},
+			checks: check(
+				hasNilError(),
+				hasEntries("ID1", 1, 2),
+				hasLogField("name", "restart"),
+			),
+		},
 		{
 			name: "job with empty schedule",
 			job1: ContainerJob{Schedule: ""},
@@ -320,7 +456,7 @@ func TestAddContainerJob(t *testing.T) {
 			name: "job with empty timeout",
 			job1: ContainerJob{Schedule: "3 * * * *", Action: "restart", Timeout: ""},
 			mock: func(r *MockRunner, c *Cron) {
-				r.EXPECT().AddJob("3 * * * *", &ContainerJob{Schedule: "3 * * * *", Action: "restart", cron: c})
+				r.EXPECT().AddJob("3 * * * *", &ContainerJob{Schedule: "3 * * * *", Action: "restart", cron: c, guard: newGuard(""), defaultName: true})
 			},
 			checks: check(
 				hasNilError(),
@@ -336,11 +472,51 @@ func TestAddContainerJob(t *testing.T) {
 				hasNoEntries(),
 			),
 		},
+		{
+			name: "invalid concurrency policy",
+			job1: ContainerJob{Schedule: "3 * * * *", Action: "start", Concurrency: "invalid"},
+			checks: check(
+				hasError("invalid concurrency policy, only 'allow', 'skip', 'queue' and 'replace' are permitted"),
+				hasNoEntries(),
+			),
+		},
+		{
+			name: "invalid catch-up policy",
+			job1: ContainerJob{Schedule: "3 * * * *", Action: "start", Catchup: "invalid"},
+			checks: check(
+				hasError("invalid catch-up policy, only 'none', 'once' and 'all' are permitted"),
+				hasNoEntries(),
+			),
+		},
+		{
+			name: "invalid retry",
+			job1: ContainerJob{Schedule: "3 * * * *", Action: "start", Retry: Retry{Delay: "soon"}},
+			checks: check(
+				hasError("invalid retry delay, only positive duration like '30s' or '5m' are permitted"),
+				hasNoEntries(),
+			),
+		},
+		{
+			name: "invalid mail condition",
+			job1: ContainerJob{Schedule: "3 * * * *", Action: "start", MailOn: "sometimes"},
+			checks: check(
+				hasError("invalid mail condition, only 'output', 'failure', 'always' and 'never' are permitted"),
+				hasNoEntries(),
+			),
+		},
+		{
+			name: "invalid notify",
+			job1: ContainerJob{Schedule: "3 * * * *", Action: "start", Notify: Notify{URLs: []string{"ftp://hooks"}}},
+			checks: check(
+				hasError("invalid notify url 'ftp://hooks', only http and https urls are permitted"),
+				hasNoEntries(),
+			),
+		},
 		{
 			name: "invalid action",
 			job1: ContainerJob{Schedule: "3 * * * *", Action: "invalid"},
 			checks: check(
-				hasError("invalid container action, only 'start', 'restart', 'stop' and 'exec' are permitted"),
+				hasError("invalid container action, only 'start', 'restart', 'stop', 'exec' and 'run' are permitted"),
 				hasNoEntries(),
 			),
 		},
@@ -348,7 +524,7 @@ func TestAddContainerJob(t *testing.T) {
 			name: "invalid command when action is start",
 			job1: ContainerJob{Schedule: "* * * * *", Action: "start", Command: "ls"},
 			checks: check(
-				hasError("a command can be specified only with 'exec' action"),
+				hasError("a command can be specified only with 'exec' and 'run' actions"),
 				hasNoEntries(),
 			),
 		},
@@ -356,7 +532,7 @@ func TestAddContainerJob(t *testing.T) {
 			name: "invalid command when action is restart",
 			job1: ContainerJob{Schedule: "* * * * *", Action: "restart", Command: "ls"},
 			checks: check(
-				hasError("a command can be specified only with 'exec' action"),
+				hasError("a command can be specified only with 'exec' and 'run' actions"),
 				hasNoEntries(),
 			),
 		},
@@ -364,7 +540,7 @@ func TestAddContainerJob(t *testing.T) {
 			name: "invalid command when action is stop",
 			job1: ContainerJob{Schedule: "* * * * *", Action: "stop", Command: "ls"},
 			checks: check(
-				hasError("a command can be specified only with 'exec' action"),
+				hasError("a command can be specified only with 'exec' and 'run' actions"),
 				hasNoEntries(),
 			),
 		},
@@ -379,6 +555,53 @@ func TestAddContainerJob(t *testing.T) {
 				hasEntries("", 0),
 			),
 		},
+		{
+			name: "run action with image",
+			job1: ContainerJob{Schedule: "* * * * *", Action: "run", Image: "alpine", Command: "ls"},
+			mock: func(r *MockRunner, c *Cron) {
+				r.EXPECT().AddJob(gomock.Any(), gomock.Any())
+			},
+			checks: check(
+				hasNilError(),
+				hasLogField("image", "alpine"),
+			),
+		},
+		{
+			name: "run action with image of container",
+			job1: ContainerJob{Schedule: "* * * * *", Action: "run", Container: types.Container{Image: "alpine"}},
+			mock: func(r *MockRunner, c *Cron) {
+				r.EXPECT().AddJob(gomock.Any(), gomock.Any())
+			},
+			checks: check(
+				hasNilError(),
+			),
+		},
+		{
+			name: "wait with start action",
+			job1: ContainerJob{Schedule: "* * * * *", Action: "start", Wait: true, Timeout: "60"},
+			mock: func(r *MockRunner, c *Cron) {
+				r.EXPECT().AddJob(gomock.Any(), gomock.Any())
+			},
+			checks: check(
+				hasNilError(),
+			),
+		},
+		{
+			name: "wait with other action",
+			job1: ContainerJob{Schedule: "* * * * *", Action: "restart", Wait: true},
+			checks: check(
+				hasError("wait can be specified only with 'start' action"),
+				hasNoEntries(),
+			),
+		},
+		{
+			name: "image required when action is run",
+			job1: ContainerJob{Schedule: "* * * * *", Action: "run"},
+			checks: check(
+				hasError("image is required"),
+				hasNoEntries(),
+			),
+		},
 		{
 			name: "command required when action is exec",
 			job1: ContainerJob{Schedule: "* * * * *", Action: "exec", Command: ""},
@@ -411,7 +634,7 @@ func TestAddContainerJob(t *testing.T) {
 			defer ctrl.Finish()
 			r := NewMockRunner(ctrl)
 
-			c := &Cron{r, nil, nil, make(map[string]cron.EntryID), nil}
+			c := &Cron{runner: r, cEntries: make(map[string][]cron.EntryID)}
 			if tt.mock != nil {
 				tt.mock(r, c)
 			}
@@ -454,9 +677,9 @@ func TestAddServiceJob(t *testing.T) {
 		}
 	}
 
-	hasEntries := func(key string, want cron.EntryID) checkFunc {
+	hasEntries := func(key string, want ...cron.EntryID) checkFunc {
 		return func(t *testing.T, c *Cron, out string, err error) {
-			assert.Assert(t, is.Equal(c.sEntries[key], want))
+			assert.Assert(t, is.DeepEqual(c.sEntries[key], want))
 		}
 	}
 
@@ -475,9 +698,9 @@ func TestAddServiceJob(t *testing.T) {
 	}{
 		{
 			name: "one service",
-			job1: ServiceJob{Schedule: "1 * * * *", Action: "update", ServiceID: "ID1"},
+			job1: ServiceJob{Schedule: "1 * * * *", Action: "update", ServiceID: "ID1", ServiceName: "s1"},
 			mock: func(r *MockRunner, c *Cron) {
-				j := &ServiceJob{Schedule: "1 * * * *", Action: "update", ServiceID: "ID1", cron: c}
+				j := &ServiceJob{Schedule: "1 * * * *", Action: "update", ServiceID: "ID1", ServiceName: "s1", cron: c, guard: newGuard("")}
 				id := cron.EntryID(1)
 				r.EXPECT().AddJob("1 * * * *", j).Return(id, nil)
 			},
@@ -489,12 +712,12 @@ func TestAddServiceJob(t *testing.T) {
 		},
 		{
 			name: "multiples services",
-			job1: ServiceJob{Schedule: "1 * * * *", Action: "update", ServiceID: "ID1"},
-			job2: &ServiceJob{Schedule: "2 * * * *", Action: "update", ServiceID: "ID2"},
+			job1: ServiceJob{Schedule: "1 * * * *", Action: "update", ServiceID: "ID1", ServiceName: "s1"},
+			job2: &ServiceJob{Schedule: "2 * * * *", Action: "update", ServiceID: "ID2", ServiceName: "s2"},
 			mock: func(r *MockRunner, c *Cron) {
-				j1 := &ServiceJob{Schedule: "1 * * * *", Action: "update", ServiceID: "ID1", cron: c}
+				j1 := &ServiceJob{Schedule: "1 * * * *", Action: "update", ServiceID: "ID1", ServiceName: "s1", cron: c, guard: newGuard("")}
 				id1 := cron.EntryID(1)
-				j2 := &ServiceJob{Schedule: "2 * * * *", Action: "update", ServiceID: "ID2", cron: c}
+				j2 := &ServiceJob{Schedule: "2 * * * *", Action: "update", ServiceID: "ID2", ServiceName: "s2", cron: c, guard: newGuard("")}
 				id2 := cron.EntryID(2)
 
 				r.EXPECT().AddJob("1 * * * *", j1).Return(id1, nil)
@@ -507,6 +730,21 @@ func TestAddServiceJob(t *testing.T) {
 				hasLogField("msg", "add service job to cron"),
 			),
 		},
+		{
+			name: "scale down and up jobs of a service",
+			job1: ServiceJob{Schedule: "0 20 * * *", Action: "scaledown", ServiceID: "ID1", ServiceName: "s1"},
+			job2: &ServiceJob{Schedule: "0 7 * * *", Action: "scaleup", ServiceID: "ID1", ServiceName: "s1"},
+			mock: func(r *MockRunner, c *Cron) {
+				r.EXPECT().AddJob("0 20 * * *", gomock.Any()).Return(cron.EntryID(1), nil)
+				r.EXPECT().Entry(cron.EntryID(1)).Return(cron.Entry{ID: 1, Job: &ServiceJob{Action: "scaledown"}})
+				r.EXPECT().AddJob("0 7 * * *", gomock.Any()).Return(cron.EntryID(2), nil)
+			},
+			checks: check(
+				hasNilError(),
+				hasEntries("ID1", 1, 2),
+				hasLogField("name", "s1-scaleup"),
+			),
+		},
 		{
 			name: "job with empty schedule",
 			job1: ServiceJob{Schedule: ""},
@@ -515,11 +753,99 @@ func TestAddServiceJob(t *testing.T) {
 				hasNoEntries(),
 			),
 		},
+		{
+			name: "invalid concurrency policy",
+			job1: ServiceJob{Schedule: "3 * * * *", Action: "update", Concurrency: "invalid"},
+			checks: check(
+				hasError("invalid concurrency policy, only 'allow', 'skip', 'queue' and 'replace' are permitted"),
+				hasNoEntries(),
+			),
+		},
+		{
+			name: "invalid catch-up policy",
+			job1: ServiceJob{Schedule: "3 * * * *", Action: "update", Catchup: "invalid"},
+			checks: check(
+				hasError("invalid catch-up policy, only 'none', 'once' and 'all' are permitted"),
+				hasNoEntries(),
+			),
+		},
+		{
+			name: "invalid retry",
+			job1: ServiceJob{Schedule: "3 * * * *", Action: "update", Retry: Retry{Multiplier: 0.5}},
+			checks: check(
+				hasError("invalid retry multiplier, only number greater or equal to 1 are permitted"),
+				hasNoEntries(),
+			),
+		},
+		{
+			name: "invalid mail condition",
+			job1: ServiceJob{Schedule: "3 * * * *", Action: "update", MailOn: "sometimes"},
+			checks: check(
+				hasError("invalid mail condition, only 'output', 'failure', 'always' and 'never' are permitted"),
+				hasNoEntries(),
+			),
+		},
+		{
+			name: "invalid notify",
+			job1: ServiceJob{Schedule: "3 * * * *", Action: "update", Notify: Notify{Template: "{{.Job"}},
+			checks: check(
+				hasError("invalid notify template"),
+				hasNoEntries(),
+			),
+		},
 		{
 			name: "invalid action",
 			job1: ServiceJob{Schedule: "3 * * * *", Action: "invalid"},
 			checks: check(
-				hasError("invalid service action, only 'update' and 'exec' are permitted"),
+				hasError("invalid service action, only 'update', 'exec', 'run', 'scale', 'rollback', 'scaledown' and 'scaleup' are permitted"),
+				hasNoEntries(),
+			),
+		},
+		{
+			name: "scale without replicas",
+			job1: ServiceJob{Schedule: "3 * * * *", Action: "scale"},
+			checks: check(
+				hasError("replicas is required"),
+				hasNoEntries(),
+			),
+		},
+		{
+			name: "exec without command",
+			job1: ServiceJob{Schedule: "3 * * * *", Action: "exec"},
+			checks: check(
+				hasError("command is required"),
+				hasNoEntries(),
+			),
+		},
+		{
+			name: "invalid slot",
+			job1: ServiceJob{Schedule: "3 * * * *", Action: "exec", Command: "pg_dump", Slot: "0"},
+			checks: check(
+				hasError("invalid service slot, only positive integer are permitted"),
+				hasNoEntries(),
+			),
+		},
+		{
+			name: "command without exec action",
+			job1: ServiceJob{Schedule: "3 * * * *", Action: "update", Command: "pg_dump"},
+			checks: check(
+				hasError("a command can be specified only with 'exec' action"),
+				hasNoEntries(),
+			),
+		},
+		{
+			name: "timeout without run action",
+			job1: ServiceJob{Schedule: "3 * * * *", Action: "update", Timeout: "60"},
+			checks: check(
+				hasError("a timeout can be specified only with 'run' action"),
+				hasNoEntries(),
+			),
+		},
+		{
+			name: "invalid replicas",
+			job1: ServiceJob{Schedule: "3 * * * *", Action: "scaledown", Replicas: "-1"},
+			checks: check(
+				hasError("invalid service replicas, only positive integer are permitted"),
 				hasNoEntries(),
 			),
 		},
@@ -547,7 +873,7 @@ func TestAddServiceJob(t *testing.T) {
 			defer ctrl.Finish()
 			r := NewMockRunner(ctrl)
 
-			c := &Cron{r, nil, nil, nil, make(map[string]cron.EntryID)}
+			c := &Cron{runner: r, sEntries: make(map[string][]cron.EntryID)}
 			if tt.mock != nil {
 				tt.mock(r, c)
 			}
@@ -584,9 +910,9 @@ func TestRemoveContainerJob(t *testing.T) {
 		}
 	}
 
-	hasEntries := func(key string, want cron.EntryID) checkFunc {
+	hasEntries := func(key string, want ...cron.EntryID) checkFunc {
 		return func(t *testing.T, c *Cron, out string) {
-			assert.Assert(t, is.Equal(c.cEntries[key], want))
+			assert.Assert(t, is.DeepEqual(c.cEntries[key], want))
 		}
 	}
 
@@ -599,14 +925,14 @@ func TestRemoveContainerJob(t *testing.T) {
 	tests := []struct {
 		name    string
 		ID      string
-		entries map[string]cron.EntryID
+		entries map[string][]cron.EntryID
 		mock    mockFunc
 		checks  []checkFunc
 	}{
 		{
 			name:    "ID not exist",
 			ID:      "ID22222",
-			entries: map[string]cron.EntryID{"ID1": 0},
+			entries: map[string][]cron.EntryID{"ID1": {0}},
 			checks: check(
 				hasEntries("ID1", 0),
 				hasNoLog(),
@@ -615,9 +941,10 @@ func TestRemoveContainerJob(t *testing.T) {
 		{
 			name:    "ID exist",
 			ID:      "ID1",
-			entries: map[string]cron.EntryID{"ID1": 111},
+			entries: map[string][]cron.EntryID{"ID1": {111, 112}},
 			mock: func(r *MockRunner, c *Cron) {
 				r.EXPECT().Remove(cron.EntryID(111))
+				r.EXPECT().Remove(cron.EntryID(112))
 			},
 			checks: check(
 				hasNoEntries(),
@@ -638,7 +965,7 @@ func TestRemoveContainerJob(t *testing.T) {
 			defer ctrl.Finish()
 			r := NewMockRunner(ctrl)
 
-			c := &Cron{r, nil, nil, tt.entries, nil}
+			c := &Cron{runner: r, cEntries: tt.entries}
 			if tt.mock != nil {
 				tt.mock(r, c)
 			}
@@ -672,9 +999,9 @@ func TestRemoveServiceJob(t *testing.T) {
 		}
 	}
 
-	hasEntries := func(key string, want cron.EntryID) checkFunc {
+	hasEntries := func(key string, want ...cron.EntryID) checkFunc {
 		return func(t *testing.T, c *Cron, out string) {
-			assert.Assert(t, is.Equal(c.sEntries[key], want))
+			assert.Assert(t, is.DeepEqual(c.sEntries[key], want))
 		}
 	}
 
@@ -687,14 +1014,14 @@ func TestRemoveServiceJob(t *testing.T) {
 	tests := []struct {
 		name    string
 		ID      string
-		entries map[string]cron.EntryID
+		entries map[string][]cron.EntryID
 		mock    mockFunc
 		checks  []checkFunc
 	}{
 		{
 			name:    "ID not exist",
 			ID:      "ID22222",
-			entries: map[string]cron.EntryID{"ID1": 0},
+			entries: map[string][]cron.EntryID{"ID1": {0}},
 			checks: check(
 				hasEntries("ID1", 0),
 				hasNoLog(),
@@ -703,9 +1030,10 @@ func TestRemoveServiceJob(t *testing.T) {
 		{
 			name:    "ID exist",
 			ID:      "ID1",
-			entries: map[string]cron.EntryID{"ID1": 111},
+			entries: map[string][]cron.EntryID{"ID1": {111, 112}},
 			mock: func(r *MockRunner, c *Cron) {
 				r.EXPECT().Remove(cron.EntryID(111))
+				r.EXPECT().Remove(cron.EntryID(112))
 			},
 			checks: check(
 				hasNoEntries(),
@@ -726,7 +1054,7 @@ func TestRemoveServiceJob(t *testing.T) {
 			defer ctrl.Finish()
 			r := NewMockRunner(ctrl)
 
-			c := &Cron{r, nil, nil, nil, tt.entries}
+			c := &Cron{runner: r, sEntries: tt.entries}
 			if tt.mock != nil {
 				tt.mock(r, c)
 			}
@@ -742,6 +1070,55 @@ func TestRemoveServiceJob(t *testing.T) {
 	}
 }
 
+func TestContainersServices(t *testing.T) {
+	// Arrange
+	c := NewCron(false)
+	db := container.Summary{ID: "C2", Names: []string{"/db"}}
+	web := container.Summary{ID: "C1", Names: []string{"/web"}}
+	assert.NilError(t, c.AddContainerJob(ContainerJob{Schedule: "@daily", Action: "restart", Container: db}))
+	assert.NilError(t, c.AddContainerJob(ContainerJob{Schedule: "@daily", Action: "restart", Container: web}))
+	assert.NilError(t, c.AddContainerJob(ContainerJob{Label: "stop", Schedule: "@hourly", Action: "stop", Container: web}))
+	assert.NilError(t, c.AddServiceJob(ServiceJob{Schedule: "@daily", Action: "scaledown", ServiceID: "S1", ServiceName: "api", ServiceVersion: swarm.Version{Index: 12}}))
+	assert.NilError(t, c.AddServiceJob(ServiceJob{Schedule: "@daily", Action: "scaleup", ServiceID: "S1", ServiceName: "api", ServiceVersion: swarm.Version{Index: 12}}))
+
+	// Act
+	containers := c.Containers()
+	services := c.Services()
+
+	// Assert
+	assert.Assert(t, is.DeepEqual(containers, map[string][]string{"C1": {"", "stop"}, "C2": {""}}))
+	assert.Assert(t, is.DeepEqual(services, map[string]swarm.Version{"S1": {Index: 12}}))
+}
+
+func TestAddContainerServiceJobTwice(t *testing.T) {
+	// Arrange
+	c := NewCron(false)
+	db := container.Summary{ID: "C1", Names: []string{"/db"}}
+	jobs := []ContainerJob{
+		{Schedule: "@daily", Action: "restart", Container: db},
+		{Label: "dump", Schedule: "@hourly", Action: "exec", Command: "dump", Container: db},
+	}
+	services := []ServiceJob{
+		{Schedule: "0 20 * * *", Action: "scaledown", ServiceID: "S1", ServiceName: "api"},
+		{Schedule: "0 7 * * *", Action: "scaleup", ServiceID: "S1", ServiceName: "api"},
+	}
+
+	// Act, like a docker event and a reconcile adding the same jobs
+	for i := 0; i < 2; i++ {
+		for _, j := range jobs {
+			assert.NilError(t, c.AddContainerJob(j))
+		}
+		for _, j := range services {
+			assert.NilError(t, c.AddServiceJob(j))
+		}
+	}
+
+	// Assert
+	assert.Assert(t, is.DeepEqual(names(c), []string{"db-restart", "db-dump", "api-scaledown", "api-scaleup"}))
+	assert.Assert(t, is.Len(c.cEntries["C1"], 2))
+	assert.Assert(t, is.Len(c.sEntries["S1"], 2))
+}
+
 func TestStart(t *testing.T) {
 	type checkFunc func(*testing.T, string)
 	check := func(fns ...checkFunc) []checkFunc { return fns }
@@ -783,7 +1160,7 @@ func TestStart(t *testing.T) {
 				tt.mock(r)
 			}
 
-			c := &Cron{r, nil, nil, nil, nil}
+			c := &Cron{runner: r}
 
 			// Act
 			c.Start()
@@ -840,7 +1217,7 @@ func TestStop(t *testing.T) {
 				tt.mock(r, s)
 			}
 
-			c := &Cron{r, s, nil, nil, nil}
+			c := &Cron{runner: r, sync: s}
 
 			// Act
 			c.Stop()
@@ -914,6 +1291,7 @@ func TestLoadConfig(t *testing.T) {
 	tests := []struct {
 		name     string
 		filename string
+		format   string
 		config   string
 		mock     mockFunc
 		checks   []checkFunc
@@ -931,7 +1309,7 @@ func TestLoadConfig(t *testing.T) {
 						}
 					]`,
 			mock: func(r *MockRunner, c *Cron) {
-				r.EXPECT().AddJob("0/2 * * 12 *", &Job{"0/2 * * 12 *", "echo", []string{"boby"}, c})
+				r.EXPECT().AddJob("0/2 * * 12 *", &Job{Schedule: "0/2 * * 12 *", Command: "echo", Args: []string{"boby"}, cron: c, guard: newGuard("")})
 			},
 			checks: check(
 				hasNilError(),
@@ -958,8 +1336,8 @@ func TestLoadConfig(t *testing.T) {
 						}
 					]`,
 			mock: func(r *MockRunner, c *Cron) {
-				r.EXPECT().AddJob("0/2 * * 12 *", &Job{"0/2 * * 12 *", "command1", []string{"arg1"}, c})
-				r.EXPECT().AddJob("5 5 * * *", &Job{"5 5 * * *", "command2", []string{"arg2"}, c})
+				r.EXPECT().AddJob("0/2 * * 12 *", &Job{Schedule: "0/2 * * 12 *", Command: "command1", Args: []string{"arg1"}, cron: c, guard: newGuard("")})
+				r.EXPECT().AddJob("5 5 * * *", &Job{Schedule: "5 5 * * *", Command: "command2", Args: []string{"arg2"}, cron: c, guard: newGuard("")})
 			},
 			checks: check(
 				hasNilError(),
@@ -986,6 +1364,75 @@ func TestLoadConfig(t *testing.T) {
 				hasError("failed to parse JSON data from config file"),
 			),
 		},
+		{
+			name:     "yaml file",
+			filename: "/configs/config.yml",
+			config: `
+concurrency: skip
+jobs:
+  - schedule: "0/2 * * 12 *"
+    command: echo
+    args: [boby]
+`,
+			mock: func(r *MockRunner, c *Cron) {
+				r.EXPECT().AddJob("0/2 * * 12 *", &Job{Schedule: "0/2 * * 12 *", Command: "echo", Args: []string{"boby"}, Concurrency: "skip", cron: c, guard: newGuard("skip")})
+			},
+			checks: check(
+				hasNilError(),
+			),
+		},
+		{
+			name:     "toml file",
+			filename: "/configs/config.toml",
+			config: `
+timeout = "5m"
+
+[[jobs]]
+schedule = "0/2 * * 12 *"
+command = "echo"
+args = ["boby"]
+`,
+			mock: func(r *MockRunner, c *Cron) {
+				r.EXPECT().AddJob("0/2 * * 12 *", &Job{Schedule: "0/2 * * 12 *", Command: "echo", Args: []string{"boby"}, Timeout: "5m", cron: c, guard: newGuard("")})
+			},
+			checks: check(
+				hasNilError(),
+			),
+		},
+		{
+			name:     "format forced",
+			filename: "/configs/config",
+			format:   "yaml",
+			config: `
+- schedule: "0/2 * * 12 *"
+  command: echo
+`,
+			mock: func(r *MockRunner, c *Cron) {
+				r.EXPECT().AddJob("0/2 * * 12 *", &Job{Schedule: "0/2 * * 12 *", Command: "echo", cron: c, guard: newGuard("")})
+			},
+			checks: check(
+				hasNilError(),
+			),
+		},
+		{
+			name:     "crontab file",
+			filename: "/etc/crontab",
+			config:   "# m h dom mon dow user command\nNAME=bob\n0 2 * * * root echo hello $NAME\n",
+			mock: func(r *MockRunner, c *Cron) {
+				r.EXPECT().AddJob("0 2 * * *", &Job{Schedule: "0 2 * * *", Command: "/bin/sh", Args: []string{"-c", "echo hello $NAME"}, Env: map[string]string{"NAME": "bob"}, User: "root", raw: true, cron: c, guard: newGuard("")})
+			},
+			checks: check(
+				hasNilError(),
+			),
+		},
+		{
+			name:     "yaml not valid",
+			filename: "/configs/config.yaml",
+			config:   "jobs: [",
+			checks: check(
+				hasError("failed to parse YAML data from config file"),
+			),
+		},
 		{
 			name:     "invalid job",
 			filename: "/configs/config.json",
@@ -1019,7 +1466,7 @@ func TestLoadConfig(t *testing.T) {
 			defer ctrl.Finish()
 			r := NewMockRunner(ctrl)
 
-			c := &Cron{r, nil, fs, nil, nil}
+			c := &Cron{runner: r, fs: fs, format: tt.format}
 			if tt.mock != nil {
 				tt.mock(r, c)
 			}
@@ -1034,3 +1481,535 @@ func TestLoadConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadConfigReload(t *testing.T) {
+	type checkFunc func(*testing.T, *Cron, string, error)
+	check := func(fns ...checkFunc) []checkFunc { return fns }
+
+	type mockFunc func(*MockRunner, *Cron)
+
+	hasError := func(want string) checkFunc {
+		return func(t *testing.T, c *Cron, out string, err error) {
+			assert.Assert(t, is.ErrorContains(err, want))
+		}
+	}
+
+	hasNilError := func() checkFunc {
+		return func(t *testing.T, c *Cron, out string, err error) {
+			assert.NilError(t, err)
+		}
+	}
+
+	hasLogField := func(field string, want string) checkFunc {
+		return func(t *testing.T, c *Cron, out string, err error) {
+			assert.Assert(t, is.Contains(out, fmt.Sprintf("\"%s\":\"%s\"", field, want)))
+		}
+	}
+
+	hasEntries := func(want map[string]cron.EntryID) checkFunc {
+		return func(t *testing.T, c *Cron, out string, err error) {
+			assert.Assert(t, is.DeepEqual(c.fEntries, want))
+		}
+	}
+
+	job1 := &Job{Schedule: "1 * * * *", Command: "echo1"}
+	job2 := &Job{Schedule: "2 * * * *", Command: "echo2"}
+	job3 := &Job{Schedule: "3 * * * *", Command: "echo3"}
+
+	tests := []struct {
+		name     string
+		config   string
+		fEntries map[string]cron.EntryID
+		mock     mockFunc
+		checks   []checkFunc
+	}{
+		{
+			name: "add and remove changed jobs only",
+			config: `[
+						{"schedule": "1 * * * *", "command": "echo1"},
+						{"schedule": "3 * * * *", "command": "echo3"}
+					]`,
+			fEntries: map[string]cron.EntryID{job1.key(): 1, job2.key(): 2},
+			mock: func(r *MockRunner, c *Cron) {
+				r.EXPECT().AddJob("3 * * * *", gomock.Any()).Return(cron.EntryID(3), nil)
+				r.EXPECT().Remove(cron.EntryID(2))
+			},
+			checks: check(
+				hasNilError(),
+				hasEntries(map[string]cron.EntryID{job1.key(): 1, job3.key(): 3}),
+				hasLogField("msg", "remove job from cron"),
+			),
+		},
+		{
+			name: "duplicate jobs",
+			config: `[
+						{"schedule": "1 * * * *", "command": "echo1"},
+						{"schedule": "1 * * * *", "command": "echo1"}
+					]`,
+			fEntries: map[string]cron.EntryID{job1.key(): 1},
+			mock: func(r *MockRunner, c *Cron) {
+				r.EXPECT().AddJob("1 * * * *", gomock.Any()).Return(cron.EntryID(2), nil)
+			},
+			checks: check(
+				hasNilError(),
+				hasEntries(map[string]cron.EntryID{job1.key(): 1, job1.key() + "#1": 2}),
+			),
+		},
+		{
+			name: "invalid job keep previous jobs",
+			config: `[
+						{"schedule": "3 * * * *", "command": "echo3"},
+						{"schedule": "bad", "command": "echo4"}
+					]`,
+			fEntries: map[string]cron.EntryID{job1.key(): 1, job2.key(): 2},
+			mock: func(r *MockRunner, c *Cron) {
+				r.EXPECT().AddJob("3 * * * *", gomock.Any()).Return(cron.EntryID(3), nil)
+				r.EXPECT().AddJob("bad", gomock.Any()).Return(cron.EntryID(0), errors.New("bad schedule"))
+				r.EXPECT().Remove(cron.EntryID(3))
+			},
+			checks: check(
+				hasError("bad schedule"),
+				hasError("failed to add jobs fron config file"),
+				hasEntries(map[string]cron.EntryID{job1.key(): 1, job2.key(): 2}),
+			),
+		},
+		{
+			name:     "null config",
+			config:   `null`,
+			fEntries: map[string]cron.EntryID{job1.key(): 1},
+			checks: check(
+				hasError("jobs is required"),
+				hasEntries(map[string]cron.EntryID{job1.key(): 1}),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			out := &bytes.Buffer{}
+			log.SetOutput(out)
+
+			fs := afero.NewMemMapFs()
+			afero.WriteFile(fs, "/configs/config.json", []byte(tt.config), 0640)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			r := NewMockRunner(ctrl)
+
+			c := &Cron{runner: r, fs: fs, fEntries: tt.fEntries}
+			if tt.mock != nil {
+				tt.mock(r, c)
+			}
+
+			// Act
+			err := c.LoadConfig("/configs/config.json")
+
+			// Assert
+			for _, check := range tt.checks {
+				check(t, c, out.String(), err)
+			}
+		})
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	type checkFunc func(*testing.T, *Cron, string)
+	check := func(fns ...checkFunc) []checkFunc { return fns }
+
+	type mockFunc func(*MockRunner)
+
+	hasLogField := func(field string, want string) checkFunc {
+		return func(t *testing.T, c *Cron, out string) {
+			assert.Assert(t, is.Contains(out, fmt.Sprintf("\"%s\":\"%s\"", field, want)))
+		}
+	}
+
+	hasNotLogField := func(field string, want string) checkFunc {
+		return func(t *testing.T, c *Cron, out string) {
+			assert.Assert(t, !strings.Contains(out, fmt.Sprintf("\"%s\":\"%s\"", field, want)), "actual: %s", out)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		config string
+		change string
+		mock   mockFunc
+		checks []checkFunc
+	}{
+		{
+			name:   "config unchanged",
+			config: `[{"schedule": "1 * * * *", "command": "echo1"}]`,
+			checks: check(
+				hasLogField("msg", "watch config file for changes"),
+				hasNotLogField("msg", "config file changed"),
+			),
+		},
+		{
+			name:   "config changed",
+			config: `[{"schedule": "1 * * * *", "command": "echo1"}]`,
+			change: `[{"schedule": "2 * * * *", "command": "echo2"}]`,
+			mock: func(r *MockRunner) {
+				r.EXPECT().AddJob("2 * * * *", gomock.Any()).Return(cron.EntryID(2), nil)
+			},
+			checks: check(
+				hasLogField("msg", "config file changed"),
+				hasLogField("msg", "load config file"),
+				hasNotLogField("msg", "config file rejected, previous jobs are kept"),
+			),
+		},
+		{
+			name:   "config changed in error",
+			config: `[{"schedule": "1 * * * *", "command": "echo1"}]`,
+			change: `[{error}]`,
+			checks: check(
+				hasLogField("msg", "config file changed"),
+				hasLogField("level", "error"),
+				hasLogField("msg", "config file rejected, previous jobs are kept"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			out := &bytes.Buffer{}
+			log.SetOutput(out)
+			log.SetFormatter(&log.JSONFormatter{})
+
+			fs := afero.NewMemMapFs()
+			afero.WriteFile(fs, "/configs/config.json", []byte(tt.config), 0640)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			r := NewMockRunner(ctrl)
+			if tt.mock != nil {
+				tt.mock(r)
+			}
+
+			c := &Cron{runner: r, fs: fs}
+
+			// Act
+			stop := make(chan struct{})
+			done := c.watchConfig("/configs/config.json", time.Millisecond, stop)
+			if tt.change != "" {
+				afero.WriteFile(fs, "/configs/config.json", []byte(tt.change), 0640)
+			}
+			time.Sleep(20 * time.Millisecond)
+			close(stop)
+			<-done
+
+			// Assert
+			for _, check := range tt.checks {
+				check(t, c, out.String())
+			}
+		})
+	}
+}
+
+func TestJobs(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	r := NewMockRunner(ctrl)
+
+	next := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+	prev := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	paused := newGuard("")
+	paused.setPaused(true)
+
+	r.EXPECT().Entries().Return([]cron.Entry{
+		{ID: 1, Next: next, Prev: prev, Job: &Job{Schedule: "1 * * * *", Command: "echo", Args: []string{"1"}}},
+		{ID: 2, Job: &ContainerJob{Schedule: "2 * * * *", Action: "start", Container: types.Container{ID: "c1", Names: []string{"/name1"}}, guard: paused}},
+		{ID: 3, Job: &ServiceJob{Schedule: "3 * * * *", Action: "update", ServiceName: "s1"}},
+		{ID: 4, Job: cron.FuncJob(func() {})},
+	})
+
+	c := &Cron{runner: r}
+
+	// Act
+	jobs := c.Jobs()
+
+	// Assert
+	assert.Assert(t, is.DeepEqual(jobs, []JobInfo{
+		{ID: 1, Name: "echo 1", Source: "file", Schedule: "1 * * * *", Command: "echo", Args: []string{"1"}, Next: next, Prev: prev},
+		{ID: 2, Name: "name1-start", Source: "container", Schedule: "2 * * * *", Action: "start", Container: "name1", Paused: true},
+		{ID: 3, Name: "s1-update", Source: "service", Schedule: "3 * * * *", Action: "update", Service: "s1"},
+	}))
+}
+
+func TestJob(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	r := NewMockRunner(ctrl)
+
+	r.EXPECT().Entry(cron.EntryID(1)).Return(cron.Entry{ID: 1, Job: &Job{Schedule: "1 * * * *", Command: "echo"}})
+	r.EXPECT().Entry(cron.EntryID(2)).Return(cron.Entry{})
+
+	c := &Cron{runner: r}
+
+	// Act
+	job, err := c.Job(1)
+
+	// Assert
+	assert.NilError(t, err)
+	assert.Assert(t, is.DeepEqual(job, JobInfo{ID: 1, Name: "echo", Source: "file", Schedule: "1 * * * *", Command: "echo"}))
+
+	// Act
+	_, err = c.Job(2)
+
+	// Assert
+	assert.Assert(t, is.Equal(err, ErrJobNotFound))
+}
+
+func TestFindJob(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	r := NewMockRunner(ctrl)
+
+	entries := []cron.Entry{
+		{ID: 1, Job: &Job{Schedule: "1 * * * *", Command: "backup.sh"}},
+		{ID: 2, Job: &Job{Schedule: "2 * * * *", Command: "clean.sh"}},
+		{ID: 3, Job: &Job{Schedule: "3 * * * *", Command: "purge.sh"}},
+	}
+	r.EXPECT().Entry(cron.EntryID(2)).Return(entries[1])
+	r.EXPECT().Entry(cron.EntryID(7)).Return(cron.Entry{})
+	r.EXPECT().Entries().Return(entries).Times(4)
+
+	c := &Cron{runner: r}
+
+	tests := []struct {
+		name string
+		want int
+		err  error
+	}{
+		{name: "2", want: 2},
+		{name: "backup.sh", want: 1},
+		{name: "purge.sh", want: 3},
+		{name: "7", err: ErrJobNotFound},
+		{name: "unknown", err: ErrJobNotFound},
+	}
+
+	for _, tt := range tests {
+		// Act
+		job, err := c.FindJob(tt.name)
+
+		// Assert
+		if tt.err != nil {
+			assert.Assert(t, is.Equal(err, tt.err), tt.name)
+		} else {
+			assert.NilError(t, err)
+			assert.Equal(t, job.ID, tt.want)
+		}
+	}
+}
+
+func TestRunJob(t *testing.T) {
+	// Arrange
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+	log.SetFormatter(&log.JSONFormatter{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	r := NewMockRunner(ctrl)
+	s := NewMockJobSynchroniser(ctrl)
+	c := &Cron{runner: r, sync: s}
+
+	paused := newGuard("")
+	paused.setPaused(true)
+	r.EXPECT().Entry(cron.EntryID(1)).Return(cron.Entry{ID: 1, Job: &Job{Schedule: "1 * * * *", Command: "sh", Args: []string{"-c", "echo run; exit 3"}, cron: c}})
+	r.EXPECT().Entry(cron.EntryID(2)).Return(cron.Entry{ID: 2, Job: &Job{Schedule: "2 * * * *", Command: "echo", guard: paused, cron: c}})
+	r.EXPECT().Entry(cron.EntryID(3)).Return(cron.Entry{})
+	s.EXPECT().Add(1)
+	s.EXPECT().Done()
+
+	// Act
+	stdout := &bytes.Buffer{}
+	err := c.RunJob(1, stdout)
+
+	// Assert
+	assert.Equal(t, ExitCode(err), 3)
+	assert.Equal(t, stdout.String(), "run\n")
+	assert.Assert(t, is.Contains(out.String(), `"msg":"run job"`))
+	assert.Assert(t, is.Contains(out.String(), `"name":"sh -c echo run; exit 3"`))
+
+	// Act
+	err = c.RunJob(2, stdout)
+
+	// Assert
+	assert.Assert(t, is.Equal(err, ErrJobPaused))
+
+	// Act
+	err = c.RunJob(3, stdout)
+
+	// Assert
+	assert.Assert(t, is.Equal(err, ErrJobNotFound))
+}
+
+func TestTriggerJob(t *testing.T) {
+	type checkFunc func(*testing.T, string, error)
+	check := func(fns ...checkFunc) []checkFunc { return fns }
+
+	type mockFunc func(*MockRunner, *MockJobSynchroniser, *Cron, chan struct{})
+
+	hasError := func(want error) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.Assert(t, is.Equal(err, want))
+		}
+	}
+
+	hasNilError := func() checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.NilError(t, err)
+		}
+	}
+
+	hasLogField := func(field string, want string) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.Assert(t, is.Contains(out, fmt.Sprintf("\"%s\":\"%s\"", field, want)))
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mock   mockFunc
+		checks []checkFunc
+	}{
+		{
+			name: "trigger job",
+			mock: func(r *MockRunner, s *MockJobSynchroniser, c *Cron, done chan struct{}) {
+				j := &Job{Schedule: "1 * * * *", Command: "echo", Args: []string{"triggered"}, cron: c}
+				r.EXPECT().Entry(cron.EntryID(1)).Return(cron.Entry{ID: 1, Job: j})
+				s.EXPECT().Add(1)
+				s.EXPECT().Done().Do(func() { close(done) })
+			},
+			checks: check(
+				hasNilError(),
+				hasLogField("msg", "trigger job"),
+				hasLogField("output", "triggered\\n"),
+			),
+		},
+		{
+			name: "job not found",
+			mock: func(r *MockRunner, s *MockJobSynchroniser, c *Cron, done chan struct{}) {
+				r.EXPECT().Entry(cron.EntryID(1)).Return(cron.Entry{})
+			},
+			checks: check(
+				hasError(ErrJobNotFound),
+			),
+		},
+		{
+			name: "job paused",
+			mock: func(r *MockRunner, s *MockJobSynchroniser, c *Cron, done chan struct{}) {
+				g := newGuard("")
+				g.setPaused(true)
+				j := &Job{Schedule: "1 * * * *", Command: "echo", cron: c, guard: g}
+				r.EXPECT().Entry(cron.EntryID(1)).Return(cron.Entry{ID: 1, Job: j})
+			},
+			checks: check(
+				hasError(ErrJobPaused),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			out := &bytes.Buffer{}
+			log.SetOutput(out)
+			log.SetFormatter(&log.JSONFormatter{})
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			r := NewMockRunner(ctrl)
+			s := NewMockJobSynchroniser(ctrl)
+
+			c := &Cron{runner: r, sync: s}
+			done := make(chan struct{})
+			if tt.mock != nil {
+				tt.mock(r, s, c, done)
+			}
+
+			// Act
+			err := c.TriggerJob(1)
+			if err == nil {
+				<-done
+			}
+
+			// Assert
+			for _, check := range tt.checks {
+				check(t, out.String(), err)
+			}
+		})
+	}
+}
+
+func TestPauseResumeJob(t *testing.T) {
+	// Arrange
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	r := NewMockRunner(ctrl)
+
+	g := newGuard("")
+	entry := cron.Entry{ID: 1, Job: &ServiceJob{Schedule: "1 * * * *", Action: "update", guard: g}}
+	r.EXPECT().Entry(cron.EntryID(1)).Return(entry).Times(2)
+	r.EXPECT().Entry(cron.EntryID(2)).Return(cron.Entry{})
+
+	c := &Cron{runner: r}
+
+	// Act
+	err := c.PauseJob(1)
+
+	// Assert
+	assert.NilError(t, err)
+	assert.Assert(t, g.isPaused())
+	assert.Assert(t, is.Contains(out.String(), "pause job"))
+
+	// Act
+	err = c.ResumeJob(1)
+
+	// Assert
+	assert.NilError(t, err)
+	assert.Assert(t, !g.isPaused())
+	assert.Assert(t, is.Contains(out.String(), "resume job"))
+
+	// Act
+	err = c.PauseJob(2)
+
+	// Assert
+	assert.Assert(t, is.Equal(err, ErrJobNotFound))
+}
+
+func TestPauseJobUpdatedService(t *testing.T) {
+	// Arrange
+	c := NewCron(false)
+	job := ServiceJob{Schedule: "@daily", Action: "update", ServiceID: "S1", ServiceName: "web", ServiceVersion: swarm.Version{Index: 1}}
+	assert.NilError(t, c.AddServiceJob(job))
+	assert.NilError(t, c.PauseJob(1))
+
+	// Act
+	c.RemoveServiceJob("S1")
+	job.ServiceVersion = swarm.Version{Index: 2}
+	errPaused := c.AddServiceJob(job)
+	paused := c.Jobs()
+
+	resumeErr := c.ResumeJob(paused[0].ID)
+	c.RemoveServiceJob("S1")
+	job.ServiceVersion = swarm.Version{Index: 3}
+	errResumed := c.AddServiceJob(job)
+
+	// Assert
+	assert.NilError(t, errPaused)
+	assert.NilError(t, resumeErr)
+	assert.NilError(t, errResumed)
+	assert.Equal(t, paused[0].Name, "web-update")
+	assert.Assert(t, paused[0].Paused)
+	assert.Assert(t, !c.Jobs()[0].Paused)
+}
@@ -0,0 +1,160 @@
+package cron
+
+import (
+	"bufio"
+	"bytes"
+	"os/user"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// defaultShell run the command line of a crontab job when SHELL is not set.
+const defaultShell = "/bin/sh"
+
+var envLine = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+
+// lookupUser find a user by name, it is replaced in tests.
+var lookupUser = user.Lookup
+
+// parseCrontab read jobs from data in classic crontab format. Each line is
+// either a comment, an environment variable assignment applied to the jobs
+// below it or a job with a schedule, a user when userColumn is set, like in
+// /etc/crontab and /etc/cron.d, and a command line run by the shell. MAILTO
+// set the mail addresses of the jobs below it, an empty MAILTO disable their
+// mails.
+func parseCrontab(data []byte, parseSecond bool, userColumn bool) ([]Job, error) {
+	fields := 5
+	if parseSecond {
+		fields = 6
+	}
+
+	jobs := []Job{}
+	env := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if m := envLine.FindStringSubmatch(line); m != nil {
+			env[m[1]] = unquote(m[2])
+			continue
+		}
+
+		job, err := parseCrontabLine(line, fields, userColumn, env)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse crontab data from config file at line %d", n)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to parse crontab data from config file")
+	}
+	return jobs, nil
+}
+
+func parseCrontabLine(line string, fields int, userColumn bool, env map[string]string) (Job, error) {
+	if strings.HasPrefix(line, "@") {
+		fields = 1
+		if strings.HasPrefix(line, "@reboot") {
+			return Job{}, errors.New("@reboot schedule is not supported")
+		}
+	}
+
+	schedule, rest := cutFields(line, fields)
+	if rest == "" {
+		return Job{}, errors.New("schedule and command are required")
+	}
+
+	job := Job{raw: true}
+
+	if userColumn {
+		name, command := cutFields(rest, 1)
+		if command == "" {
+			return Job{}, errors.New("schedule, user and command are required")
+		}
+		job.User = name
+		rest = command
+	}
+
+	if tz, ok := env["CRON_TZ"]; ok {
+		schedule = "CRON_TZ=" + tz + " " + schedule
+	}
+	job.Schedule = schedule
+
+	command, stdin := splitPercent(rest)
+	job.Command = defaultShell
+	if shell, ok := env["SHELL"]; ok && shell != "" {
+		job.Command = shell
+	}
+	job.Args = []string{"-c", command}
+	job.Stdin = stdin
+	if to, ok := env["MAILTO"]; ok {
+		job.MailTo = to
+		if to == "" {
+			job.MailOn = MailNever
+		}
+	}
+
+	if len(env) > 0 {
+		job.Env = make(map[string]string, len(env))
+		for k, v := range env {
+			job.Env[k] = v
+		}
+	}
+	return job, nil
+}
+
+// cutFields split the first n whitespace separated fields of s from the rest.
+func cutFields(s string, n int) (string, string) {
+	i := 0
+	for f := 0; f < n; f++ {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		if i == len(s) {
+			return "", ""
+		}
+		for i < len(s) && s[i] != ' ' && s[i] != '\t' {
+			i++
+		}
+	}
+	return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i:])
+}
+
+// splitPercent apply the crontab '%' semantics to a command line. The first
+// unescaped '%' ends the command, the rest is sent to its standard input with
+// any other '%' replaced by a newline. A '\%' is a literal '%'.
+func splitPercent(s string) (command string, stdin string) {
+	var b strings.Builder
+	inStdin := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == '%':
+			b.WriteByte('%')
+			i++
+		case s[i] == '%' && !inStdin:
+			command = b.String()
+			b.Reset()
+			inStdin = true
+		case s[i] == '%':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	if !inStdin {
+		return b.String(), ""
+	}
+	return command, b.String() + "\n"
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
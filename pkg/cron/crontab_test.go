@@ -0,0 +1,157 @@
+package cron
+
+import (
+	"testing"
+
+	gocmp "github.com/google/go-cmp/cmp"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestParseCrontab(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		parseSecond bool
+		userColumn  bool
+		want        []Job
+		err         string
+	}{
+		{
+			name: "comments and empty lines",
+			data: "# comment\n\n   # indented comment\n",
+			want: []Job{},
+		},
+		{
+			name: "five fields",
+			data: "*/5 * * * *   echo  hello   world\n",
+			want: []Job{
+				{Schedule: "*/5 * * * *", Command: "/bin/sh", Args: []string{"-c", "echo  hello   world"}, raw: true},
+			},
+		},
+		{
+			name:        "six fields with parse second",
+			data:        "0 */5 * * * * echo hello",
+			parseSecond: true,
+			want: []Job{
+				{Schedule: "0 */5 * * * *", Command: "/bin/sh", Args: []string{"-c", "echo hello"}, raw: true},
+			},
+		},
+		{
+			name: "descriptor",
+			data: "@daily\techo hello",
+			want: []Job{
+				{Schedule: "@daily", Command: "/bin/sh", Args: []string{"-c", "echo hello"}, raw: true},
+			},
+		},
+		{
+			name:       "user column",
+			data:       "0 1 * * * root echo hello\n@hourly mail  echo bye",
+			userColumn: true,
+			want: []Job{
+				{Schedule: "0 1 * * *", Command: "/bin/sh", Args: []string{"-c", "echo hello"}, User: "root", raw: true},
+				{Schedule: "@hourly", Command: "/bin/sh", Args: []string{"-c", "echo bye"}, User: "mail", raw: true},
+			},
+		},
+		{
+			name: "no user column",
+			data: "0 1 * * * root echo hello\n0 1 * * * mail -s report ops",
+			want: []Job{
+				{Schedule: "0 1 * * *", Command: "/bin/sh", Args: []string{"-c", "root echo hello"}, raw: true},
+				{Schedule: "0 1 * * *", Command: "/bin/sh", Args: []string{"-c", "mail -s report ops"}, raw: true},
+			},
+		},
+		{
+			name: "environment variables",
+			data: "0 1 * * * echo 1\nNAME = \"bob\"\nSHELL=/bin/bash\n0 2 * * * echo 2\nNAME='alice'\nCRON_TZ=America/Montreal\n0 3 * * * echo 3\n",
+			want: []Job{
+				{Schedule: "0 1 * * *", Command: "/bin/sh", Args: []string{"-c", "echo 1"}, raw: true},
+				{Schedule: "0 2 * * *", Command: "/bin/bash", Args: []string{"-c", "echo 2"}, Env: map[string]string{"NAME": "bob", "SHELL": "/bin/bash"}, raw: true},
+				{Schedule: "CRON_TZ=America/Montreal 0 3 * * *", Command: "/bin/bash", Args: []string{"-c", "echo 3"}, Env: map[string]string{"NAME": "alice", "SHELL": "/bin/bash", "CRON_TZ": "America/Montreal"}, raw: true},
+			},
+		},
+		{
+			name: "mailto",
+			data: "MAILTO=ops@example.com\n0 1 * * * echo 1\n",
+			want: []Job{
+				{Schedule: "0 1 * * *", Command: "/bin/sh", Args: []string{"-c", "echo 1"}, Env: map[string]string{"MAILTO": "ops@example.com"}, MailTo: "ops@example.com", raw: true},
+			},
+		},
+		{
+			name: "empty mailto",
+			data: "MAILTO=\"\"\n0 1 * * * echo 1\n",
+			want: []Job{
+				{Schedule: "0 1 * * *", Command: "/bin/sh", Args: []string{"-c", "echo 1"}, Env: map[string]string{"MAILTO": ""}, MailOn: MailNever, raw: true},
+			},
+		},
+		{
+			name: "percent as standard input",
+			data: `0 1 * * * mail -s "100\% done" bob%Hello%Bye`,
+			want: []Job{
+				{Schedule: "0 1 * * *", Command: "/bin/sh", Args: []string{"-c", `mail -s "100% done" bob`}, Stdin: "Hello\nBye\n", raw: true},
+			},
+		},
+		{
+			name: "missing command",
+			data: "# jobs\n0 1 * * *",
+			err:  "failed to parse crontab data from config file at line 2: schedule and command are required",
+		},
+		{
+			name:        "missing command with parse second",
+			data:        "0 1 * * * echo",
+			parseSecond: true,
+			err:         "schedule and command are required",
+		},
+		{
+			name:       "missing command with user column",
+			data:       "0 1 * * * root",
+			userColumn: true,
+			err:        "schedule, user and command are required",
+		},
+		{
+			name: "reboot not supported",
+			data: "@reboot echo hello",
+			err:  "@reboot schedule is not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := parseCrontab([]byte(tt.data), tt.parseSecond, tt.userColumn)
+
+			// Assert
+			if tt.err != "" {
+				assert.Assert(t, is.ErrorContains(err, tt.err))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Assert(t, is.DeepEqual(got, tt.want, gocmp.AllowUnexported(Job{})))
+		})
+	}
+}
+
+func TestSplitPercent(t *testing.T) {
+	tests := []struct {
+		line    string
+		command string
+		stdin   string
+	}{
+		{line: "echo hello", command: "echo hello"},
+		{line: "cat%", command: "cat", stdin: "\n"},
+		{line: "cat%a%b", command: "cat", stdin: "a\nb\n"},
+		{line: `date +\%Y%a\%b`, command: "date +%Y", stdin: "a%b\n"},
+		{line: `echo \`, command: `echo \`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			// Act
+			command, stdin := splitPercent(tt.line)
+
+			// Assert
+			assert.Equal(t, command, tt.command)
+			assert.Equal(t, stdin, tt.stdin)
+		})
+	}
+}
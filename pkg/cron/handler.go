@@ -2,12 +2,15 @@ package cron
 
 import (
 	context "context"
+	"slices"
+	"time"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/swarm"
 	"github.com/docker/docker/client"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -36,12 +39,9 @@ func (h *Handler) ScanContainer() error {
 	})
 	log.Infoln("scan containers for cron schedule")
 
-	f := filters.NewArgs()
-	f.Add("label", "mobycron.schedule")
-
 	defer h.cli.Close()
 
-	err := h.addContainers(f)
+	err := h.addContainers(filters.NewArgs())
 	if err != nil {
 		return err
 	}
@@ -67,13 +67,65 @@ func (h *Handler) ScanService() error {
 	return nil
 }
 
+// ValidateContainers validate the jobs of current containers, without adding
+// them to cron.
+func (h *Handler) ValidateContainers() ([]Validation, error) {
+	defer h.cli.Close()
+
+	containers, err := h.cli.ContainerList(context.Background(), container.ListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	validations := []Validation{}
+	for _, container := range containers {
+		if createdByRun(container) {
+			continue
+		}
+		for _, l := range labelJobs(container.Labels) {
+			j, err := h.containerJob(container, l)
+			if _, ok := container.Labels["com.docker.swarm.task.name"]; ok {
+				err = errors.New("mobycron label must be set on service, not directly on the container")
+			}
+			validations = append(validations, h.cron.ValidateContainerJob(j, err))
+		}
+	}
+	return validations, nil
+}
+
+// ValidateServices validate the jobs of current services, without adding them
+// to cron.
+func (h *Handler) ValidateServices() ([]Validation, error) {
+	f := filters.NewArgs()
+	f.Add("label", "mobycron.schedule")
+
+	defer h.cli.Close()
+
+	services, err := h.cli.ServiceList(context.Background(), swarm.ServiceListOptions{Filters: f})
+	if err != nil {
+		return nil, err
+	}
+
+	validations := []Validation{}
+	for _, service := range services {
+		if _, ok := service.Spec.Labels["mobycron.schedule"]; !ok {
+			continue
+		}
+		jobs, err := h.serviceJobs(service)
+		for _, j := range jobs {
+			validations = append(validations, h.cron.ValidateServiceJob(j, err))
+		}
+	}
+	return validations, nil
+}
+
 // ListenContainer listen docker message for containers with cron schedule
 func (h *Handler) ListenContainer() {
 	filterArgs := filters.NewArgs()
-	filterArgs.Add("label", "mobycron.schedule")
 	filterArgs.Add("type", "container")
 	filterArgs.Add("event", "create")
 	filterArgs.Add("event", "destroy")
+	filterArgs.Add("event", "rename")
 
 	eventOptions := events.ListOptions{Filters: filterArgs}
 
@@ -96,6 +148,7 @@ func (h *Handler) ListenContainer() {
 						"Scope":    event.Scope,
 					})
 					log.Infoln("event message from server")
+					dockerEvents.WithLabelValues(string(event.Type), string(event.Action)).Inc()
 
 					if event.Action == "create" {
 						f := filters.NewArgs()
@@ -109,6 +162,9 @@ func (h *Handler) ListenContainer() {
 					if event.Action == "destroy" {
 						h.cron.RemoveContainerJob(event.Actor.ID)
 					}
+					if event.Action == "rename" {
+						h.cron.RenameContainerJob(event.Actor.ID, event.Actor.Attributes["name"])
+					}
 
 				case err := <-errChan:
 					log.WithFields(log.Fields{
@@ -152,6 +208,7 @@ func (h *Handler) ListenService() {
 						"Scope":    event.Scope,
 					})
 					log.Infoln("event message from server")
+					dockerEvents.WithLabelValues(string(event.Type), string(event.Action)).Inc()
 
 					if event.Action == "create" {
 						f := filters.NewArgs()
@@ -190,37 +247,204 @@ func (h *Handler) ListenService() {
 	go listen()
 }
 
-func (h *Handler) addContainers(filters filters.Args) error {
+// ReconcileContainer compare the labeled containers with the jobs of cron every
+// interval, so the jobs missed by lost events are added or removed.
+func (h *Handler) ReconcileContainer(interval time.Duration) {
+	reconcile := func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := h.reconcileContainers(); err != nil {
+				log.WithFields(log.Fields{
+					"func": "Handler.ReconcileContainer",
+				}).WithError(err).Errorln("reconcile containers is in error")
+			}
+		}
+	}
+	go reconcile()
+}
+
+// ReconcileService compare the labeled services with the jobs of cron every
+// interval, so the jobs missed by lost events are added, updated or removed.
+func (h *Handler) ReconcileService(interval time.Duration) {
+	reconcile := func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := h.reconcileServices(); err != nil {
+				log.WithFields(log.Fields{
+					"func": "Handler.ReconcileService",
+				}).WithError(err).Errorln("reconcile services is in error")
+			}
+		}
+	}
+	go reconcile()
+}
+
+// reconcileContainers add the jobs of the containers missing from cron, by
+// container and label name, and remove the jobs of the containers that no
+// longer exist. An invalid job is not missing.
+func (h *Handler) reconcileContainers() error {
 	log := log.WithFields(log.Fields{
-		"func": "Handler.addContainers"})
-	log.Infoln("add containers from filters")
+		"func": "Handler.reconcileContainers",
+	})
 
-	containers, err := h.cli.ContainerList(context.Background(), container.ListOptions{All: true, Filters: filters})
+	defer h.cli.Close()
+
+	containers, err := h.cli.ContainerList(context.Background(), container.ListOptions{All: true})
 	if err != nil {
 		return err
 	}
 
+	added := h.cron.Containers()
 	for _, container := range containers {
-		if _, ok := container.Labels["com.docker.swarm.task.name"]; ok {
-			log.Errorln("mobycron label must be set on service, not directly on the container")
+		labels := added[container.ID]
+		delete(added, container.ID)
+
+		missing := h.missingJobs(container, labels)
+		if len(missing) == 0 {
+			continue
+		}
+		log := log.WithField("container.ID", container.ID)
+		log.WithField("labels", len(labels)).Warnln("container jobs missing from cron, add them")
+		for _, j := range missing {
+			if err := h.cron.AddContainerJob(j); err != nil {
+				log.WithError(err).WithField("name", j.Label).Errorln("add container job to cron is in error")
+			}
+		}
+	}
+
+	for ID := range added {
+		log.WithField("container.ID", ID).Warnln("container no longer exist, remove its jobs")
+		h.cron.RemoveContainerJob(ID)
+	}
+	return nil
+}
+
+// reconcileServices add the jobs of the services missing from cron, add again
+// the jobs of the services updated since their jobs were added and remove the
+// jobs of the services that no longer exist. A service with only invalid jobs
+// is not missing.
+func (h *Handler) reconcileServices() error {
+	log := log.WithFields(log.Fields{
+		"func": "Handler.reconcileServices",
+	})
+
+	defer h.cli.Close()
+
+	f := filters.NewArgs()
+	f.Add("label", "mobycron.schedule")
+	services, err := h.cli.ServiceList(context.Background(), swarm.ServiceListOptions{Filters: f})
+	if err != nil {
+		return err
+	}
+
+	added := h.cron.Services()
+	for _, service := range services {
+		log := log.WithField("service.ID", service.ID)
+		version, ok := added[service.ID]
+		delete(added, service.ID)
+
+		switch {
+		case ok && version.Index != service.Version.Index:
+			log.WithField("service.Version", service.Version).Warnln("service jobs outdated in cron, update them")
+			h.cron.RemoveServiceJob(service.ID)
+			h.addService(log, service)
+		case !ok && h.validService(service):
+			log.Warnln("service jobs missing from cron, add them")
+			h.addService(log, service)
+		}
+	}
+
+	for ID := range added {
+		log.WithField("service.ID", ID).Warnln("service no longer exist, remove its jobs")
+		h.cron.RemoveServiceJob(ID)
+	}
+	return nil
+}
+
+// missingJobs return the valid jobs declared in the labels of container that
+// are not in cron, where the container has the jobs with the label names
+// added.
+func (h *Handler) missingJobs(container container.Summary, added []string) []ContainerJob {
+	if _, ok := container.Labels["com.docker.swarm.task.name"]; ok || createdByRun(container) {
+		return nil
+	}
+	jobs := []ContainerJob{}
+	for _, l := range labelJobs(container.Labels) {
+		if slices.Contains(added, l.name) {
 			continue
 		}
-		j := ContainerJob{
-			Schedule:  container.Labels["mobycron.schedule"],
-			Action:    container.Labels["mobycron.action"],
-			Timeout:   container.Labels["mobycron.timeout"],
-			Command:   container.Labels["mobycron.command"],
-			Container: container,
-			cli:       h.cli,
+		j, err := h.containerJob(container, l)
+		if h.cron.ValidateContainerJob(j, err).Err == nil {
+			jobs = append(jobs, j)
 		}
+	}
+	return jobs
+}
 
-		if err := h.cron.AddContainerJob(j); err != nil {
-			log.WithError(err).Errorln("add container job to cron is in error")
+// validService tell if a job declared in the labels of service can be added
+// to cron.
+func (h *Handler) validService(service swarm.Service) bool {
+	jobs, err := h.serviceJobs(service)
+	for _, j := range jobs {
+		if h.cron.ValidateServiceJob(j, err).Err == nil {
+			return true
 		}
 	}
+	return false
+}
+
+func (h *Handler) addContainers(filters filters.Args) error {
+	log := log.WithFields(log.Fields{
+		"func": "Handler.addContainers"})
+	log.Infoln("add containers from filters")
+
+	containers, err := h.cli.ContainerList(context.Background(), container.ListOptions{All: true, Filters: filters})
+	if err != nil {
+		return err
+	}
+
+	for _, container := range containers {
+		h.addContainer(log, container)
+	}
 	return nil
 }
 
+// addContainer add the jobs declared in the labels of container to cron.
+func (h *Handler) addContainer(log *log.Entry, container container.Summary) {
+	jobs := labelJobs(container.Labels)
+	if len(jobs) == 0 {
+		return
+	}
+	if createdByRun(container) {
+		log.WithField("container.ID", container.ID).Infoln("skipped, container created by a run job")
+		return
+	}
+	if _, ok := container.Labels["com.docker.swarm.task.name"]; ok {
+		log.Errorln("mobycron label must be set on service, not directly on the container")
+		return
+	}
+	for _, l := range jobs {
+		j, err := h.containerJob(container, l)
+		if err == nil {
+			err = h.cron.AddContainerJob(j)
+		}
+		if err != nil {
+			log.WithError(err).WithField("name", l.name).Errorln("add container job to cron is in error")
+		}
+	}
+}
+
+// createdByRun tell if container was created by the 'run' action of a job. It
+// inherit the labels of its image, so the jobs declared with labels in the
+// image are not its own jobs.
+func createdByRun(container container.Summary) bool {
+	return container.Labels[runLabel] == "true"
+}
+
 func (h *Handler) addServices(filters filters.Args) error {
 	log := log.WithFields(log.Fields{
 		"func": "Handler.addServices",
@@ -237,20 +461,101 @@ func (h *Handler) addServices(filters filters.Args) error {
 			log.Info("skipped, mobycron label not found")
 			continue
 		}
-		j := ServiceJob{
-			Schedule:         service.Spec.Labels["mobycron.schedule"],
-			Action:           service.Spec.Labels["mobycron.action"],
-			ServiceID:        service.ID,
-			ServiceName:      service.Spec.Name,
-			ServiceVersion:   service.Version,
-			ServiceCreatedAt: service.CreatedAt,
-			Service:          service,
-			cli:              h.cli,
-		}
+		h.addService(log, service)
+	}
+	return nil
+}
 
+// addService add the jobs declared in the labels of service to cron.
+func (h *Handler) addService(log *log.Entry, service swarm.Service) {
+	jobs, err := h.serviceJobs(service)
+	if err != nil {
+		log.WithError(err).Errorln("add service job to cron is in error")
+		return
+	}
+	for _, j := range jobs {
 		if err := h.cron.AddServiceJob(j); err != nil {
-			log.WithError(err).Errorln("add service job to cron is in error")
+			log.WithError(err).WithField("action", j.Action).Errorln("add service job to cron is in error")
 		}
 	}
-	return nil
+}
+
+// containerJob read the job l declared in the labels of container.
+func (h *Handler) containerJob(container container.Summary, l labelJob) (ContainerJob, error) {
+	retry, err := labelRetry(l)
+	return ContainerJob{
+		Name:        l.get("name"),
+		Label:       l.name,
+		Schedule:    l.get("schedule"),
+		Action:      l.get("action"),
+		Timeout:     l.get("timeout"),
+		Command:     l.get("command"),
+		Concurrency: l.get("concurrency"),
+		Catchup:     l.get("catchup"),
+		Retry:       retry,
+		Notify:      labelNotify(l),
+		Ping:        labelPing(l),
+		MailTo:      l.get("mailto"),
+		MailOn:      l.get("mailon"),
+		Wait:        l.get("wait") == "true",
+		Image:       l.get("image"),
+		Env:         splitList(l.get("env")),
+		Mounts:      splitList(l.get("mounts")),
+		Network:     l.get("network"),
+		AutoRemove:  l.get("autoremove") == "true",
+		Container:   container,
+		cli:         h.cli,
+	}, err
+}
+
+// serviceJobs read the job declared in the labels of service. A 'scaledown'
+// job with a scale-up schedule is paired with the 'scaleup' job restoring the
+// service.
+func (h *Handler) serviceJobs(service swarm.Service) ([]ServiceJob, error) {
+	l := labelJob{labels: service.Spec.Labels}
+	j, err := h.serviceJob(service, l)
+	jobs := []ServiceJob{j}
+
+	scaleup := l.get("scaleup")
+	if scaleup == "" {
+		return jobs, err
+	}
+	if j.Action != "scaledown" {
+		return jobs, errors.New("a scale-up schedule can be specified only with 'scaledown' action")
+	}
+
+	up := j
+	up.Schedule = scaleup
+	up.Action = "scaleup"
+	up.Replicas = ""
+	if j.Name != "" {
+		up.Name = j.Name + "-up"
+	}
+	return append(jobs, up), err
+}
+
+// serviceJob read the job l declared in the labels of service.
+func (h *Handler) serviceJob(service swarm.Service, l labelJob) (ServiceJob, error) {
+	retry, err := labelRetry(l)
+	return ServiceJob{
+		Name:             l.get("name"),
+		Schedule:         l.get("schedule"),
+		Action:           l.get("action"),
+		Command:          l.get("command"),
+		Slot:             l.get("slot"),
+		Replicas:         l.get("replicas"),
+		Timeout:          l.get("timeout"),
+		Concurrency:      l.get("concurrency"),
+		Catchup:          l.get("catchup"),
+		Retry:            retry,
+		Notify:           labelNotify(l),
+		Ping:             labelPing(l),
+		MailTo:           l.get("mailto"),
+		MailOn:           l.get("mailon"),
+		ServiceID:        service.ID,
+		ServiceName:      service.Spec.Name,
+		ServiceVersion:   service.Version,
+		ServiceCreatedAt: service.CreatedAt,
+		cli:              h.cli,
+	}, err
 }
@@ -5,6 +5,7 @@ import (
 	context "context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/docker/docker/api/types/swarm"
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	log "github.com/sirupsen/logrus"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
@@ -77,12 +79,9 @@ func TestScanContainer(t *testing.T) {
 		checks []checkFunc
 	}{
 		{
-			name: "scan filtered by 'mobycron.schedule' label",
+			name: "scan all containers",
 			mock: func(sc *MockCronner, cli *MockDockerClient) {
-				args := filters.NewArgs()
-				args.Add("label", "mobycron.schedule")
-
-				opt := container.ListOptions{All: true, Filters: args}
+				opt := container.ListOptions{All: true, Filters: filters.NewArgs()}
 				cli.EXPECT().ContainerList(gomock.Any(), opt).Return(nil, nil)
 				cli.EXPECT().Close()
 			},
@@ -240,10 +239,10 @@ func TestListenContainer(t *testing.T) {
 			name: "container created",
 			mock: func(sc *MockCronner, cli *MockDockerClient, eventChan chan events.Message, errChan chan error) {
 				eventOpt := events.ListOptions{Filters: filters.NewArgs()}
-				eventOpt.Filters.Add("label", "mobycron.schedule")
 				eventOpt.Filters.Add("type", "container")
 				eventOpt.Filters.Add("event", "create")
 				eventOpt.Filters.Add("event", "destroy")
+				eventOpt.Filters.Add("event", "rename")
 
 				listOpt := container.ListOptions{All: true, Filters: filters.NewArgs()}
 				listOpt.Filters.Add("id", "1")
@@ -290,6 +289,23 @@ func TestListenContainer(t *testing.T) {
 				hasLogField("level", "info"),
 				hasLogField("func", "Handler.ListenContainer"),
 				hasLogField("msg", "event message from server"),
+				func(t *testing.T, out string) {
+					assert.Assert(t, testutil.ToFloat64(dockerEvents.WithLabelValues("", "destroy")) > 0)
+				},
+			),
+		},
+		{
+			name: "container renamed",
+			mock: func(sc *MockCronner, cli *MockDockerClient, eventChan chan events.Message, errChan chan error) {
+				cli.EXPECT().Events(gomock.Any(), gomock.Any()).Return(eventChan, errChan)
+				sc.EXPECT().RenameContainerJob("1", "db")
+			},
+			events: func(eventChan chan events.Message, errChan chan error) {
+				eventChan <- events.Message{Action: "rename", Actor: events.Actor{ID: "1", Attributes: map[string]string{"name": "db", "oldName": "/0123456789ab_db"}}}
+			},
+			checks: check(
+				hasLogField("func", "Handler.ListenContainer"),
+				hasLogField("msg", "event message from server"),
 			),
 		},
 		{
@@ -555,6 +571,12 @@ func TestAddContainers(t *testing.T) {
 		}
 	}
 
+	hasNoLogField := func(field string, want string) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.Assert(t, !strings.Contains(out, fmt.Sprintf("\"%s\":\"%s\"", field, want)))
+		}
+	}
+
 	tests := []struct {
 		name    string
 		filters filters.Args
@@ -581,22 +603,36 @@ func TestAddContainers(t *testing.T) {
 					{
 						ID: "12345",
 						Labels: map[string]string{
-							"mobycron.schedule": "3 * * * * *",
-							"mobycron.action":   "exec",
-							"mobycron.timeout":  "30",
-							"mobycron.command":  "echo 'do job'",
+							"mobycron.schedule":       "3 * * * * *",
+							"mobycron.action":         "exec",
+							"mobycron.timeout":        "30",
+							"mobycron.command":        "echo 'do job'",
+							"mobycron.concurrency":    "skip",
+							"mobycron.catchup":        "once",
+							"mobycron.retry.attempts": "3",
+							"mobycron.retry.delay":    "5s",
+							"mobycron.notify.urls":    "http://hooks/a,http://hooks/b",
+							"mobycron.notify.on":      "failure,recovery",
+							"mobycron.ping.url":       "https://hc-ping.com/uuid",
+							"mobycron.mailto":         "dba@example.com",
 						},
 					},
 				}
 				cli.EXPECT().ContainerList(ctx, opt).Return(containers, nil)
 				sc.EXPECT().AddContainerJob(ContainerJob{
-					Schedule:  "3 * * * * *",
-					Action:    "exec",
-					Timeout:   "30",
-					Command:   "echo 'do job'",
-					Container: containers[0],
-					cron:      nil,
-					cli:       cli,
+					Schedule:    "3 * * * * *",
+					Action:      "exec",
+					Timeout:     "30",
+					Command:     "echo 'do job'",
+					Concurrency: "skip",
+					Catchup:     "once",
+					Retry:       Retry{Attempts: 3, Delay: "5s"},
+					Notify:      Notify{URLs: []string{"http://hooks/a", "http://hooks/b"}, On: "failure,recovery"},
+					Ping:        Ping{URL: "https://hc-ping.com/uuid"},
+					MailTo:      "dba@example.com",
+					Container:   containers[0],
+					cron:        nil,
+					cli:         cli,
 				})
 			},
 			checks: check(
@@ -656,6 +692,128 @@ func TestAddContainers(t *testing.T) {
 				hasNilError(),
 			),
 		},
+		{
+			name:    "many jobs for one container",
+			filters: filters.NewArgs(),
+			mock: func(sc *MockCronner, cli *MockDockerClient, filters filters.Args) {
+				containers := []types.Container{
+					{
+						ID: "1",
+						Labels: map[string]string{
+							"mobycron.schedule":         "1 * * * *",
+							"mobycron.action":           "start",
+							"mobycron.dump.schedule":    "0 * * * *",
+							"mobycron.dump.action":      "exec",
+							"mobycron.dump.command":     "pg_dumpall",
+							"mobycron.dump.concurrency": "skip",
+							"mobycron.dump.name":        "pg-dump",
+							"mobycron.nightly.schedule": "0 2 * * *",
+							"mobycron.nightly.action":   "restart",
+							"mobycron.nightly.timeout":  "30",
+							"mobycron.batch.schedule":   "0 4 * * *",
+							"mobycron.batch.action":     "start",
+							"mobycron.batch.wait":       "true",
+						},
+					},
+				}
+				cli.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return(containers, nil)
+				gomock.InOrder(
+					sc.EXPECT().AddContainerJob(ContainerJob{
+						Schedule:  "1 * * * *",
+						Action:    "start",
+						Container: containers[0],
+						cli:       cli,
+					}),
+					sc.EXPECT().AddContainerJob(ContainerJob{
+						Label:     "batch",
+						Schedule:  "0 4 * * *",
+						Action:    "start",
+						Wait:      true,
+						Container: containers[0],
+						cli:       cli,
+					}),
+					sc.EXPECT().AddContainerJob(ContainerJob{
+						Name:        "pg-dump",
+						Label:       "dump",
+						Schedule:    "0 * * * *",
+						Action:      "exec",
+						Command:     "pg_dumpall",
+						Concurrency: "skip",
+						Container:   containers[0],
+						cli:         cli,
+					}),
+					sc.EXPECT().AddContainerJob(ContainerJob{
+						Label:     "nightly",
+						Schedule:  "0 2 * * *",
+						Action:    "restart",
+						Timeout:   "30",
+						Container: containers[0],
+						cli:       cli,
+					}),
+				)
+			},
+			checks: check(
+				hasNilError(),
+			),
+		},
+		{
+			name:    "run action",
+			filters: filters.NewArgs(),
+			mock: func(sc *MockCronner, cli *MockDockerClient, filters filters.Args) {
+				containers := []types.Container{
+					{
+						ID:    "1",
+						Image: "postgres",
+						Labels: map[string]string{
+							"mobycron.schedule":   "0 * * * *",
+							"mobycron.action":     "run",
+							"mobycron.image":      "alpine",
+							"mobycron.command":    "ls /data",
+							"mobycron.env":        "A=1, B=2",
+							"mobycron.mounts":     "/data:/data:ro",
+							"mobycron.network":    "backend",
+							"mobycron.autoremove": "true",
+						},
+					},
+				}
+				cli.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return(containers, nil)
+				sc.EXPECT().AddContainerJob(ContainerJob{
+					Schedule:   "0 * * * *",
+					Action:     "run",
+					Command:    "ls /data",
+					Image:      "alpine",
+					Env:        []string{"A=1", "B=2"},
+					Mounts:     []string{"/data:/data:ro"},
+					Network:    "backend",
+					AutoRemove: true,
+					Container:  containers[0],
+					cli:        cli,
+				})
+			},
+			checks: check(
+				hasNilError(),
+			),
+		},
+		{
+			name:    "container without mobycron label",
+			filters: filters.NewArgs(),
+			mock: func(sc *MockCronner, cli *MockDockerClient, filters filters.Args) {
+				containers := []types.Container{
+					{
+						ID: "1",
+						Labels: map[string]string{
+							"com.docker.swarm.task.name": "sname.1.tid",
+							"mobycron.action":            "start",
+						},
+					},
+				}
+				cli.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return(containers, nil)
+			},
+			checks: check(
+				hasNilError(),
+				hasNoLogField("level", "error"),
+			),
+		},
 		{
 			name:    "container from service/task",
 			filters: filters.NewArgs(),
@@ -681,6 +839,28 @@ func TestAddContainers(t *testing.T) {
 				hasLogField("msg", "mobycron label must be set on service, not directly on the container"),
 			),
 		},
+		{
+			name:    "container created by a run job",
+			filters: filters.NewArgs(),
+			mock: func(sc *MockCronner, cli *MockDockerClient, filters filters.Args) {
+				containers := []types.Container{
+					{
+						ID: "run1",
+						Labels: map[string]string{
+							"mobycron.run":      "true",
+							"mobycron.schedule": "@daily",
+							"mobycron.action":   "run",
+						},
+					},
+				}
+				cli.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return(containers, nil)
+			},
+			checks: check(
+				hasNilError(),
+				hasLogField("msg", "skipped, container created by a run job"),
+				hasNoLogField("level", "error"),
+			),
+		},
 		{
 			name:    "ContainerList in error",
 			filters: filters.NewArgs(),
@@ -691,11 +871,25 @@ func TestAddContainers(t *testing.T) {
 				hasError("ContainerList in error"),
 			),
 		},
+		{
+			name:    "invalid retry label",
+			filters: filters.NewArgs(),
+			mock: func(sc *MockCronner, cli *MockDockerClient, filters filters.Args) {
+				containers := []types.Container{{ID: "1", Labels: map[string]string{"mobycron.schedule": "1 * * * *", "mobycron.retry.attempts": "many"}}}
+				cli.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return(containers, nil)
+			},
+			checks: check(
+				hasNilError(),
+				hasLogField("level", "error"),
+				hasLogField("error", "invalid retry attempts, only positive integer are permitted"),
+				hasLogField("msg", "add container job to cron is in error"),
+			),
+		},
 		{
 			name:    "AddContainerJob in error",
 			filters: filters.NewArgs(),
 			mock: func(sc *MockCronner, cli *MockDockerClient, filters filters.Args) {
-				containers := []types.Container{{ID: "1"}}
+				containers := []types.Container{{ID: "1", Labels: map[string]string{"mobycron.schedule": "1 * * * *"}}}
 				cli.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return(containers, nil)
 				sc.EXPECT().AddContainerJob(gomock.Any()).Return(errors.New("AddContainerJob in error"))
 			},
@@ -788,10 +982,19 @@ func TestAddServices(t *testing.T) {
 							Annotations: swarm.Annotations{
 								Name: "name1",
 								Labels: map[string]string{
-									"mobycron.schedule": "3 * * * * *",
-									"mobycron.action":   "exec",
-									"mobycron.timeout":  "30",
-									"mobycron.command":  "echo 'do job'",
+									"mobycron.schedule":        "3 * * * * *",
+									"mobycron.action":          "exec",
+									"mobycron.timeout":         "30",
+									"mobycron.command":         "echo 'do job'",
+									"mobycron.concurrency":     "queue",
+									"mobycron.catchup":         "all",
+									"mobycron.retry.exitcodes": "1,2",
+									"mobycron.notify.template": "{{.Job}}",
+									"mobycron.ping.url":        "https://hc-ping.com/svc",
+									"mobycron.ping.body":       "true",
+									"mobycron.mailto":          "web@example.com",
+									"mobycron.mailon":          "failure",
+									"mobycron.name":            "name1-refresh",
 								},
 							},
 						},
@@ -803,13 +1006,22 @@ func TestAddServices(t *testing.T) {
 				}
 				cli.EXPECT().ServiceList(ctx, opt).Return(services, nil)
 				sc.EXPECT().AddServiceJob(ServiceJob{
+					Name:             "name1-refresh",
 					Schedule:         "3 * * * * *",
 					Action:           "exec",
+					Command:          "echo 'do job'",
+					Timeout:          "30",
+					Concurrency:      "queue",
+					Catchup:          "all",
+					Retry:            Retry{ExitCodes: []int{1, 2}},
+					Notify:           Notify{Template: "{{.Job}}"},
+					Ping:             Ping{URL: "https://hc-ping.com/svc", Body: true},
+					MailTo:           "web@example.com",
+					MailOn:           "failure",
 					ServiceID:        "12345",
 					ServiceName:      "name1",
 					ServiceVersion:   swarm.Version{Index: 111},
 					ServiceCreatedAt: time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
-					Service:          services[0],
 					cron:             nil,
 					cli:              cli,
 				})
@@ -869,22 +1081,24 @@ func TestAddServices(t *testing.T) {
 				sc.EXPECT().AddServiceJob(ServiceJob{
 					Schedule:         "3 * * * * *",
 					Action:           "exec",
+					Command:          "echo 'do job'",
+					Timeout:          "30",
 					ServiceID:        "12345",
 					ServiceName:      "name1",
 					ServiceVersion:   swarm.Version{Index: 111},
 					ServiceCreatedAt: time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
-					Service:          services[0],
 					cron:             nil,
 					cli:              cli,
 				})
 				sc.EXPECT().AddServiceJob(ServiceJob{
 					Schedule:         "2 * * * * *",
 					Action:           "exec",
+					Command:          "echo 'do job2'",
+					Timeout:          "2",
 					ServiceID:        "2222",
 					ServiceName:      "name2",
 					ServiceVersion:   swarm.Version{Index: 222},
 					ServiceCreatedAt: time.Date(2002, 1, 1, 0, 0, 0, 0, time.UTC),
-					Service:          services[1],
 					cron:             nil,
 					cli:              cli,
 				})
@@ -929,6 +1143,68 @@ func TestAddServices(t *testing.T) {
 				hasLogField("msg", "add service job to cron is in error"),
 			),
 		},
+		{
+			name:    "scale down and up",
+			filters: filters.NewArgs(),
+			mock: func(sc *MockCronner, cli *MockDockerClient, filters filters.Args) {
+				service := swarm.Service{
+					ID: "12345",
+					Spec: swarm.ServiceSpec{
+						Annotations: swarm.Annotations{
+							Name: "web",
+							Labels: map[string]string{
+								"mobycron.schedule": "0 20 * * 1-5",
+								"mobycron.action":   "scaledown",
+								"mobycron.replicas": "1",
+								"mobycron.scaleup":  "0 7 * * 1-5",
+								"mobycron.name":     "web-night",
+							},
+						},
+					},
+				}
+				down := ServiceJob{
+					Name:        "web-night",
+					Schedule:    "0 20 * * 1-5",
+					Action:      "scaledown",
+					Replicas:    "1",
+					ServiceID:   "12345",
+					ServiceName: "web",
+					cli:         cli,
+				}
+				up := down
+				up.Name = "web-night-up"
+				up.Schedule = "0 7 * * 1-5"
+				up.Action = "scaleup"
+				up.Replicas = ""
+
+				cli.EXPECT().ServiceList(gomock.Any(), gomock.Any()).Return([]swarm.Service{service}, nil)
+				sc.EXPECT().AddServiceJob(down)
+				sc.EXPECT().AddServiceJob(up)
+			},
+			checks: check(
+				hasNilError(),
+			),
+		},
+		{
+			name:    "scale-up schedule without scale down",
+			filters: filters.NewArgs(),
+			mock: func(sc *MockCronner, cli *MockDockerClient, filters filters.Args) {
+				services := []swarm.Service{
+					{ID: "111", Spec: swarm.ServiceSpec{Annotations: swarm.Annotations{Name: "web", Labels: map[string]string{
+						"mobycron.schedule": "0 20 * * *",
+						"mobycron.action":   "scale",
+						"mobycron.scaleup":  "0 7 * * *",
+					}}}},
+				}
+
+				cli.EXPECT().ServiceList(gomock.Any(), gomock.Any()).Return(services, nil)
+			},
+			checks: check(
+				hasNilError(),
+				hasLogField("level", "error"),
+				hasLogField("error", "a scale-up schedule can be specified only with 'scaledown' action"),
+			),
+		},
 		{
 			name:    "skipped - no label",
 			filters: filters.NewArgs(),
@@ -971,3 +1247,246 @@ func TestAddServices(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateContainers(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cron := NewMockCronner(ctrl)
+	cli := NewMockDockerClient(ctrl)
+	h := &Handler{cron, cli}
+
+	containers := []types.Container{
+		{ID: "1", Labels: map[string]string{"mobycron.schedule": "1 * * * *", "mobycron.action": "start", "mobycron.dump.schedule": "2 * * * *", "mobycron.dump.retry.attempts": "many"}},
+		{ID: "2", Labels: map[string]string{"mobycron.schedule": "1 * * * *", "com.docker.swarm.task.name": "sname.1.tid"}},
+		{ID: "3", Labels: map[string]string{"other": "label"}},
+		{ID: "4", Labels: map[string]string{"mobycron.schedule": "@daily", "mobycron.action": "run", "mobycron.run": "true"}},
+	}
+	cli.EXPECT().ContainerList(gomock.Any(), container.ListOptions{All: true}).Return(containers, nil)
+	cli.EXPECT().Close()
+	cron.EXPECT().ValidateContainerJob(gomock.Any(), nil).DoAndReturn(func(j ContainerJob, err error) Validation {
+		assert.Equal(t, j.Action, "start")
+		return Validation{Name: "c1-start"}
+	})
+	cron.EXPECT().ValidateContainerJob(gomock.Any(), gomock.Not(nil)).DoAndReturn(func(j ContainerJob, err error) Validation {
+		assert.Equal(t, j.Label, "dump")
+		assert.Assert(t, is.ErrorContains(err, "invalid retry attempts"))
+		return Validation{Name: "c1-dump", Err: err}
+	})
+	cron.EXPECT().ValidateContainerJob(gomock.Any(), gomock.Not(nil)).DoAndReturn(func(j ContainerJob, err error) Validation {
+		assert.Equal(t, j.Container.ID, "2")
+		assert.Error(t, err, "mobycron label must be set on service, not directly on the container")
+		return Validation{Name: "c2", Err: err}
+	})
+
+	// Act
+	v, err := h.ValidateContainers()
+
+	// Assert
+	assert.NilError(t, err)
+	assert.Equal(t, len(v), 3)
+}
+
+func TestValidateContainersError(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cli := NewMockDockerClient(ctrl)
+	h := &Handler{NewMockCronner(ctrl), cli}
+
+	cli.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return(nil, errors.New("ContainerList in error"))
+	cli.EXPECT().Close()
+
+	// Act
+	_, err := h.ValidateContainers()
+
+	// Assert
+	assert.Error(t, err, "ContainerList in error")
+}
+
+func TestValidateServices(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cron := NewMockCronner(ctrl)
+	cli := NewMockDockerClient(ctrl)
+	h := &Handler{cron, cli}
+
+	f := filters.NewArgs()
+	f.Add("label", "mobycron.schedule")
+	services := []swarm.Service{
+		{ID: "1", Spec: swarm.ServiceSpec{Annotations: swarm.Annotations{Name: "web", Labels: map[string]string{"mobycron.schedule": "1 * * * *", "mobycron.action": "update"}}}},
+		{ID: "2", Spec: swarm.ServiceSpec{Annotations: swarm.Annotations{Name: "api", Labels: map[string]string{"mobycron.schedule": "1 * * * *", "mobycron.retry.attempts": "many"}}}},
+	}
+	cli.EXPECT().ServiceList(gomock.Any(), swarm.ServiceListOptions{Filters: f}).Return(services, nil)
+	cli.EXPECT().Close()
+	cron.EXPECT().ValidateServiceJob(gomock.Any(), nil).DoAndReturn(func(j ServiceJob, err error) Validation {
+		assert.Equal(t, j.ServiceName, "web")
+		return Validation{Name: "web-update"}
+	})
+	cron.EXPECT().ValidateServiceJob(gomock.Any(), gomock.Not(nil)).DoAndReturn(func(j ServiceJob, err error) Validation {
+		assert.Equal(t, j.ServiceName, "api")
+		return Validation{Name: "api-", Err: err}
+	})
+
+	// Act
+	v, err := h.ValidateServices()
+
+	// Assert
+	assert.NilError(t, err)
+	assert.Equal(t, len(v), 2)
+	assert.Assert(t, is.ErrorContains(v[1].Err, "invalid retry attempts"))
+}
+
+func TestReconcileContainers(t *testing.T) {
+	// Arrange
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cron := NewMockCronner(ctrl)
+	cli := NewMockDockerClient(ctrl)
+	h := &Handler{cron, cli}
+
+	containers := []container.Summary{
+		{ID: "added", Names: []string{"/web"}, Labels: map[string]string{"mobycron.schedule": "@daily", "mobycron.action": "restart"}},
+		{ID: "missed", Names: []string{"/db"}, Labels: map[string]string{"mobycron.schedule": "@daily", "mobycron.action": "restart"}},
+		{ID: "invalid", Names: []string{"/cache"}, Labels: map[string]string{"mobycron.schedule": "@daily", "mobycron.action": "reboot"}},
+		{ID: "partial", Names: []string{"/queue"}, Labels: map[string]string{"mobycron.schedule": "@daily", "mobycron.action": "restart", "mobycron.purge.schedule": "@hourly", "mobycron.purge.action": "exec", "mobycron.purge.command": "purge"}},
+		{ID: "unlabeled", Names: []string{"/proxy"}},
+		{ID: "run", Names: []string{"/eager_run"}, Labels: map[string]string{"mobycron.schedule": "@daily", "mobycron.action": "run", "mobycron.run": "true"}},
+	}
+	cli.EXPECT().ContainerList(gomock.Any(), container.ListOptions{All: true}).Return(containers, nil)
+	cli.EXPECT().Close()
+	cron.EXPECT().Containers().Return(map[string][]string{"added": {""}, "partial": {""}, "destroyed": {""}})
+	cron.EXPECT().ValidateContainerJob(gomock.Any(), nil).DoAndReturn(func(j ContainerJob, err error) Validation {
+		if j.Action == "reboot" {
+			return Validation{Err: errors.New("invalid container action")}
+		}
+		return Validation{}
+	}).Times(3)
+	cron.EXPECT().AddContainerJob(gomock.Any()).DoAndReturn(func(j ContainerJob) error {
+		assert.Equal(t, j.Container.ID, "missed")
+		return nil
+	})
+	cron.EXPECT().AddContainerJob(gomock.Any()).DoAndReturn(func(j ContainerJob) error {
+		assert.Equal(t, j.Container.ID, "partial")
+		assert.Equal(t, j.Label, "purge")
+		return errors.New("add error")
+	})
+	cron.EXPECT().RemoveContainerJob("destroyed")
+
+	// Act
+	err := h.reconcileContainers()
+
+	// Assert
+	assert.NilError(t, err)
+	assert.Assert(t, is.Contains(out.String(), "container jobs missing from cron, add them"))
+	assert.Assert(t, is.Contains(out.String(), "add container job to cron is in error"))
+	assert.Assert(t, is.Contains(out.String(), "container no longer exist, remove its jobs"))
+}
+
+func TestReconcileServices(t *testing.T) {
+	// Arrange
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cron := NewMockCronner(ctrl)
+	cli := NewMockDockerClient(ctrl)
+	h := &Handler{cron, cli}
+
+	service := func(ID string, version uint64, action string) swarm.Service {
+		return swarm.Service{
+			ID:   ID,
+			Meta: swarm.Meta{Version: swarm.Version{Index: version}},
+			Spec: swarm.ServiceSpec{Annotations: swarm.Annotations{Name: ID, Labels: map[string]string{"mobycron.schedule": "@daily", "mobycron.action": action}}},
+		}
+	}
+	services := []swarm.Service{
+		service("current", 10, "update"),
+		service("updated", 21, "update"),
+		service("missed", 30, "update"),
+		service("invalid", 40, "reboot"),
+	}
+	f := filters.NewArgs()
+	f.Add("label", "mobycron.schedule")
+	cli.EXPECT().ServiceList(gomock.Any(), swarm.ServiceListOptions{Filters: f}).Return(services, nil)
+	cli.EXPECT().Close()
+	cron.EXPECT().Services().Return(map[string]swarm.Version{"current": {Index: 10}, "updated": {Index: 20}, "removed": {Index: 50}})
+	cron.EXPECT().ValidateServiceJob(gomock.Any(), nil).DoAndReturn(func(j ServiceJob, err error) Validation {
+		if j.Action == "reboot" {
+			return Validation{Err: errors.New("invalid service action")}
+		}
+		return Validation{}
+	}).Times(2)
+	cron.EXPECT().RemoveServiceJob("updated")
+	cron.EXPECT().AddServiceJob(gomock.Any()).DoAndReturn(func(j ServiceJob) error {
+		assert.Equal(t, j.ServiceVersion, swarm.Version{Index: 21})
+		return nil
+	})
+	cron.EXPECT().AddServiceJob(gomock.Any()).DoAndReturn(func(j ServiceJob) error {
+		assert.Equal(t, j.ServiceID, "missed")
+		return nil
+	})
+	cron.EXPECT().RemoveServiceJob("removed")
+
+	// Act
+	err := h.reconcileServices()
+
+	// Assert
+	assert.NilError(t, err)
+	assert.Assert(t, is.Contains(out.String(), "service jobs outdated in cron, update them"))
+	assert.Assert(t, is.Contains(out.String(), "service jobs missing from cron, add them"))
+	assert.Assert(t, is.Contains(out.String(), "service no longer exist, remove its jobs"))
+}
+
+func TestReconcileError(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cron := NewMockCronner(ctrl)
+	cli := NewMockDockerClient(ctrl)
+	h := &Handler{cron, cli}
+
+	cli.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return(nil, errors.New("list error"))
+	cli.EXPECT().ServiceList(gomock.Any(), gomock.Any()).Return(nil, errors.New("list error"))
+	cli.EXPECT().Close().Times(2)
+
+	// Act
+	errContainers := h.reconcileContainers()
+	errServices := h.reconcileServices()
+
+	// Assert
+	assert.Error(t, errContainers, "list error")
+	assert.Error(t, errServices, "list error")
+}
+
+func TestReconcileContainer(t *testing.T) {
+	// Arrange
+	log.SetOutput(&bytes.Buffer{})
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cron := NewMockCronner(ctrl)
+	cli := NewMockDockerClient(ctrl)
+	h := &Handler{cron, cli}
+
+	done := make(chan bool)
+	cli.EXPECT().ContainerList(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, container.ListOptions) ([]container.Summary, error) {
+		done <- true
+		return nil, errors.New("list error")
+	}).MinTimes(1)
+	cli.EXPECT().Close().AnyTimes()
+
+	// Act
+	h.ReconcileContainer(time.Millisecond)
+
+	// Assert
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("containers not reconciled")
+	}
+}
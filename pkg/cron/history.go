@@ -0,0 +1,266 @@
+package cron
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// historyFile is the name of the history file in the data directory.
+const historyFile = "history.jsonl"
+
+// maxOutput is the maximum size of the output kept in the history of a run.
+const maxOutput = 4096
+
+// pruneInterval is the number of runs added between two prunes of the history.
+const pruneInterval = 100
+
+// Run is the result of a job run.
+type Run struct {
+	Job      string        `json:"job"`
+	Source   string        `json:"source"`
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Duration time.Duration `json:"duration"`
+	ExitCode int           `json:"exitCode"`
+	Output   string        `json:"output,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// History persist the runs of all jobs in the data directory. Runs beyond
+// maxCount by job or older than maxAge are dropped.
+type History struct {
+	fs       afero.Fs
+	filename string
+	maxCount int
+	maxAge   time.Duration
+	added    int
+	mu       sync.Mutex
+}
+
+// NewHistory returns a history stored in dir.
+func NewHistory(dir string, maxCount int, maxAge time.Duration) *History {
+	return &History{
+		fs:       afero.NewOsFs(),
+		filename: filepath.Join(dir, historyFile),
+		maxCount: maxCount,
+		maxAge:   maxAge,
+	}
+}
+
+// Add a run to the history.
+func (h *History) Add(run Run) error {
+	if len(run.Output) > maxOutput {
+		run.Output = run.Output[len(run.Output)-maxOutput:]
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode run")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.fs.MkdirAll(filepath.Dir(h.filename), 0750); err != nil {
+		return errors.Wrap(err, "failed to create data directory")
+	}
+	f, err := h.fs.OpenFile(h.filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return errors.Wrap(err, "failed to open history file")
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write history file")
+	}
+
+	h.added++
+	if h.added%pruneInterval == 0 {
+		return h.prune()
+	}
+	return nil
+}
+
+// Runs return the runs of job, or of all jobs when job is empty, from the
+// oldest to the newest.
+func (h *History) Runs(job string) ([]Run, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	runs, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []Run{}
+	for _, r := range runs {
+		if job == "" || r.Job == job {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, nil
+}
+
+// Prune drop the runs beyond the retention.
+func (h *History) Prune() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.prune()
+}
+
+func (h *History) prune() error {
+	runs, err := h.read()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, r := range runs {
+		data, _ := json.Marshal(r)
+		buf.Write(append(data, '\n'))
+	}
+
+	// Replace the file at once so that a reader never see a partial history.
+	tmp := h.filename + ".tmp"
+	if err := afero.WriteFile(h.fs, tmp, buf.Bytes(), 0640); err != nil {
+		return errors.Wrap(err, "failed to write history file")
+	}
+	if err := h.fs.Rename(tmp, h.filename); err != nil {
+		return errors.Wrap(err, "failed to write history file")
+	}
+	return nil
+}
+
+// read the runs kept by the retention, sorted by start time.
+func (h *History) read() ([]Run, error) {
+	data, err := afero.ReadFile(h.fs, h.filename)
+	if os.IsNotExist(err) {
+		return []Run{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read history file")
+	}
+
+	runs := []Run{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var r Run
+		// A line being written by another process is skipped.
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		if h.maxAge > 0 && time.Since(r.Start) > h.maxAge {
+			continue
+		}
+		runs = append(runs, r)
+	}
+	sort.SliceStable(runs, func(i, k int) bool { return runs[i].Start.Before(runs[k].Start) })
+
+	if h.maxCount <= 0 {
+		return runs, nil
+	}
+
+	// Keep the newest runs of each job
+	count := map[string]int{}
+	kept := make([]Run, 0, len(runs))
+	for i := len(runs) - 1; i >= 0; i-- {
+		key := runs[i].Source + "/" + runs[i].Job
+		if count[key] < h.maxCount {
+			count[key]++
+			kept = append(kept, runs[i])
+		}
+	}
+	for i, k := 0, len(kept)-1; i < k; i, k = i+1, k-1 {
+		kept[i], kept[k] = kept[k], kept[i]
+	}
+	return kept, nil
+}
+
+// exitError is the error of a process completed with a non zero exit code.
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return "exit status " + strconv.Itoa(e.code)
+}
+
+// ExitCode return the exit code of a run completed with err, -1 when the run
+// failed before or without an exit code.
+func ExitCode(err error) int {
+	var ee *exec.ExitError
+	var xe *exitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &ee):
+		return ee.ExitCode()
+	case errors.As(err, &xe):
+		return xe.code
+	default:
+		return -1
+	}
+}
+
+// record the outcome of a job run started at start in metrics, history and
+// state, and notify or mail it.
+func (c *Cron) record(job scheduledJob, start time.Time, out string, err error) {
+	labels := job.labels()
+	observeRun(labels, start, err)
+
+	if err == nil && c.state != nil {
+		if err := c.state.SetLastSuccess(job.id(), start); err != nil {
+			log.WithFields(log.Fields{
+				"func": "Cron.record",
+				"job":  job.id(),
+			}).WithError(err).Errorln("failed to save last successful run")
+		}
+	}
+
+	if c.history == nil && c.notifier == nil && c.mailer == nil {
+		return
+	}
+
+	end := time.Now()
+	run := Run{
+		Job:      labels["name"],
+		Source:   labels["source"],
+		Start:    start,
+		End:      end,
+		Duration: end.Sub(start),
+		ExitCode: ExitCode(err),
+		Output:   out,
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+	if c.notifier != nil {
+		c.notifier.notify(job, run)
+	}
+	if c.mailer != nil {
+		c.mailer.mail(job, run)
+	}
+	if c.history == nil {
+		return
+	}
+	if err := c.history.Add(run); err != nil {
+		log.WithFields(log.Fields{
+			"func": "Cron.record",
+			"job":  run.Job,
+		}).WithError(err).Errorln("failed to add run to history")
+	}
+}
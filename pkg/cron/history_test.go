@@ -0,0 +1,201 @@
+package cron
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func newTestHistory(maxCount int, maxAge time.Duration) *History {
+	h := NewHistory("/data", maxCount, maxAge)
+	h.fs = afero.NewMemMapFs()
+	return h
+}
+
+func TestHistoryRuns(t *testing.T) {
+	now := time.Now().Round(0)
+	run := func(job string, ago time.Duration) Run {
+		return Run{Job: job, Source: "file", Start: now.Add(-ago), End: now.Add(-ago + time.Second), Duration: time.Second}
+	}
+
+	tests := []struct {
+		name     string
+		maxCount int
+		maxAge   time.Duration
+		runs     []Run
+		job      string
+		want     []Run
+	}{
+		{
+			name: "empty history",
+			want: []Run{},
+		},
+		{
+			name: "all jobs sorted by start",
+			runs: []Run{run("a", time.Minute), run("b", 3*time.Minute), run("a", 2*time.Minute)},
+			want: []Run{run("b", 3*time.Minute), run("a", 2*time.Minute), run("a", time.Minute)},
+		},
+		{
+			name: "one job",
+			runs: []Run{run("a", time.Minute), run("b", 2*time.Minute)},
+			job:  "b",
+			want: []Run{run("b", 2*time.Minute)},
+		},
+		{
+			name:     "retention by count for each job",
+			maxCount: 2,
+			runs:     []Run{run("a", 4*time.Minute), run("a", 3*time.Minute), run("b", 3*time.Minute), run("a", 2*time.Minute)},
+			want:     []Run{run("a", 3*time.Minute), run("b", 3*time.Minute), run("a", 2*time.Minute)},
+		},
+		{
+			name:   "retention by age",
+			maxAge: time.Hour,
+			runs:   []Run{run("a", 2*time.Hour), run("a", time.Minute)},
+			want:   []Run{run("a", time.Minute)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			h := newTestHistory(tt.maxCount, tt.maxAge)
+			for _, r := range tt.runs {
+				assert.NilError(t, h.Add(r))
+			}
+
+			// Act
+			runs, err := h.Runs(tt.job)
+
+			// Assert
+			assert.NilError(t, err)
+			assert.Assert(t, is.DeepEqual(runs, tt.want))
+		})
+	}
+}
+
+func TestHistoryAddTruncateOutput(t *testing.T) {
+	// Arrange
+	h := newTestHistory(0, 0)
+	output := strings.Repeat("a", maxOutput) + "end"
+
+	// Act
+	err := h.Add(Run{Job: "a", Output: output})
+
+	// Assert
+	assert.NilError(t, err)
+	runs, _ := h.Runs("a")
+	assert.Equal(t, len(runs[0].Output), maxOutput)
+	assert.Assert(t, strings.HasSuffix(runs[0].Output, "end"))
+}
+
+func TestHistoryAddError(t *testing.T) {
+	// Arrange
+	h := newTestHistory(0, 0)
+	h.fs = afero.NewReadOnlyFs(afero.NewMemMapFs())
+
+	// Act
+	err := h.Add(Run{Job: "a"})
+
+	// Assert
+	assert.Assert(t, is.ErrorContains(err, "failed to"))
+}
+
+func TestHistoryPrune(t *testing.T) {
+	// Arrange
+	h := newTestHistory(1, 0)
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.NilError(t, h.Add(Run{Job: "a", Start: now.Add(time.Duration(i) * time.Second)}))
+	}
+	f, _ := h.fs.OpenFile(h.filename, os.O_APPEND|os.O_WRONLY, 0640)
+	f.WriteString("{\"job\":\"partial")
+	f.Close()
+
+	// Act
+	err := h.Prune()
+
+	// Assert
+	assert.NilError(t, err)
+	data, _ := afero.ReadFile(h.fs, h.filename)
+	assert.Equal(t, strings.Count(string(data), "\n"), 1)
+	assert.Assert(t, is.Contains(string(data), now.Add(2*time.Second).Format(time.RFC3339Nano)))
+}
+
+func TestHistoryAddPruneInterval(t *testing.T) {
+	// Arrange
+	h := newTestHistory(1, 0)
+
+	// Act
+	for i := 0; i < pruneInterval; i++ {
+		assert.NilError(t, h.Add(Run{Job: "a"}))
+	}
+
+	// Assert
+	data, _ := afero.ReadFile(h.fs, h.filename)
+	assert.Equal(t, strings.Count(string(data), "\n"), 1)
+}
+
+func TestExitCode(t *testing.T) {
+	execErr := exec.Command("sh", "-c", "exit 3").Run()
+
+	assert.Equal(t, ExitCode(nil), 0)
+	assert.Equal(t, ExitCode(execErr), 3)
+	assert.Equal(t, ExitCode(&exitError{2}), 2)
+	assert.Equal(t, ExitCode(errors.Wrap(&exitError{4}, "wrapped")), 4)
+	assert.Equal(t, ExitCode(errors.New("other")), -1)
+	assert.Equal(t, (&exitError{1}).Error(), "exit status 1")
+}
+
+func TestCronRecord(t *testing.T) {
+	// Arrange
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+
+	h := newTestHistory(0, 0)
+	s := newTestState()
+	c := &Cron{history: h, state: s}
+	job := &ContainerJob{Action: "exec", Container: container.Summary{Names: []string{"/c1"}}}
+	start := time.Now().Add(-time.Second).Round(0)
+
+	// Act
+	c.record(job, start, "output", &exitError{1})
+
+	// Assert
+	runs, err := h.Runs("c1-exec")
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(runs, 1))
+	assert.Equal(t, runs[0].Source, "container")
+	assert.Equal(t, runs[0].ExitCode, 1)
+	assert.Equal(t, runs[0].Output, "output")
+	assert.Equal(t, runs[0].Error, "exit status 1")
+	assert.Assert(t, runs[0].Duration >= time.Second)
+
+	last, _ := s.LastSuccess("container/c1-exec")
+	assert.Assert(t, last.IsZero())
+
+	// Act
+	c.record(job, start, "", nil)
+
+	// Assert
+	last, _ = s.LastSuccess("container/c1-exec")
+	assert.Assert(t, last.Equal(start))
+
+	// Act
+	h.fs = afero.NewReadOnlyFs(afero.NewMemMapFs())
+	s.fs = afero.NewReadOnlyFs(afero.NewMemMapFs())
+	c.record(job, start, "", nil)
+
+	// Assert
+	assert.Assert(t, is.Contains(out.String(), "failed to add run to history"))
+	assert.Assert(t, is.Contains(out.String(), "failed to save last successful run"))
+}
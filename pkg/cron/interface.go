@@ -2,17 +2,24 @@ package cron
 
 import (
 	"context"
+	"io"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/api/types/system"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	cron "github.com/robfig/cron/v3"
 )
 
 // Runner is an interface for testing robfig/cron
 type Runner interface {
 	AddJob(spec string, cmd cron.Job) (cron.EntryID, error)
+	Entries() []cron.Entry
+	Entry(id cron.EntryID) cron.Entry
 	Remove(id cron.EntryID)
 	Start()
 	Stop() context.Context
@@ -30,21 +37,45 @@ type Cronner interface {
 	AddContainerJob(job ContainerJob) error
 	AddServiceJob(job ServiceJob) error
 	RemoveContainerJob(ID string)
+	RenameContainerJob(ID string, name string)
 	RemoveServiceJob(ID string)
+	Containers() map[string][]string
+	Services() map[string]swarm.Version
+	ValidateContainerJob(job ContainerJob, err error) Validation
+	ValidateServiceJob(job ServiceJob, err error) Validation
+}
+
+// Scheduler is an interface for inspecting and controlling the jobs of cron
+type Scheduler interface {
+	Jobs() []JobInfo
+	Job(ID int) (JobInfo, error)
+	FindJob(name string) (JobInfo, error)
+	TriggerJob(ID int) error
+	RunJob(ID int, w io.Writer) error
+	PauseJob(ID int) error
+	ResumeJob(ID int) error
 }
 
 // DockerClient is the client for docker
 type DockerClient interface {
 	Close() error
+	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *ocispec.Platform, containerName string) (container.CreateResponse, error)
 	ContainerExecAttach(ctx context.Context, execID string, config container.ExecStartOptions) (types.HijackedResponse, error)
 	ContainerExecCreate(ctx context.Context, container string, config container.ExecOptions) (types.IDResponse, error)
 	ContainerExecInspect(ctx context.Context, execID string) (container.ExecInspect, error)
 	ContainerInspect(ctx context.Context, container string) (types.ContainerJSON, error)
 	ContainerList(ctx context.Context, options container.ListOptions) ([]types.Container, error)
+	ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions) (io.ReadCloser, error)
 	ContainerStart(ctx context.Context, container string, options container.StartOptions) error
 	ContainerStop(ctx context.Context, container string, timeout container.StopOptions) error
 	ContainerRestart(ctx context.Context, container string, options container.StopOptions) error
+	ContainerRemove(ctx context.Context, containerID string, options container.RemoveOptions) error
+	ContainerWait(ctx context.Context, containerID string, condition container.WaitCondition) (<-chan container.WaitResponse, <-chan error)
 	Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error)
+	ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error)
+	Info(ctx context.Context) (system.Info, error)
+	ServiceInspectWithRaw(ctx context.Context, serviceID string, options types.ServiceInspectOptions) (swarm.Service, []byte, error)
 	ServiceList(ctx context.Context, options types.ServiceListOptions) ([]swarm.Service, error)
 	ServiceUpdate(ctx context.Context, serviceID string, version swarm.Version, service swarm.ServiceSpec, options types.ServiceUpdateOptions) (swarm.ServiceUpdateResponse, error)
+	TaskList(ctx context.Context, options types.TaskListOptions) ([]swarm.Task, error)
 }
@@ -6,13 +6,18 @@ package cron
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	types "github.com/docker/docker/api/types"
 	container "github.com/docker/docker/api/types/container"
 	events "github.com/docker/docker/api/types/events"
+	image "github.com/docker/docker/api/types/image"
+	network "github.com/docker/docker/api/types/network"
 	swarm "github.com/docker/docker/api/types/swarm"
+	system "github.com/docker/docker/api/types/system"
 	gomock "github.com/golang/mock/gomock"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	v3 "github.com/robfig/cron/v3"
 )
 
@@ -54,6 +59,34 @@ func (mr *MockRunnerMockRecorder) AddJob(spec, cmd interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddJob", reflect.TypeOf((*MockRunner)(nil).AddJob), spec, cmd)
 }
 
+// Entries mocks base method.
+func (m *MockRunner) Entries() []v3.Entry {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Entries")
+	ret0, _ := ret[0].([]v3.Entry)
+	return ret0
+}
+
+// Entries indicates an expected call of Entries.
+func (mr *MockRunnerMockRecorder) Entries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Entries", reflect.TypeOf((*MockRunner)(nil).Entries))
+}
+
+// Entry mocks base method.
+func (m *MockRunner) Entry(id v3.EntryID) v3.Entry {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Entry", id)
+	ret0, _ := ret[0].(v3.Entry)
+	return ret0
+}
+
+// Entry indicates an expected call of Entry.
+func (mr *MockRunnerMockRecorder) Entry(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Entry", reflect.TypeOf((*MockRunner)(nil).Entry), id)
+}
+
 // Remove mocks base method.
 func (m *MockRunner) Remove(id v3.EntryID) {
 	m.ctrl.T.Helper()
@@ -202,6 +235,20 @@ func (mr *MockCronnerMockRecorder) AddServiceJob(job interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddServiceJob", reflect.TypeOf((*MockCronner)(nil).AddServiceJob), job)
 }
 
+// Containers mocks base method.
+func (m *MockCronner) Containers() map[string][]string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Containers")
+	ret0, _ := ret[0].(map[string][]string)
+	return ret0
+}
+
+// Containers indicates an expected call of Containers.
+func (mr *MockCronnerMockRecorder) Containers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Containers", reflect.TypeOf((*MockCronner)(nil).Containers))
+}
+
 // RemoveContainerJob mocks base method.
 func (m *MockCronner) RemoveContainerJob(ID string) {
 	m.ctrl.T.Helper()
@@ -214,6 +261,18 @@ func (mr *MockCronnerMockRecorder) RemoveContainerJob(ID interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveContainerJob", reflect.TypeOf((*MockCronner)(nil).RemoveContainerJob), ID)
 }
 
+// RenameContainerJob mocks base method.
+func (m *MockCronner) RenameContainerJob(ID, name string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RenameContainerJob", ID, name)
+}
+
+// RenameContainerJob indicates an expected call of RenameContainerJob.
+func (mr *MockCronnerMockRecorder) RenameContainerJob(ID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameContainerJob", reflect.TypeOf((*MockCronner)(nil).RenameContainerJob), ID, name)
+}
+
 // RemoveServiceJob mocks base method.
 func (m *MockCronner) RemoveServiceJob(ID string) {
 	m.ctrl.T.Helper()
@@ -226,6 +285,171 @@ func (mr *MockCronnerMockRecorder) RemoveServiceJob(ID interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveServiceJob", reflect.TypeOf((*MockCronner)(nil).RemoveServiceJob), ID)
 }
 
+// Services mocks base method.
+func (m *MockCronner) Services() map[string]swarm.Version {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Services")
+	ret0, _ := ret[0].(map[string]swarm.Version)
+	return ret0
+}
+
+// Services indicates an expected call of Services.
+func (mr *MockCronnerMockRecorder) Services() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Services", reflect.TypeOf((*MockCronner)(nil).Services))
+}
+
+// ValidateContainerJob mocks base method.
+func (m *MockCronner) ValidateContainerJob(job ContainerJob, err error) Validation {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateContainerJob", job, err)
+	ret0, _ := ret[0].(Validation)
+	return ret0
+}
+
+// ValidateContainerJob indicates an expected call of ValidateContainerJob.
+func (mr *MockCronnerMockRecorder) ValidateContainerJob(job, err interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateContainerJob", reflect.TypeOf((*MockCronner)(nil).ValidateContainerJob), job, err)
+}
+
+// ValidateServiceJob mocks base method.
+func (m *MockCronner) ValidateServiceJob(job ServiceJob, err error) Validation {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateServiceJob", job, err)
+	ret0, _ := ret[0].(Validation)
+	return ret0
+}
+
+// ValidateServiceJob indicates an expected call of ValidateServiceJob.
+func (mr *MockCronnerMockRecorder) ValidateServiceJob(job, err interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateServiceJob", reflect.TypeOf((*MockCronner)(nil).ValidateServiceJob), job, err)
+}
+
+// MockScheduler is a mock of Scheduler interface.
+type MockScheduler struct {
+	ctrl     *gomock.Controller
+	recorder *MockSchedulerMockRecorder
+}
+
+// MockSchedulerMockRecorder is the mock recorder for MockScheduler.
+type MockSchedulerMockRecorder struct {
+	mock *MockScheduler
+}
+
+// NewMockScheduler creates a new mock instance.
+func NewMockScheduler(ctrl *gomock.Controller) *MockScheduler {
+	mock := &MockScheduler{ctrl: ctrl}
+	mock.recorder = &MockSchedulerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduler) EXPECT() *MockSchedulerMockRecorder {
+	return m.recorder
+}
+
+// FindJob mocks base method.
+func (m *MockScheduler) FindJob(name string) (JobInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindJob", name)
+	ret0, _ := ret[0].(JobInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindJob indicates an expected call of FindJob.
+func (mr *MockSchedulerMockRecorder) FindJob(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindJob", reflect.TypeOf((*MockScheduler)(nil).FindJob), name)
+}
+
+// Job mocks base method.
+func (m *MockScheduler) Job(ID int) (JobInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Job", ID)
+	ret0, _ := ret[0].(JobInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Job indicates an expected call of Job.
+func (mr *MockSchedulerMockRecorder) Job(ID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Job", reflect.TypeOf((*MockScheduler)(nil).Job), ID)
+}
+
+// Jobs mocks base method.
+func (m *MockScheduler) Jobs() []JobInfo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Jobs")
+	ret0, _ := ret[0].([]JobInfo)
+	return ret0
+}
+
+// Jobs indicates an expected call of Jobs.
+func (mr *MockSchedulerMockRecorder) Jobs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Jobs", reflect.TypeOf((*MockScheduler)(nil).Jobs))
+}
+
+// PauseJob mocks base method.
+func (m *MockScheduler) PauseJob(ID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PauseJob", ID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PauseJob indicates an expected call of PauseJob.
+func (mr *MockSchedulerMockRecorder) PauseJob(ID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseJob", reflect.TypeOf((*MockScheduler)(nil).PauseJob), ID)
+}
+
+// ResumeJob mocks base method.
+func (m *MockScheduler) ResumeJob(ID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeJob", ID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResumeJob indicates an expected call of ResumeJob.
+func (mr *MockSchedulerMockRecorder) ResumeJob(ID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeJob", reflect.TypeOf((*MockScheduler)(nil).ResumeJob), ID)
+}
+
+// RunJob mocks base method.
+func (m *MockScheduler) RunJob(ID int, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunJob", ID, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunJob indicates an expected call of RunJob.
+func (mr *MockSchedulerMockRecorder) RunJob(ID, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunJob", reflect.TypeOf((*MockScheduler)(nil).RunJob), ID, w)
+}
+
+// TriggerJob mocks base method.
+func (m *MockScheduler) TriggerJob(ID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TriggerJob", ID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TriggerJob indicates an expected call of TriggerJob.
+func (mr *MockSchedulerMockRecorder) TriggerJob(ID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TriggerJob", reflect.TypeOf((*MockScheduler)(nil).TriggerJob), ID)
+}
+
 // MockDockerClient is a mock of DockerClient interface.
 type MockDockerClient struct {
 	ctrl     *gomock.Controller
@@ -263,6 +487,21 @@ func (mr *MockDockerClientMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockDockerClient)(nil).Close))
 }
 
+// ContainerCreate mocks base method.
+func (m *MockDockerClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *v1.Platform, containerName string) (container.CreateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContainerCreate", ctx, config, hostConfig, networkingConfig, platform, containerName)
+	ret0, _ := ret[0].(container.CreateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ContainerCreate indicates an expected call of ContainerCreate.
+func (mr *MockDockerClientMockRecorder) ContainerCreate(ctx, config, hostConfig, networkingConfig, platform, containerName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerCreate", reflect.TypeOf((*MockDockerClient)(nil).ContainerCreate), ctx, config, hostConfig, networkingConfig, platform, containerName)
+}
+
 // ContainerExecAttach mocks base method.
 func (m *MockDockerClient) ContainerExecAttach(ctx context.Context, execID string, config container.ExecStartOptions) (types.HijackedResponse, error) {
 	m.ctrl.T.Helper()
@@ -338,6 +577,35 @@ func (mr *MockDockerClientMockRecorder) ContainerList(ctx, options interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerList", reflect.TypeOf((*MockDockerClient)(nil).ContainerList), ctx, options)
 }
 
+// ContainerLogs mocks base method.
+func (m *MockDockerClient) ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContainerLogs", ctx, containerID, options)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ContainerLogs indicates an expected call of ContainerLogs.
+func (mr *MockDockerClientMockRecorder) ContainerLogs(ctx, containerID, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerLogs", reflect.TypeOf((*MockDockerClient)(nil).ContainerLogs), ctx, containerID, options)
+}
+
+// ContainerRemove mocks base method.
+func (m *MockDockerClient) ContainerRemove(ctx context.Context, containerID string, options container.RemoveOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContainerRemove", ctx, containerID, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ContainerRemove indicates an expected call of ContainerRemove.
+func (mr *MockDockerClientMockRecorder) ContainerRemove(ctx, containerID, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerRemove", reflect.TypeOf((*MockDockerClient)(nil).ContainerRemove), ctx, containerID, options)
+}
+
 // ContainerRestart mocks base method.
 func (m *MockDockerClient) ContainerRestart(ctx context.Context, container string, options container.StopOptions) error {
 	m.ctrl.T.Helper()
@@ -380,6 +648,21 @@ func (mr *MockDockerClientMockRecorder) ContainerStop(ctx, container, timeout in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerStop", reflect.TypeOf((*MockDockerClient)(nil).ContainerStop), ctx, container, timeout)
 }
 
+// ContainerWait mocks base method.
+func (m *MockDockerClient) ContainerWait(ctx context.Context, containerID string, condition container.WaitCondition) (<-chan container.WaitResponse, <-chan error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContainerWait", ctx, containerID, condition)
+	ret0, _ := ret[0].(<-chan container.WaitResponse)
+	ret1, _ := ret[1].(<-chan error)
+	return ret0, ret1
+}
+
+// ContainerWait indicates an expected call of ContainerWait.
+func (mr *MockDockerClientMockRecorder) ContainerWait(ctx, containerID, condition interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerWait", reflect.TypeOf((*MockDockerClient)(nil).ContainerWait), ctx, containerID, condition)
+}
+
 // Events mocks base method.
 func (m *MockDockerClient) Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error) {
 	m.ctrl.T.Helper()
@@ -395,6 +678,52 @@ func (mr *MockDockerClientMockRecorder) Events(ctx, options interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Events", reflect.TypeOf((*MockDockerClient)(nil).Events), ctx, options)
 }
 
+// ImagePull mocks base method.
+func (m *MockDockerClient) ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImagePull", ctx, refStr, options)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImagePull indicates an expected call of ImagePull.
+func (mr *MockDockerClientMockRecorder) ImagePull(ctx, refStr, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImagePull", reflect.TypeOf((*MockDockerClient)(nil).ImagePull), ctx, refStr, options)
+}
+
+// Info mocks base method.
+func (m *MockDockerClient) Info(ctx context.Context) (system.Info, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Info", ctx)
+	ret0, _ := ret[0].(system.Info)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Info indicates an expected call of Info.
+func (mr *MockDockerClientMockRecorder) Info(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*MockDockerClient)(nil).Info), ctx)
+}
+
+// ServiceInspectWithRaw mocks base method.
+func (m *MockDockerClient) ServiceInspectWithRaw(ctx context.Context, serviceID string, options types.ServiceInspectOptions) (swarm.Service, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ServiceInspectWithRaw", ctx, serviceID, options)
+	ret0, _ := ret[0].(swarm.Service)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ServiceInspectWithRaw indicates an expected call of ServiceInspectWithRaw.
+func (mr *MockDockerClientMockRecorder) ServiceInspectWithRaw(ctx, serviceID, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServiceInspectWithRaw", reflect.TypeOf((*MockDockerClient)(nil).ServiceInspectWithRaw), ctx, serviceID, options)
+}
+
 // ServiceList mocks base method.
 func (m *MockDockerClient) ServiceList(ctx context.Context, options types.ServiceListOptions) ([]swarm.Service, error) {
 	m.ctrl.T.Helper()
@@ -424,3 +753,18 @@ func (mr *MockDockerClientMockRecorder) ServiceUpdate(ctx, serviceID, version, s
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServiceUpdate", reflect.TypeOf((*MockDockerClient)(nil).ServiceUpdate), ctx, serviceID, version, service, options)
 }
+
+// TaskList mocks base method.
+func (m *MockDockerClient) TaskList(ctx context.Context, options types.TaskListOptions) ([]swarm.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TaskList", ctx, options)
+	ret0, _ := ret[0].([]swarm.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TaskList indicates an expected call of TaskList.
+func (mr *MockDockerClientMockRecorder) TaskList(ctx, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TaskList", reflect.TypeOf((*MockDockerClient)(nil).TaskList), ctx, options)
+}
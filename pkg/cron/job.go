@@ -1,32 +1,89 @@
 package cron
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 )
 
-// Job run a command with specified args on a schedule.
+// Job run a command with specified args on a schedule. With the 'run' action,
+// the command is run in a new container created from Image. Name is the name
+// of the job, by default its command.
 type Job struct {
-	Schedule string   `json:"schedule"`
-	Command  string   `json:"command"`
-	Args     []string `json:"args"`
-	cron     *Cron
+	Name        string            `json:"name"`
+	Schedule    string            `json:"schedule"`
+	Action      string            `json:"action"`
+	Command     string            `json:"command"`
+	Args        []string          `json:"args"`
+	Timeout     string            `json:"timeout"`
+	Concurrency string            `json:"concurrency"`
+	Env         map[string]string `json:"env"`
+	Stdin       string            `json:"stdin"`
+	User        string            `json:"user"`
+	Image       string            `json:"image"`
+	Mounts      []string          `json:"mounts"`
+	Network     string            `json:"network"`
+	AutoRemove  bool              `json:"autoremove"`
+	Catchup     string            `json:"catchup"`
+	Retry       Retry             `json:"retry"`
+	Notify      Notify            `json:"notify"`
+	Ping        Ping              `json:"ping"`
+	MailTo      string            `json:"mailto"`
+	MailOn      string            `json:"mailon"`
+	raw         bool
+	cron        *Cron
+	guard       *guard
 }
 
+// killGracePeriod is the delay between SIGTERM and SIGKILL sent to the
+// processes of a job canceled or timed out.
+var killGracePeriod = 10 * time.Second
+
 // Run a Job and log the output.
 func (j *Job) Run() {
+	j.runWith(nil)
+}
+
+// runWith run the job like the scheduler, streaming its output to w when
+// set, and return its error.
+func (j *Job) runWith(w io.Writer) error {
 	log := log.WithFields(log.Fields{
 		"func":     "Job.Run",
+		"name":     j.labels()["name"],
 		"schedule": j.Schedule,
+		"action":   j.Action,
 		"command":  j.Command,
 		"args":     strings.Join(j.Args, " "),
+		"timeout":  j.Timeout,
 	})
 
 	j.cron.sync.Add(1)
+	defer j.cron.sync.Done()
+
+	ctx, release, ok := j.guard.acquire(log)
+	if !ok {
+		return ErrJobSkipped
+	}
+	defer release()
+
+	jobsRunning.Inc()
+	defer jobsRunning.Dec()
+	start := time.Now()
+	j.Ping.start(log)
 
 	// Secret mapping
 	secretMapper := func(key string) string {
@@ -43,18 +100,258 @@ func (j *Job) Run() {
 		return env
 	}
 
-	// Expand env in all args
-	args := make([]string, len(j.Args))
-	for i, arg := range j.Args {
-		args[i] = os.Expand(arg, secretMapper)
+	var timedOut bool
+	out, attempt, err := j.Retry.do(ctx, log, func(ctx context.Context) (string, error) {
+		var out string
+		var err error
+		out, timedOut, err = j.attempt(ctx, secretMapper, w)
+		return out, err
+	})
+	log = log.WithField("output", out)
+	if j.Retry.Attempts > 1 {
+		log = log.WithField("attempt", attempt)
+	}
+	if timedOut {
+		log.WithError(err).Errorln("job timed out")
+	} else if err != nil {
+		log.WithError(err).Errorln("job completed with error")
+	} else {
+		log.Infoln("job completed successfully")
 	}
 
-	cmd := exec.Command(os.Expand(j.Command, secretMapper), args...)
-	if out, err := cmd.CombinedOutput(); err != nil {
-		log.WithField("output", string(out)).WithError(err).Errorln("job completed with error")
+	j.Ping.finish(log, out, err)
+	j.cron.record(j, start, out, err)
+	return err
+}
+
+// attempt run the job once, within its timeout, copying its output to w when
+// set.
+func (j *Job) attempt(ctx context.Context, mapping func(string) string, w io.Writer) (string, bool, error) {
+	if j.Timeout != "" {
+		timeout, _ := time.ParseDuration(j.Timeout)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var out []byte
+	var err error
+	if j.Action == "run" {
+		var logs string
+		logs, err = j.run(ctx, mapping, w)
+		out = []byte(logs)
 	} else {
-		log.WithField("output", string(out)).Infoln("job completed successfully")
+		var cmd *exec.Cmd
+		var stopKill func()
+		if cmd, stopKill, err = j.command(ctx, mapping); err == nil {
+			if w == nil {
+				out, err = cmd.CombinedOutput()
+			} else {
+				var buf bytes.Buffer
+				cmd.Stdout = io.MultiWriter(&buf, w)
+				cmd.Stderr = cmd.Stdout
+				err = cmd.Run()
+				out = buf.Bytes()
+			}
+			stopKill()
+		}
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return string(out), true, errors.Errorf("timed out after %s", j.Timeout)
+	}
+	return string(out), false, err
+}
+
+// command build the process of the job. stopKill must be called once the
+// process is waited, so the SIGKILL of a canceled process group is not sent
+// to a process group reusing its ID.
+func (j *Job) command(ctx context.Context, mapping func(string) string) (cmd *exec.Cmd, stopKill func(), err error) {
+	name, args := j.expand(mapping)
+	cmd = exec.CommandContext(ctx, name, args...)
+
+	// Run the command in its own process group so that all its children are
+	// terminated with it. Cancel is called before Wait returns, so kill is
+	// set when stopKill is called.
+	var kill *time.Timer
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		pgid := -cmd.Process.Pid
+		kill = time.AfterFunc(killGracePeriod, func() {
+			syscall.Kill(pgid, syscall.SIGKILL)
+		})
+		return syscall.Kill(pgid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = killGracePeriod
+	stopKill = func() {
+		if kill != nil {
+			kill.Stop()
+		}
+	}
+
+	env := os.Environ()
+	if j.User != "" {
+		u, err := lookupUser(j.User)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to find job user")
+		}
+		uid, _ := strconv.ParseUint(u.Uid, 10, 32)
+		gid, _ := strconv.ParseUint(u.Gid, 10, 32)
+		cmd.SysProcAttr.Credential = &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}
+		env = append(env, "USER="+u.Username, "LOGNAME="+u.Username, "HOME="+u.HomeDir)
+	}
+	for k, v := range j.Env {
+		env = append(env, k+"="+v)
+	}
+	cmd.Env = env
+
+	if j.Stdin != "" {
+		cmd.Stdin = strings.NewReader(j.Stdin)
+	}
+	return cmd, stopKill, nil
+}
+
+// run the job in a new container and return its logs, streamed to w when set.
+func (j *Job) run(ctx context.Context, mapping func(string) string, w io.Writer) (string, error) {
+	cli, err := newDockerClient()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create docker client")
+	}
+	defer cli.Close()
+
+	opts := runOptions{
+		Image:      j.Image,
+		Env:        envList(j.Env),
+		Mounts:     j.Mounts,
+		Network:    j.Network,
+		AutoRemove: j.AutoRemove,
+	}
+	if j.Command != "" {
+		name, args := j.expand(mapping)
+		opts.Cmd = append([]string{name}, args...)
+	}
+	return runContainer(ctx, cli, opts, w)
+}
+
+// expand env in command and args, unless the job comes from a crontab.
+func (j *Job) expand(mapping func(string) string) (string, []string) {
+	name := j.Command
+	args := make([]string, len(j.Args))
+	copy(args, j.Args)
+	if !j.raw {
+		name = os.Expand(name, mapping)
+		for i, arg := range args {
+			args[i] = os.Expand(arg, mapping)
+		}
+	}
+	return name, args
+}
+
+// validate check the fields of the job.
+func (j *Job) validate() error {
+	if err := validateName(j.Name); err != nil {
+		return err
+	}
+
+	if j.Schedule == "" {
+		return errors.New("schedule is required")
+	}
+
+	switch j.Action {
+	case "":
+		if j.Command == "" {
+			return errors.New("command is required")
+		}
+	case "run":
+		if j.Image == "" {
+			return errors.New("image is required")
+		}
+	default:
+		return errors.New("invalid job action, only 'run' is permitted")
+	}
+
+	if j.Timeout != "" {
+		if d, err := time.ParseDuration(j.Timeout); err != nil || d <= 0 {
+			return errors.New("invalid job timeout, only positive duration like '30s' or '5m' are permitted")
+		}
+	}
+
+	if err := validateConcurrency(j.Concurrency); err != nil {
+		return err
+	}
+
+	if err := validateCatchup(j.Catchup); err != nil {
+		return err
+	}
+
+	if err := j.Retry.validate(); err != nil {
+		return err
+	}
+
+	if err := j.Notify.validate(); err != nil {
+		return err
+	}
+
+	if err := j.Ping.validate(); err != nil {
+		return err
 	}
 
-	j.cron.sync.Done()
+	if err := validateMailTo(j.MailTo); err != nil {
+		return err
+	}
+
+	if err := validateMailOn(j.MailOn); err != nil {
+		return err
+	}
+	return nil
+}
+
+// labels identify the job in metrics.
+func (j *Job) labels() prometheus.Labels {
+	name := j.Name
+	if name == "" {
+		name = j.commandLine()
+	}
+	return prometheus.Labels{"name": name, "source": "file", "container": "", "service": ""}
+}
+
+// commandLine return the command of the job with its args, or the command line
+// run by the shell for a job of a crontab.
+func (j *Job) commandLine() string {
+	if j.raw && len(j.Args) == 2 {
+		return j.Args[1]
+	}
+	return strings.Join(append([]string{j.Command}, j.Args...), " ")
+}
+
+// id identify the job across restarts by the config entry that declare it.
+func (j *Job) id() string {
+	data, _ := json.Marshal([]interface{}{j.Schedule, j.Action, j.Command, j.Args, j.Image})
+	return fmt.Sprintf("file/%x", sha256.Sum256(data))[:17]
+}
+
+// scheduleSpec return the schedule of the job.
+func (j *Job) scheduleSpec() string {
+	return j.Schedule
+}
+
+// catchupPolicy return the policy applied to the runs missed by the job.
+func (j *Job) catchupPolicy() string {
+	return j.Catchup
+}
+
+// notifyPolicy return the webhooks notified of the runs of the job.
+func (j *Job) notifyPolicy() Notify {
+	return j.Notify
+}
+
+// mailPolicy return the addresses and the condition of the mails of the runs
+// of the job.
+func (j *Job) mailPolicy() (string, string) {
+	return j.MailTo, j.MailOn
+}
+
+// key identify a job by its configuration.
+func (j *Job) key() string {
+	data, _ := json.Marshal(j)
+	return string(data)
 }
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/docker/docker/api/types/container"
 	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"gotest.tools/v3/assert"
@@ -35,6 +38,12 @@ func TestJobRun(t *testing.T) {
 		name           string
 		command        string
 		args           []string
+		timeout        string
+		jobEnv         map[string]string
+		stdin          string
+		user           string
+		raw            bool
+		retry          Retry
 		envs           map[string]string
 		secret         string
 		secretFilename string
@@ -154,6 +163,121 @@ func TestJobRun(t *testing.T) {
 				hasOutput("job completed successfully"),
 			),
 		},
+		{
+			name:    "completed before timeout",
+			command: "echo",
+			args:    []string{"hello bob"},
+			timeout: "5s",
+			mock: func(s *MockJobSynchroniser) {
+				s.EXPECT().Add(1)
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasOutput("hello bob"),
+				hasOutput("job completed successfully"),
+			),
+		},
+		{
+			name:    "timed out with partial output",
+			command: "sh",
+			args:    []string{"-c", "echo partial; sleep 5"},
+			timeout: "100ms",
+			mock: func(s *MockJobSynchroniser) {
+				s.EXPECT().Add(1)
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasOutput("partial"),
+				hasOutput("job timed out"),
+				hasOutput("timed out after 100ms"),
+				hasNotOutput("job completed successfully"),
+			),
+		},
+		{
+			name:    "timed out and killed when SIGTERM is ignored",
+			command: "sh",
+			args:    []string{"-c", "trap '' TERM; echo partial; sleep 5; echo never"},
+			timeout: "100ms",
+			mock: func(s *MockJobSynchroniser) {
+				s.EXPECT().Add(1)
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasOutput("partial"),
+				hasOutput("job timed out"),
+				hasNotOutput("partial\\nnever"),
+			),
+		},
+		{
+			name:    "job env variable",
+			command: "sh",
+			args:    []string{"-c", "echo hello $NAME"},
+			jobEnv:  map[string]string{"NAME": "alice"},
+			raw:     true,
+			mock: func(s *MockJobSynchroniser) {
+				s.EXPECT().Add(1)
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasOutput("hello alice"),
+				hasOutput("job completed successfully"),
+			),
+		},
+		{
+			name:    "raw args not expanded",
+			command: "sh",
+			args:    []string{"-c", "echo ${NAME:-default}"},
+			raw:     true,
+			mock: func(s *MockJobSynchroniser) {
+				s.EXPECT().Add(1)
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasOutput("default"),
+				hasOutput("job completed successfully"),
+			),
+		},
+		{
+			name:    "standard input",
+			command: "cat",
+			stdin:   "line1\nline2\n",
+			mock: func(s *MockJobSynchroniser) {
+				s.EXPECT().Add(1)
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasOutput("line1\\nline2"),
+				hasOutput("job completed successfully"),
+			),
+		},
+		{
+			name:    "unknown user",
+			command: "echo",
+			user:    "unknown-mobycron-user",
+			mock: func(s *MockJobSynchroniser) {
+				s.EXPECT().Add(1)
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasOutput("failed to find job user"),
+				hasOutput("job completed with error"),
+			),
+		},
+		{
+			name:    "retry failed job",
+			command: "sh",
+			args:    []string{"-c", "echo try; exit 3"},
+			retry:   Retry{Attempts: 2, Delay: "1ms"},
+			mock: func(s *MockJobSynchroniser) {
+				s.EXPECT().Add(1)
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasOutput("job attempt failed, retry after delay"),
+				hasOutput("attempt"),
+				hasOutput("job completed with error"),
+			),
+		},
 		{
 			name:    "invalid command",
 			command: "invalid command",
@@ -196,16 +320,111 @@ func TestJobRun(t *testing.T) {
 				tt.mock(s)
 			}
 
-			c := &Cron{nil, s, fs, nil, nil}
-			j := &Job{"3 * * * * *", tt.command, tt.args, c}
+			c := &Cron{sync: s, fs: fs}
+			j := &Job{
+				Schedule: "3 * * * * *",
+				Command:  tt.command,
+				Args:     tt.args,
+				Timeout:  tt.timeout,
+				Env:      tt.jobEnv,
+				Stdin:    tt.stdin,
+				User:     tt.user,
+				raw:      tt.raw,
+				Retry:    tt.retry,
+				cron:     c,
+			}
+
+			// Kill quickly the timed out jobs
+			defer func(d time.Duration) { killGracePeriod = d }(killGracePeriod)
+			killGracePeriod = 100 * time.Millisecond
 
 			// Act
+			start := time.Now()
 			j.Run()
 
 			// Assert
+			assert.Assert(t, time.Since(start) < 2*time.Second)
 			for _, check := range tt.checks {
 				check(t, out.String())
 			}
 		})
 	}
 }
+
+func TestJobRunContainer(t *testing.T) {
+	// Arrange
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	s := NewMockJobSynchroniser(ctrl)
+	cli := NewMockDockerClient(ctrl)
+
+	defer func(f func() (DockerClient, error)) { newDockerClient = f }(newDockerClient)
+	newDockerClient = func() (DockerClient, error) { return cli, nil }
+
+	config := &container.Config{
+		Image:  "alpine",
+		Cmd:    []string{"echo", "hello bob"},
+		Env:    []string{"NAME=bob"},
+		Labels: map[string]string{"mobycron.run": "true"},
+	}
+	hostConfig := &container.HostConfig{Binds: []string{"/data:/data"}, NetworkMode: "backend"}
+
+	s.EXPECT().Add(1)
+	cli.EXPECT().ContainerCreate(gomock.Any(), config, hostConfig, gomock.Any(), gomock.Any(), "").Return(container.CreateResponse{ID: "run1"}, nil)
+	cli.EXPECT().ContainerWait(gomock.Any(), "run1", gomock.Any()).Return(waitResult(container.WaitResponse{}, nil))
+	cli.EXPECT().ContainerStart(gomock.Any(), "run1", gomock.Any())
+	cli.EXPECT().ContainerLogs(gomock.Any(), "run1", gomock.Any()).Return(logStream("hello bob\n", ""), nil)
+	cli.EXPECT().ContainerRemove(gomock.Any(), "run1", gomock.Any())
+	cli.EXPECT().Close()
+	s.EXPECT().Done()
+
+	j := &Job{
+		Schedule:   "* * * * *",
+		Action:     "run",
+		Command:    "echo",
+		Args:       []string{"hello $NAME"},
+		Env:        map[string]string{"NAME": "bob"},
+		Image:      "alpine",
+		Mounts:     []string{"/data:/data"},
+		Network:    "backend",
+		AutoRemove: true,
+		cron:       &Cron{sync: s, fs: afero.NewMemMapFs()},
+	}
+	f := env.Patch(t, "NAME", "bob")
+	defer f()
+
+	// Act
+	j.Run()
+
+	// Assert
+	assert.Assert(t, is.Contains(out.String(), "hello bob"))
+	assert.Assert(t, is.Contains(out.String(), "job completed successfully"))
+}
+
+func TestJobRunContainerClientError(t *testing.T) {
+	// Arrange
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	s := NewMockJobSynchroniser(ctrl)
+
+	defer func(f func() (DockerClient, error)) { newDockerClient = f }(newDockerClient)
+	newDockerClient = func() (DockerClient, error) { return nil, errors.New("no docker") }
+
+	s.EXPECT().Add(1)
+	s.EXPECT().Done()
+
+	j := &Job{Schedule: "* * * * *", Action: "run", Image: "alpine", cron: &Cron{sync: s}}
+
+	// Act
+	j.Run()
+
+	// Assert
+	assert.Assert(t, is.Contains(out.String(), "failed to create docker client: no docker"))
+	assert.Assert(t, is.Contains(out.String(), "job completed with error"))
+}
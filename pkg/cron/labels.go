@@ -0,0 +1,46 @@
+package cron
+
+import (
+	"sort"
+	"strings"
+)
+
+// labelPrefix is the prefix of all docker labels read by mobycron.
+const labelPrefix = "mobycron."
+
+// labelJob is a job declared with docker labels. The unnamed job use labels
+// like 'mobycron.schedule' and a named job use labels like
+// 'mobycron.<name>.schedule'.
+type labelJob struct {
+	name   string
+	labels map[string]string
+}
+
+// get return the value of the label key of the job.
+func (l labelJob) get(key string) string {
+	if l.name == "" {
+		return l.labels[labelPrefix+key]
+	}
+	return l.labels[labelPrefix+l.name+"."+key]
+}
+
+// labelJobs return all jobs declared in labels, sorted by name. A job is
+// declared by its schedule label.
+func labelJobs(labels map[string]string) []labelJob {
+	jobs := []labelJob{}
+	for k := range labels {
+		if !strings.HasPrefix(k, labelPrefix) || !strings.HasSuffix(k, ".schedule") {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(k, labelPrefix), "schedule")
+		if name != "" {
+			name = strings.TrimSuffix(name, ".")
+			if name == "" || strings.Contains(name, ".") {
+				continue
+			}
+		}
+		jobs = append(jobs, labelJob{name: name, labels: labels})
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].name < jobs[k].name })
+	return jobs
+}
@@ -0,0 +1,72 @@
+package cron
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestLabelJobs(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   []string
+	}{
+		{
+			name:   "no label",
+			labels: map[string]string{"other": "value"},
+			want:   []string{},
+		},
+		{
+			name:   "unnamed job",
+			labels: map[string]string{"mobycron.schedule": "* * * * *", "mobycron.action": "start"},
+			want:   []string{""},
+		},
+		{
+			name: "named jobs",
+			labels: map[string]string{
+				"mobycron.schedule":         "* * * * *",
+				"mobycron.nightly.schedule": "0 2 * * *",
+				"mobycron.dump.schedule":    "0 * * * *",
+				"mobycron.dump.action":      "exec",
+			},
+			want: []string{"", "dump", "nightly"},
+		},
+		{
+			name: "invalid names",
+			labels: map[string]string{
+				"mobycron..schedule":    "* * * * *",
+				"mobycron.a.b.schedule": "* * * * *",
+				"mobycron.xschedule":    "* * * * *",
+				"other.schedule":        "* * * * *",
+			},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			jobs := labelJobs(tt.labels)
+
+			// Assert
+			names := []string{}
+			for _, j := range jobs {
+				names = append(names, j.name)
+			}
+			assert.Assert(t, is.DeepEqual(names, tt.want))
+		})
+	}
+}
+
+func TestLabelJobGet(t *testing.T) {
+	labels := map[string]string{
+		"mobycron.action":      "start",
+		"mobycron.dump.action": "exec",
+	}
+
+	assert.Equal(t, labelJob{labels: labels}.get("action"), "start")
+	assert.Equal(t, labelJob{name: "dump", labels: labels}.get("action"), "exec")
+	assert.Equal(t, labelJob{name: "dump", labels: labels}.get("command"), "")
+}
@@ -0,0 +1,318 @@
+package cron
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Conditions on the outcome of a run to send a mail.
+const (
+	MailOutput  = "output"
+	MailFailure = "failure"
+	MailAlways  = "always"
+	MailNever   = "never"
+)
+
+const (
+	// mailOutput is the maximum size of the output sent in a mail.
+	mailOutput = 64 * 1024
+
+	// mailQueue is the number of mails waiting to be sent before new ones
+	// are dropped.
+	mailQueue = 100
+
+	defaultMailSubject  = `mobycron: {{.Job}} {{if eq .Status "failure"}}failed{{else}}succeeded{{end}}`
+	defaultMailTemplate = `Job: {{.Job}}
+Schedule: {{.Schedule}}
+{{- if .Container}}
+Container: {{.Container}}
+{{- end}}
+{{- if .Service}}
+Service: {{.Service}}
+{{- end}}
+Status: {{.Status}}
+Exit code: {{.ExitCode}}
+Start: {{.Start.Format "2006-01-02T15:04:05Z07:00"}}
+Duration: {{.Duration}}
+{{- if .Error}}
+Error: {{.Error}}
+{{- end}}
+{{if .Output}}
+{{.Output}}
+{{- end}}
+`
+)
+
+// mailTimeout is the maximum duration of the delivery of a mail.
+var mailTimeout = 30 * time.Second
+
+// SMTP configure the server where the mails of job runs are sent and the
+// defaults of the jobs. To is a comma separated list of addresses and
+// Subject and Template are Go templates executed with the Notification.
+type SMTP struct {
+	Host     string
+	Port     int
+	StartTLS bool
+	Username string
+	Password string
+	From     string
+	To       string
+	On       string
+	Subject  string
+	Template string
+}
+
+type message struct {
+	to   []string
+	data []byte
+	job  string
+}
+
+// Mailer send the output of job runs by mail in the background, like the
+// MAILTO of a classic cron.
+type Mailer struct {
+	config   SMTP
+	subject  *template.Template
+	template *template.Template
+	queue    chan message
+	done     chan struct{}
+	closed   bool
+	mu       sync.Mutex
+}
+
+func validateMailOn(on string) error {
+	switch on {
+	case "", MailOutput, MailFailure, MailAlways, MailNever:
+		return nil
+	default:
+		return errors.New("invalid mail condition, only 'output', 'failure', 'always' and 'never' are permitted")
+	}
+}
+
+func validateMailTo(to string) error {
+	if to == "" {
+		return nil
+	}
+	if _, err := mail.ParseAddressList(to); err != nil {
+		return errors.Errorf("invalid mail address '%s'", to)
+	}
+	return nil
+}
+
+// NewMailer returns a mailer sending the mails with config, and start
+// sending them.
+func NewMailer(config SMTP) (*Mailer, error) {
+	if config.Host == "" {
+		return nil, errors.New("smtp host is required to send mails")
+	}
+	if config.Port == 0 {
+		config.Port = 25
+	}
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return nil, errors.Errorf("invalid mail from address '%s'", config.From)
+	}
+	if err := validateMailTo(config.To); err != nil {
+		return nil, err
+	}
+	if err := validateMailOn(config.On); err != nil {
+		return nil, err
+	}
+	if config.Subject == "" {
+		config.Subject = defaultMailSubject
+	}
+	if config.Template == "" {
+		config.Template = defaultMailTemplate
+	}
+
+	subject, err := template.New("subject").Funcs(notifyFuncs).Parse(config.Subject)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid mail subject template")
+	}
+	body, err := template.New("body").Funcs(notifyFuncs).Parse(config.Template)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid mail template")
+	}
+
+	m := &Mailer{
+		config:   config,
+		subject:  subject,
+		template: body,
+		queue:    make(chan message, mailQueue),
+		done:     make(chan struct{}),
+	}
+	go m.send()
+	return m, nil
+}
+
+// SetMailer set the mailer of the runs of all jobs.
+func (c *Cron) SetMailer(m *Mailer) {
+	c.mailer = m
+}
+
+// matchMail tell if a run must be mailed with the condition on.
+func matchMail(on string, run Run) bool {
+	switch on {
+	case MailAlways:
+		return true
+	case MailFailure:
+		return run.Error != ""
+	case MailNever:
+		return false
+	default:
+		return run.Output != ""
+	}
+}
+
+// mail queue the mail of a run of job, without waiting for its delivery.
+func (m *Mailer) mail(job scheduledJob, run Run) {
+	to, on := job.mailPolicy()
+	if to == "" {
+		to = m.config.To
+	}
+	if on == "" {
+		on = m.config.On
+	}
+	if to == "" || !matchMail(on, run) {
+		return
+	}
+
+	log := log.WithFields(log.Fields{
+		"func": "Mailer.mail",
+		"job":  run.Job,
+	})
+
+	addresses, _ := mail.ParseAddressList(to)
+	msg := message{job: run.Job}
+	for _, a := range addresses {
+		msg.to = append(msg.to, a.Address)
+	}
+
+	data, err := m.message(to, newNotification(job, run, mailOutput))
+	if err != nil {
+		log.WithError(err).Errorln("failed to build mail")
+		return
+	}
+	msg.data = data
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		log.Errorln("mail dropped, mailer is closed")
+		return
+	}
+	select {
+	case m.queue <- msg:
+	default:
+		log.Errorln("mail dropped, too many mails waiting")
+	}
+}
+
+// close stop accepting mails and wait for the queued ones to be sent.
+func (m *Mailer) close() {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.queue)
+	}
+	m.mu.Unlock()
+	<-m.done
+}
+
+// message render the headers and body of the mail of a notification.
+func (m *Mailer) message(to string, n Notification) ([]byte, error) {
+	var subject, body bytes.Buffer
+	if err := m.subject.Execute(&subject, n); err != nil {
+		return nil, errors.Wrap(err, "failed to execute mail subject template")
+	}
+	if err := m.template.Execute(&body, n); err != nil {
+		return nil, errors.Wrap(err, "failed to execute mail template")
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", m.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject.String()))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(&msg, "\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// send deliver the queued mails, one after the other.
+func (m *Mailer) send() {
+	defer close(m.done)
+	for msg := range m.queue {
+		log := log.WithFields(log.Fields{
+			"func": "Mailer.send",
+			"job":  msg.job,
+			"to":   msg.to,
+		})
+		if err := m.deliver(msg); err != nil {
+			log.WithError(err).Errorln("failed to send mail")
+			continue
+		}
+		log.Infoln("mail sent")
+	}
+}
+
+func (m *Mailer) deliver(msg message) error {
+	addr := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+	conn, err := net.DialTimeout("tcp", addr, mailTimeout)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to smtp server")
+	}
+	conn.SetDeadline(time.Now().Add(mailTimeout))
+
+	c, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return errors.Wrap(err, "failed to connect to smtp server")
+	}
+	defer c.Close()
+
+	if m.config.StartTLS {
+		if err := c.StartTLS(&tls.Config{ServerName: m.config.Host}); err != nil {
+			return errors.Wrap(err, "failed to start tls")
+		}
+	}
+	if m.config.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)); err != nil {
+			return errors.Wrap(err, "failed to authenticate to smtp server")
+		}
+	}
+
+	from, _ := mail.ParseAddress(m.config.From)
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, to := range msg.to {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg.data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
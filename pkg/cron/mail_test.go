@@ -0,0 +1,336 @@
+package cron
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	log "github.com/sirupsen/logrus"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// smtpMail is a mail received by the SMTP stand-in.
+type smtpMail struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// smtpServer is a local SMTP stand-in recording the mails received.
+type smtpServer struct {
+	net.Listener
+	mails chan smtpMail
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	s := &smtpServer{Listener: l, mails: make(chan smtpMail, 10)}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	tp := textproto.NewConn(conn)
+	defer tp.Close()
+
+	m := smtpMail{}
+	tp.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			tp.PrintfLine("250-localhost")
+			tp.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			m.auth = arg
+			tp.PrintfLine("235 authenticated")
+		case "MAIL":
+			m.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			tp.PrintfLine("250 ok")
+		case "RCPT":
+			m.to = append(m.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			tp.PrintfLine("250 ok")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			data, _ := tp.ReadDotBytes()
+			m.data = string(data)
+			tp.PrintfLine("250 ok")
+			s.mails <- m
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func (s *smtpServer) config() SMTP {
+	host, port, _ := net.SplitHostPort(s.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return SMTP{Host: host, Port: p, From: "mobycron <cron@example.com>"}
+}
+
+// next return the next mail received, or fail after a delay.
+func (s *smtpServer) next(t *testing.T) smtpMail {
+	select {
+	case m := <-s.mails:
+		return m
+	case <-time.After(2 * time.Second):
+		t.Fatal("mail not received")
+		return smtpMail{}
+	}
+}
+
+// none check that no mail is received.
+func (s *smtpServer) none(t *testing.T) {
+	select {
+	case m := <-s.mails:
+		t.Fatalf("unexpected mail: %s", m.data)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNewMailer(t *testing.T) {
+	valid := SMTP{Host: "smtp.local", From: "cron@example.com"}
+
+	tests := []struct {
+		name   string
+		config func(c SMTP) SMTP
+		err    string
+	}{
+		{name: "valid", config: func(c SMTP) SMTP { return c }},
+		{name: "no host", config: func(c SMTP) SMTP { c.Host = ""; return c }, err: "smtp host is required to send mails"},
+		{name: "invalid from", config: func(c SMTP) SMTP { c.From = "cron"; return c }, err: "invalid mail from address 'cron'"},
+		{name: "invalid to", config: func(c SMTP) SMTP { c.To = "ops,"; return c }, err: "invalid mail address 'ops,'"},
+		{name: "invalid on", config: func(c SMTP) SMTP { c.On = "sometimes"; return c }, err: "invalid mail condition, only 'output', 'failure', 'always' and 'never' are permitted"},
+		{name: "invalid subject", config: func(c SMTP) SMTP { c.Subject = "{{.Job"; return c }, err: "invalid mail subject template"},
+		{name: "invalid template", config: func(c SMTP) SMTP { c.Template = "{{.Job"; return c }, err: "invalid mail template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMailer(tt.config(valid))
+			if tt.err == "" {
+				assert.NilError(t, err)
+				assert.Equal(t, m.config.Port, 25)
+			} else {
+				assert.Assert(t, is.ErrorContains(err, tt.err))
+			}
+		})
+	}
+}
+
+func TestMatchMail(t *testing.T) {
+	assert.Assert(t, matchMail("", Run{Output: "hello"}))
+	assert.Assert(t, !matchMail("", Run{Error: "exit status 1"}))
+	assert.Assert(t, matchMail(MailOutput, Run{Output: "hello"}))
+	assert.Assert(t, matchMail(MailFailure, Run{Error: "exit status 1"}))
+	assert.Assert(t, !matchMail(MailFailure, Run{Output: "hello"}))
+	assert.Assert(t, matchMail(MailAlways, Run{}))
+	assert.Assert(t, !matchMail(MailNever, Run{Output: "hello", Error: "exit status 1"}))
+}
+
+func TestMailerMail(t *testing.T) {
+	tests := []struct {
+		name    string
+		to      string
+		on      string
+		mailTo  string
+		mailOn  string
+		run     Run
+		mailed  bool
+		rcpt    []string
+		headers []string
+		body    []string
+	}{
+		{
+			name: "no address",
+			run:  Run{Job: "backup", Output: "hello"},
+		},
+		{
+			name:    "output with default address",
+			to:      "ops@example.com",
+			run:     Run{Job: "backup", Source: "file", Output: "hello\n.dot line"},
+			mailed:  true,
+			rcpt:    []string{"ops@example.com"},
+			headers: []string{"From: mobycron <cron@example.com>", "To: ops@example.com", "Subject: mobycron: backup succeeded"},
+			body:    []string{"Job: backup", "Schedule: 0 2 * * *", "Status: success", "Exit code: 0", "hello\n.dot line"},
+		},
+		{
+			name: "no output",
+			to:   "ops@example.com",
+			run:  Run{Job: "backup"},
+		},
+		{
+			name:    "failure with job address",
+			to:      "ops@example.com",
+			mailTo:  "dba@example.com, Bob <bob@example.com>",
+			mailOn:  MailFailure,
+			run:     Run{Job: "backup", ExitCode: 2, Error: "exit status 2"},
+			mailed:  true,
+			rcpt:    []string{"dba@example.com", "bob@example.com"},
+			headers: []string{"To: dba@example.com, Bob <bob@example.com>", "Subject: mobycron: backup failed"},
+			body:    []string{"Status: failure", "Exit code: 2", "Error: exit status 2"},
+		},
+		{
+			name:   "success with failure condition",
+			to:     "ops@example.com",
+			on:     MailFailure,
+			run:    Run{Job: "backup", Output: "hello"},
+			mailed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := newSMTPServer(t)
+			defer s.Close()
+
+			config := s.config()
+			config.To = tt.to
+			config.On = tt.on
+			m, err := NewMailer(config)
+			assert.NilError(t, err)
+			job := &Job{Schedule: "0 2 * * *", Command: "backup.sh", MailTo: tt.mailTo, MailOn: tt.mailOn}
+
+			// Act
+			m.mail(job, tt.run)
+
+			// Assert
+			if !tt.mailed {
+				s.none(t)
+				return
+			}
+			mail := s.next(t)
+			assert.Equal(t, mail.from, "cron@example.com")
+			assert.Assert(t, is.DeepEqual(mail.to, tt.rcpt))
+			headers, body, _ := strings.Cut(mail.data, "\n\n")
+			for _, h := range tt.headers {
+				assert.Assert(t, is.Contains(headers, h))
+			}
+			for _, b := range tt.body {
+				assert.Assert(t, is.Contains(body, b))
+			}
+		})
+	}
+}
+
+func TestMailerTemplate(t *testing.T) {
+	// Arrange
+	s := newSMTPServer(t)
+	defer s.Close()
+
+	config := s.config()
+	config.To = "ops@example.com"
+	config.Username = "user"
+	config.Password = "secret"
+	config.Subject = "[{{.Container}}] {{.Job}} exited with {{.ExitCode}}"
+	config.Template = "{{.Output}}"
+	m, err := NewMailer(config)
+	assert.NilError(t, err)
+	job := &ContainerJob{Schedule: "* * * * *", Action: "exec", Container: container.Summary{Names: []string{"/db"}}}
+
+	// Act
+	m.mail(job, Run{Job: "db-exec", Output: strings.Repeat("a", mailOutput) + "end"})
+
+	// Assert
+	mail := s.next(t)
+	assert.Equal(t, mail.auth, "PLAIN "+base64.StdEncoding.EncodeToString([]byte("\x00user\x00secret")))
+	headers, body, _ := strings.Cut(mail.data, "\n\n")
+	body = strings.TrimSuffix(body, "\n")
+	assert.Assert(t, is.Contains(headers, "Subject: [db] db-exec exited with 0"))
+	assert.Equal(t, len(body), mailOutput)
+	assert.Assert(t, strings.HasSuffix(body, "end"))
+}
+
+func TestMailerUnreachable(t *testing.T) {
+	// Arrange
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+
+	s := newSMTPServer(t)
+	config := s.config()
+	s.Close()
+	config.To = "ops@example.com"
+	m, err := NewMailer(config)
+	assert.NilError(t, err)
+
+	// Act
+	m.mail(&Job{Schedule: "* * * * *", Command: "backup.sh"}, Run{Job: "backup", Output: "hello"})
+	m.close()
+
+	// Assert
+	assert.Assert(t, is.Contains(out.String(), "failed to send mail"))
+}
+
+func TestMailerClose(t *testing.T) {
+	// Arrange
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+
+	s := newSMTPServer(t)
+	defer s.Close()
+
+	config := s.config()
+	config.To = "ops@example.com"
+	m, err := NewMailer(config)
+	assert.NilError(t, err)
+	job := &Job{Schedule: "* * * * *", Command: "backup.sh"}
+	m.mail(job, Run{Job: "backup", Output: "hello"})
+
+	// Act
+	m.close()
+
+	// Assert
+	assert.Equal(t, len(s.mails), 1)
+
+	// Act
+	m.mail(job, Run{Job: "backup", Output: "hello"})
+
+	// Assert
+	assert.Assert(t, is.Contains(out.String(), "mail dropped, mailer is closed"))
+}
+
+func TestCronRecordMail(t *testing.T) {
+	// Arrange
+	s := newSMTPServer(t)
+	defer s.Close()
+
+	config := s.config()
+	config.On = MailAlways
+	m, err := NewMailer(config)
+	assert.NilError(t, err)
+	c := &Cron{mailer: m}
+	job := &ServiceJob{Schedule: "* * * * *", Action: "update", ServiceName: "web", MailTo: "web@example.com"}
+
+	// Act
+	c.record(job, time.Now(), "", nil)
+
+	// Assert
+	mail := s.next(t)
+	assert.Assert(t, is.DeepEqual(mail.to, []string{"web@example.com"}))
+	assert.Assert(t, is.Contains(mail.data, "Service: web"))
+}
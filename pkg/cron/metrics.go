@@ -0,0 +1,77 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+var jobLabels = []string{"name", "source", "container", "service"}
+
+// Registry holds all metrics exposed by mobycron.
+var Registry = prometheus.NewRegistry()
+
+var (
+	jobRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mobycron_job_runs_total",
+		Help: "Total number of job runs.",
+	}, jobLabels)
+
+	jobSuccesses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mobycron_job_successes_total",
+		Help: "Total number of job runs completed successfully.",
+	}, jobLabels)
+
+	jobFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mobycron_job_failures_total",
+		Help: "Total number of job runs completed with error.",
+	}, jobLabels)
+
+	jobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mobycron_job_duration_seconds",
+		Help:    "Duration of job runs in seconds.",
+		Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 900, 1800, 3600},
+	}, jobLabels)
+
+	jobLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mobycron_job_last_success_timestamp_seconds",
+		Help: "Unix timestamp of the last job run completed successfully.",
+	}, jobLabels)
+
+	jobsRunning = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "mobycron_jobs_running",
+		Help: "Number of jobs currently running.",
+	})
+
+	dockerEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mobycron_docker_events_total",
+		Help: "Total number of docker events processed.",
+	}, []string{"type", "action"})
+)
+
+func init() {
+	Registry.MustRegister(
+		jobRuns,
+		jobSuccesses,
+		jobFailures,
+		jobDuration,
+		jobLastSuccess,
+		jobsRunning,
+		dockerEvents,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// observeRun record the outcome of a job run started at start.
+func observeRun(labels prometheus.Labels, start time.Time, err error) {
+	jobRuns.With(labels).Inc()
+	jobDuration.With(labels).Observe(time.Since(start).Seconds())
+	if err != nil {
+		jobFailures.With(labels).Inc()
+		return
+	}
+	jobSuccesses.With(labels).Inc()
+	jobLastSuccess.With(labels).SetToCurrentTime()
+}
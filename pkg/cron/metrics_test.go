@@ -0,0 +1,109 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestObserveRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      prometheus.Labels
+		err         error
+		runs        float64
+		successes   float64
+		failures    float64
+		lastSuccess bool
+	}{
+		{
+			name:        "success",
+			labels:      prometheus.Labels{"name": "observe-success", "source": "file", "container": "", "service": ""},
+			runs:        1,
+			successes:   1,
+			lastSuccess: true,
+		},
+		{
+			name:     "failure",
+			labels:   prometheus.Labels{"name": "observe-failure", "source": "container", "container": "c1", "service": ""},
+			err:      errors.New("failure"),
+			runs:     1,
+			failures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			runs := testutil.ToFloat64(jobRuns.With(tt.labels))
+			successes := testutil.ToFloat64(jobSuccesses.With(tt.labels))
+			failures := testutil.ToFloat64(jobFailures.With(tt.labels))
+
+			// Act
+			observeRun(tt.labels, time.Now(), tt.err)
+
+			// Assert
+			assert.Assert(t, is.Equal(testutil.ToFloat64(jobRuns.With(tt.labels))-runs, tt.runs))
+			assert.Assert(t, is.Equal(testutil.ToFloat64(jobSuccesses.With(tt.labels))-successes, tt.successes))
+			assert.Assert(t, is.Equal(testutil.ToFloat64(jobFailures.With(tt.labels))-failures, tt.failures))
+			assert.Assert(t, is.Equal(testutil.ToFloat64(jobLastSuccess.With(tt.labels)) > 0, tt.lastSuccess))
+			assert.Assert(t, is.Equal(testutil.CollectAndCount(jobDuration, "mobycron_job_duration_seconds") > 0, true))
+		})
+	}
+}
+
+func TestJobLabels(t *testing.T) {
+	tests := []struct {
+		name string
+		job  interface{ labels() prometheus.Labels }
+		want prometheus.Labels
+	}{
+		{
+			name: "job",
+			job:  &Job{Command: "echo"},
+			want: prometheus.Labels{"name": "echo", "source": "file", "container": "", "service": ""},
+		},
+		{
+			name: "job with a name",
+			job:  &Job{Name: "backup", Command: "echo"},
+			want: prometheus.Labels{"name": "backup", "source": "file", "container": "", "service": ""},
+		},
+		{
+			name: "container job",
+			job:  &ContainerJob{Action: "start", Container: types.Container{ID: "id1", Names: []string{"/c1"}}},
+			want: prometheus.Labels{"name": "c1-start", "source": "container", "container": "c1", "service": ""},
+		},
+		{
+			name: "named container job",
+			job:  &ContainerJob{Label: "dump", Action: "exec", Container: types.Container{ID: "id1", Names: []string{"/c1"}}},
+			want: prometheus.Labels{"name": "c1-dump", "source": "container", "container": "c1", "service": ""},
+		},
+		{
+			name: "container job with a name",
+			job:  &ContainerJob{Name: "db-backup", Label: "dump", Action: "exec", Container: types.Container{ID: "id1", Names: []string{"/c1"}}},
+			want: prometheus.Labels{"name": "db-backup", "source": "container", "container": "c1", "service": ""},
+		},
+		{
+			name: "service job",
+			job:  &ServiceJob{Action: "update", ServiceName: "s1"},
+			want: prometheus.Labels{"name": "s1-update", "source": "service", "container": "", "service": "s1"},
+		},
+		{
+			name: "service job with a name",
+			job:  &ServiceJob{Name: "web-refresh", Action: "update", ServiceName: "s1"},
+			want: prometheus.Labels{"name": "web-refresh", "source": "service", "container": "", "service": "s1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Assert(t, is.DeepEqual(tt.job.labels(), tt.want))
+		})
+	}
+}
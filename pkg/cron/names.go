@@ -0,0 +1,79 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	cron "github.com/robfig/cron/v3"
+)
+
+// validateName check the name set on a job. A number is rejected since it
+// would be confused with the ID of a job.
+func validateName(name string) error {
+	if _, err := strconv.Atoi(name); err == nil {
+		return errors.Errorf("invalid job name '%s', a name cannot be a number", name)
+	}
+	return nil
+}
+
+// uniqueName return the name of a job added to the Cron. A name set on the
+// job must be unique, while a default name already used get a numeric
+// suffix. It must be called with c.mu held.
+func (c *Cron) uniqueName(name string, explicit bool) (string, error) {
+	if _, ok := c.names[name]; !ok {
+		return name, nil
+	}
+	if explicit {
+		return "", errors.Errorf("job name '%s' is already used by another job", name)
+	}
+	for i := 2; ; i++ {
+		n := fmt.Sprintf("%s-%d", name, i)
+		if _, ok := c.names[n]; !ok {
+			return n, nil
+		}
+	}
+}
+
+// reserveName register the name of the job with the entry ID. It must be
+// called with c.mu held.
+func (c *Cron) reserveName(name string, ID cron.EntryID) {
+	if c.names == nil {
+		c.names = make(map[string]cron.EntryID)
+	}
+	c.names[name] = ID
+}
+
+// releaseName unregister the name of the job with the entry ID and return
+// it. It must be called with c.mu held.
+func (c *Cron) releaseName(ID cron.EntryID) string {
+	for name, id := range c.names {
+		if id == ID {
+			delete(c.names, name)
+			return name
+		}
+	}
+	return ""
+}
+
+// removeEntry remove the job with the entry ID from the runner and return its
+// name. It must be called with c.mu held.
+func (c *Cron) removeEntry(ID cron.EntryID) string {
+	c.runner.Remove(ID)
+	return c.releaseName(ID)
+}
+
+// removeID remove the entry ID from the entries of a container or a service.
+func removeID(entries map[string][]cron.EntryID, ID cron.EntryID) {
+	for key, ids := range entries {
+		for i, id := range ids {
+			if id == ID {
+				entries[key] = append(ids[:i:i], ids[i+1:]...)
+				break
+			}
+		}
+		if len(entries[key]) == 0 {
+			delete(entries, key)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package cron
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/spf13/afero"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// names return the names of the jobs of c.
+func names(c *Cron) []string {
+	names := []string{}
+	for _, info := range c.Jobs() {
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+func TestValidateName(t *testing.T) {
+	assert.NilError(t, validateName(""))
+	assert.NilError(t, validateName("db-backup"))
+	assert.Error(t, validateName("42"), "invalid job name '42', a name cannot be a number")
+}
+
+func TestAddJobName(t *testing.T) {
+	// Arrange
+	c := NewCron(false)
+	db := container.Summary{ID: "ID1", Names: []string{"/db"}}
+
+	// Act
+	assert.NilError(t, c.AddJob(Job{Name: "backup", Schedule: "@daily", Command: "backup.sh"}))
+	assert.NilError(t, c.AddJob(Job{Schedule: "@daily", Command: "clean.sh"}))
+	assert.NilError(t, c.AddJob(Job{Schedule: "@hourly", Command: "clean.sh"}))
+	assert.NilError(t, c.AddContainerJob(ContainerJob{Schedule: "@daily", Action: "start", Container: db}))
+	assert.NilError(t, c.AddContainerJob(ContainerJob{Name: "db-dump", Label: "dump", Schedule: "@daily", Action: "exec", Command: "dump", Container: db}))
+	assert.NilError(t, c.AddServiceJob(ServiceJob{Name: "web-refresh", Schedule: "@daily", Action: "update", ServiceID: "S1", ServiceName: "web"}))
+	errFile := c.AddJob(Job{Name: "db-dump", Schedule: "@daily", Command: "dump.sh"})
+	errContainer := c.AddContainerJob(ContainerJob{Name: "backup", Label: "stop", Schedule: "@daily", Action: "stop", Container: db})
+	errService := c.AddServiceJob(ServiceJob{Name: "backup", Schedule: "@daily", Action: "update", ServiceID: "S2", ServiceName: "api"})
+	errNumber := c.AddJob(Job{Name: "12", Schedule: "@daily", Command: "dump.sh"})
+
+	// Assert
+	assert.Assert(t, is.DeepEqual(names(c), []string{"backup", "clean.sh", "clean.sh-2", "db-start", "db-dump", "web-refresh"}))
+	assert.Error(t, errFile, "job name 'db-dump' is already used by another job")
+	assert.Error(t, errContainer, "job name 'backup' is already used by another job")
+	assert.Error(t, errService, "job name 'backup' is already used by another job")
+	assert.Error(t, errNumber, "invalid job name '12', a name cannot be a number")
+}
+
+func TestAddJobNameRecreatedContainer(t *testing.T) {
+	// Arrange
+	c := NewCron(false)
+	labels := map[string]string{
+		"mobycron.dump.schedule": "@daily",
+		"mobycron.dump.name":     "db-dump",
+	}
+	old := container.Summary{ID: "0123456789abcdef", Names: []string{"/db"}, Labels: labels}
+	recreated := container.Summary{ID: "fedcba9876543210", Names: []string{"/0123456789ab_db"}, Labels: labels}
+	other := container.Summary{ID: "1111111111111111", Names: []string{"/cache"}, Labels: labels}
+	assert.NilError(t, c.AddContainerJob(ContainerJob{Schedule: "@daily", Action: "restart", Container: old}))
+	assert.NilError(t, c.AddContainerJob(ContainerJob{Name: "db-dump", Label: "dump", Schedule: "@daily", Action: "exec", Command: "dump", Container: old}))
+
+	// Act
+	errRestart := c.AddContainerJob(ContainerJob{Schedule: "@daily", Action: "restart", Container: recreated})
+	errDump := c.AddContainerJob(ContainerJob{Name: "db-dump", Label: "dump", Schedule: "@daily", Action: "exec", Command: "dump", Container: recreated})
+	errOther := c.AddContainerJob(ContainerJob{Name: "db-dump", Label: "dump", Schedule: "@daily", Action: "exec", Command: "dump", Container: other})
+	created := names(c)
+	c.RemoveContainerJob("0123456789abcdef")
+	c.RenameContainerJob("fedcba9876543210", "db")
+
+	// Assert
+	assert.NilError(t, errRestart)
+	assert.NilError(t, errDump)
+	assert.Error(t, errOther, "job name 'db-dump' is already used by another job")
+	assert.Assert(t, is.DeepEqual(created, []string{"db-restart", "0123456789ab_db-restart", "db-dump"}))
+	assert.Assert(t, is.DeepEqual(names(c), []string{"db-restart", "db-dump"}))
+	assert.Assert(t, is.DeepEqual(c.Containers(), map[string][]string{"fedcba9876543210": {"", "dump"}}))
+	for _, entry := range c.runner.Entries() {
+		j := entry.Job.(*ContainerJob)
+		assert.Assert(t, is.DeepEqual(j.Container.Names, []string{"/db"}))
+		assert.Equal(t, j.id(), "container/"+j.labels()["name"])
+	}
+}
+
+func TestRenameContainerJob(t *testing.T) {
+	// Arrange
+	c := NewCron(false)
+	db := container.Summary{ID: "ID1", Names: []string{"/db"}}
+	assert.NilError(t, c.AddContainerJob(ContainerJob{Schedule: "@daily", Action: "restart", Container: db}))
+	assert.NilError(t, c.AddContainerJob(ContainerJob{Name: "nightly", Label: "dump", Schedule: "@daily", Action: "exec", Command: "dump", Container: db}))
+	assert.NilError(t, c.AddJob(Job{Name: "db2-restart", Schedule: "@daily", Command: "restart.sh"}))
+	g := c.runner.Entry(2).Job.(*ContainerJob).guard
+	g.setPaused(true)
+
+	// Act
+	c.RenameContainerJob("ID1", "db2")
+	c.RenameContainerJob("unknown", "db3")
+
+	// Assert
+	assert.Assert(t, is.DeepEqual(names(c), []string{"db2-restart", "db2-restart-2", "nightly"}))
+	assert.Assert(t, is.Len(c.cEntries["ID1"], 2))
+	j := c.runner.Entry(c.names["nightly"]).Job.(*ContainerJob)
+	assert.Equal(t, j.guard, g)
+	assert.Assert(t, g.isPaused())
+}
+
+func TestRemoveJobName(t *testing.T) {
+	// Arrange
+	c := NewCron(false)
+	db := container.Summary{ID: "ID1", Names: []string{"/db"}}
+	assert.NilError(t, c.AddContainerJob(ContainerJob{Name: "db-dump", Schedule: "@daily", Action: "exec", Command: "dump", Container: db}))
+	assert.NilError(t, c.AddContainerJob(ContainerJob{Schedule: "@daily", Action: "restart", Container: db}))
+	assert.NilError(t, c.AddServiceJob(ServiceJob{Name: "web-refresh", Schedule: "@daily", Action: "update", ServiceID: "S1", ServiceName: "web"}))
+	assert.NilError(t, c.syncJobs([]Job{{Name: "backup", Schedule: "@daily", Command: "backup.sh"}}))
+
+	// Act
+	c.RemoveContainerJob("ID1")
+	c.RemoveServiceJob("S1")
+	err := c.syncJobs([]Job{})
+
+	// Assert
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(c.Jobs(), 0))
+	assert.Assert(t, is.Len(c.names, 0))
+	assert.NilError(t, c.AddContainerJob(ContainerJob{Name: "db-dump", Schedule: "@daily", Action: "exec", Command: "dump", Container: db}))
+	assert.NilError(t, c.AddServiceJob(ServiceJob{Name: "web-refresh", Schedule: "@daily", Action: "update", ServiceID: "S1", ServiceName: "web"}))
+	assert.NilError(t, c.syncJobs([]Job{{Name: "backup", Schedule: "@daily", Command: "backup.sh"}}))
+}
+
+func TestLoadConfigName(t *testing.T) {
+	// Arrange
+	c := NewCron(false)
+	c.fs = afero.NewMemMapFs()
+	load := func(config string) error {
+		afero.WriteFile(c.fs, "/configs/config.json", []byte(config), 0640)
+		return c.LoadConfig("/configs/config.json")
+	}
+	assert.NilError(t, load(`[{"name": "backup", "schedule": "0 2 * * *", "command": "backup.sh"}]`))
+
+	// Act
+	errChanged := load(`[{"name": "backup", "schedule": "0 3 * * *", "command": "backup.sh"}]`)
+	errDuplicate := load(`[
+		{"name": "backup", "schedule": "0 4 * * *", "command": "backup.sh"},
+		{"name": "backup", "schedule": "0 5 * * *", "command": "backup.sh"}
+	]`)
+
+	// Assert
+	assert.NilError(t, errChanged)
+	assert.Assert(t, is.ErrorContains(errDuplicate, "job name 'backup' is already used by another job"))
+	jobs := c.Jobs()
+	assert.Equal(t, len(jobs), 1)
+	assert.Equal(t, jobs[0].Name, "backup")
+	assert.Equal(t, jobs[0].Schedule, "0 3 * * *")
+	assert.Assert(t, is.Len(c.names, 1))
+}
+
+func TestLoadConfigCrontabName(t *testing.T) {
+	// Arrange
+	c := NewCron(false)
+	c.fs = afero.NewMemMapFs()
+	afero.WriteFile(c.fs, "/configs/crontab", []byte("0 2 * * * root backup.sh --full\n0 3 * * * root clean.sh\n0 4 * * * root clean.sh\n"), 0640)
+	afero.WriteFile(c.fs, "/configs/config.json", []byte(`[{"schedule": "@daily", "command": "backup.sh", "args": ["--full"]}]`), 0640)
+
+	// Act
+	errCrontab := c.LoadConfig("/configs/crontab")
+	crontab := names(c)
+	errJSON := c.LoadConfig("/configs/config.json")
+
+	// Assert
+	assert.NilError(t, errCrontab)
+	assert.NilError(t, errJSON)
+	assert.Assert(t, is.DeepEqual(crontab, []string{"backup.sh --full", "clean.sh", "clean.sh-2"}))
+	assert.Assert(t, is.DeepEqual(names(c), []string{"backup.sh --full"}))
+}
@@ -0,0 +1,311 @@
+package cron
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Conditions on the outcome of a run to send a notification.
+const (
+	NotifyFailure  = "failure"
+	NotifySuccess  = "success"
+	NotifyRecovery = "recovery"
+	NotifyAlways   = "always"
+)
+
+const (
+	// notifyOutput is the maximum size of the output sent in a notification.
+	notifyOutput = 1024
+
+	// notifyQueue is the number of notifications waiting to be sent before
+	// new ones are dropped.
+	notifyQueue = 100
+
+	// notifyAttempts is the number of attempts to deliver a notification.
+	notifyAttempts = 3
+)
+
+// notifyRetryDelay is the delay before the second attempt to deliver a
+// notification, doubled after each failed attempt.
+var notifyRetryDelay = time.Second
+
+// Notify configure the webhooks called after the runs of a job. On is a
+// comma separated list of conditions and Template, when set, is a Go
+// template of the request body executed with the Notification.
+type Notify struct {
+	URLs     []string `json:"urls"`
+	On       string   `json:"on"`
+	Template string   `json:"template"`
+}
+
+// Notification is the outcome of a job run sent to webhooks.
+type Notification struct {
+	Job       string        `json:"job"`
+	Source    string        `json:"source"`
+	Schedule  string        `json:"schedule"`
+	Container string        `json:"container,omitempty"`
+	Service   string        `json:"service,omitempty"`
+	Status    string        `json:"status"`
+	Start     time.Time     `json:"start"`
+	End       time.Time     `json:"end"`
+	Duration  time.Duration `json:"duration"`
+	ExitCode  int           `json:"exitCode"`
+	Output    string        `json:"output,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// newNotification return the notification of a run of job, with the tail of
+// its output up to maxOutput.
+func newNotification(job scheduledJob, run Run, maxOutput int) Notification {
+	status := NotifySuccess
+	if run.Error != "" {
+		status = NotifyFailure
+	}
+	output := run.Output
+	if len(output) > maxOutput {
+		output = output[len(output)-maxOutput:]
+	}
+	labels := job.labels()
+	return Notification{
+		Job:       run.Job,
+		Source:    run.Source,
+		Schedule:  job.scheduleSpec(),
+		Container: labels["container"],
+		Service:   labels["service"],
+		Status:    status,
+		Start:     run.Start,
+		End:       run.End,
+		Duration:  run.Duration,
+		ExitCode:  run.ExitCode,
+		Output:    output,
+		Error:     run.Error,
+	}
+}
+
+var notifyFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+func (n Notify) validate() error {
+	for _, on := range splitList(n.On) {
+		switch on {
+		case NotifyFailure, NotifySuccess, NotifyRecovery, NotifyAlways:
+		default:
+			return errors.New("invalid notify condition, only 'failure', 'success', 'recovery' and 'always' are permitted")
+		}
+	}
+	for _, u := range n.URLs {
+		if p, err := url.Parse(u); err != nil || (p.Scheme != "http" && p.Scheme != "https") || p.Host == "" {
+			return errors.Errorf("invalid notify url '%s', only http and https urls are permitted", u)
+		}
+	}
+	if n.Template != "" {
+		if _, err := template.New("notify").Funcs(notifyFuncs).Parse(n.Template); err != nil {
+			return errors.Wrap(err, "invalid notify template")
+		}
+	}
+	return nil
+}
+
+// merge return n with its empty fields set from defaults.
+func (n Notify) merge(defaults Notify) Notify {
+	if len(n.URLs) == 0 {
+		n.URLs = defaults.URLs
+	}
+	if n.On == "" {
+		n.On = defaults.On
+	}
+	if n.Template == "" {
+		n.Template = defaults.Template
+	}
+	return n
+}
+
+// match tell if a run with status, after a run that failed or not, must be
+// notified.
+func (n Notify) match(status string, failedBefore bool) bool {
+	on := n.On
+	if on == "" {
+		on = NotifyFailure
+	}
+	for _, c := range splitList(on) {
+		switch {
+		case c == NotifyAlways,
+			c == status,
+			c == NotifyRecovery && status == NotifySuccess && failedBefore:
+			return true
+		}
+	}
+	return false
+}
+
+// body return the request body of the notification.
+func (n Notify) body(notification Notification) ([]byte, error) {
+	if n.Template == "" {
+		return json.Marshal(notification)
+	}
+	t, err := template.New("notify").Funcs(notifyFuncs).Parse(n.Template)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid notify template")
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, notification); err != nil {
+		return nil, errors.Wrap(err, "failed to execute notify template")
+	}
+	return buf.Bytes(), nil
+}
+
+type delivery struct {
+	url  string
+	body []byte
+	job  string
+}
+
+// Notifier send the notifications of job runs to webhooks in the background.
+type Notifier struct {
+	defaults Notify
+	client   *http.Client
+	queue    chan delivery
+	done     chan struct{}
+	closed   bool
+	failed   map[string]bool
+	mu       sync.Mutex
+}
+
+// NewNotifier returns a notifier applying defaults to the jobs that do not
+// override them, and start sending its notifications.
+func NewNotifier(defaults Notify) (*Notifier, error) {
+	if err := defaults.validate(); err != nil {
+		return nil, err
+	}
+	n := &Notifier{
+		defaults: defaults,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		queue:    make(chan delivery, notifyQueue),
+		done:     make(chan struct{}),
+		failed:   make(map[string]bool),
+	}
+	go n.send()
+	return n, nil
+}
+
+// SetNotifier set the notifier of the runs of all jobs.
+func (c *Cron) SetNotifier(n *Notifier) {
+	c.notifier = n
+}
+
+// notify queue the notification of a run of job, without waiting for its
+// delivery.
+func (n *Notifier) notify(job scheduledJob, run Run) {
+	status := NotifySuccess
+	if run.Error != "" {
+		status = NotifyFailure
+	}
+
+	n.mu.Lock()
+	failedBefore := n.failed[job.id()]
+	n.failed[job.id()] = status == NotifyFailure
+	n.mu.Unlock()
+
+	config := job.notifyPolicy().merge(n.defaults)
+	if len(config.URLs) == 0 || !config.match(status, failedBefore) {
+		return
+	}
+
+	body, err := config.body(newNotification(job, run, notifyOutput))
+
+	log := log.WithFields(log.Fields{
+		"func": "Notifier.notify",
+		"job":  run.Job,
+	})
+	if err != nil {
+		log.WithError(err).Errorln("failed to build notification")
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, u := range config.URLs {
+		if n.closed {
+			log.WithField("url", u).Errorln("notification dropped, notifier is closed")
+			continue
+		}
+		select {
+		case n.queue <- delivery{url: u, body: body, job: run.Job}:
+		default:
+			log.WithField("url", u).Errorln("notification dropped, too many notifications waiting")
+		}
+	}
+}
+
+// close stop accepting notifications and wait for the queued ones to be sent.
+func (n *Notifier) close() {
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.queue)
+	}
+	n.mu.Unlock()
+	<-n.done
+}
+
+// send deliver the queued notifications, one after the other.
+func (n *Notifier) send() {
+	defer close(n.done)
+	for d := range n.queue {
+		log := log.WithFields(log.Fields{
+			"func": "Notifier.send",
+			"job":  d.job,
+			"url":  d.url,
+		})
+
+		delay := notifyRetryDelay
+		for attempt := 1; ; attempt++ {
+			err := n.post(d)
+			if err == nil {
+				log.Infoln("notification sent")
+				break
+			}
+			if attempt >= notifyAttempts {
+				log.WithError(err).Errorln("failed to send notification")
+				break
+			}
+			log.WithField("attempt", attempt).WithError(err).Warnln("notification attempt failed, retry after delay")
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+}
+
+func (n *Notifier) post(d delivery) error {
+	resp, err := n.client.Post(d.url, "application/json", bytes.NewReader(d.body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// labelNotify read the notify of a job from its 'notify.*' labels.
+func labelNotify(l labelJob) Notify {
+	return Notify{
+		URLs:     splitList(l.get("notify.urls")),
+		On:       strings.TrimSpace(l.get("notify.on")),
+		Template: l.get("notify.template"),
+	}
+}
@@ -0,0 +1,253 @@
+package cron
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	log "github.com/sirupsen/logrus"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// webhook is a test server recording the bodies of notifications.
+type webhook struct {
+	*httptest.Server
+	bodies chan string
+	status []int
+}
+
+func newWebhook(status ...int) *webhook {
+	w := &webhook{bodies: make(chan string, 10), status: status}
+	w.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		code := http.StatusOK
+		if len(w.status) > 0 {
+			code, w.status = w.status[0], w.status[1:]
+		}
+		rw.WriteHeader(code)
+		if code == http.StatusOK {
+			w.bodies <- string(data)
+		}
+	}))
+	return w
+}
+
+// next return the next body received, or fail after a delay.
+func (w *webhook) next(t *testing.T) string {
+	select {
+	case body := <-w.bodies:
+		return body
+	case <-time.After(2 * time.Second):
+		t.Fatal("notification not received")
+		return ""
+	}
+}
+
+// none check that no notification is received.
+func (w *webhook) none(t *testing.T) {
+	select {
+	case body := <-w.bodies:
+		t.Fatalf("unexpected notification: %s", body)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNotifyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		notify Notify
+		err    string
+	}{
+		{name: "no notify"},
+		{name: "valid", notify: Notify{URLs: []string{"https://hooks.local/x"}, On: "failure, recovery", Template: `{"text": {{json .Job}}}`}},
+		{name: "invalid condition", notify: Notify{On: "failure,never"}, err: "invalid notify condition, only 'failure', 'success', 'recovery' and 'always' are permitted"},
+		{name: "invalid url", notify: Notify{URLs: []string{"hooks.local"}}, err: "invalid notify url 'hooks.local', only http and https urls are permitted"},
+		{name: "invalid template", notify: Notify{Template: "{{.Job"}, err: "invalid notify template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.notify.validate()
+			if tt.err == "" {
+				assert.NilError(t, err)
+			} else {
+				assert.Assert(t, is.ErrorContains(err, tt.err))
+			}
+		})
+	}
+}
+
+func TestNotifyMatch(t *testing.T) {
+	tests := []struct {
+		on           string
+		status       string
+		failedBefore bool
+		want         bool
+	}{
+		{on: "", status: NotifyFailure, want: true},
+		{on: "", status: NotifySuccess, want: false},
+		{on: "success", status: NotifySuccess, want: true},
+		{on: "recovery", status: NotifySuccess, failedBefore: true, want: true},
+		{on: "recovery", status: NotifySuccess, want: false},
+		{on: "recovery", status: NotifyFailure, failedBefore: true, want: false},
+		{on: "failure,recovery", status: NotifySuccess, failedBefore: true, want: true},
+		{on: "always", status: NotifySuccess, want: true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, Notify{On: tt.on}.match(tt.status, tt.failedBefore), tt.want, "on=%s status=%s failedBefore=%v", tt.on, tt.status, tt.failedBefore)
+	}
+}
+
+func TestNotifyMerge(t *testing.T) {
+	defaults := Notify{URLs: []string{"http://a"}, On: "failure", Template: "t"}
+
+	assert.Assert(t, is.DeepEqual(Notify{}.merge(defaults), defaults))
+	assert.Assert(t, is.DeepEqual(Notify{URLs: []string{"http://b"}, On: "always"}.merge(defaults), Notify{URLs: []string{"http://b"}, On: "always", Template: "t"}))
+}
+
+func TestNotifyBody(t *testing.T) {
+	n := Notification{Job: "backup", Status: NotifyFailure, ExitCode: 2, Output: "line \"1\"\n"}
+
+	body, err := Notify{}.body(n)
+	assert.NilError(t, err)
+	var got Notification
+	assert.NilError(t, json.Unmarshal(body, &got))
+	assert.Assert(t, is.DeepEqual(got, n))
+
+	body, err = Notify{Template: `{"text": {{printf "%s is %s: %s" .Job .Status .Output | json}}}`}.body(n)
+	assert.NilError(t, err)
+	assert.Equal(t, string(body), `{"text": "backup is failure: line \"1\"\n"}`)
+
+	_, err = Notify{Template: "{{.Unknown}}"}.body(n)
+	assert.Assert(t, is.ErrorContains(err, "failed to execute notify template"))
+}
+
+func TestNotifierNotify(t *testing.T) {
+	// Arrange
+	defer func(d time.Duration) { notifyRetryDelay = d }(notifyRetryDelay)
+	notifyRetryDelay = time.Millisecond
+
+	hook := newWebhook(http.StatusInternalServerError)
+	defer hook.Close()
+
+	n, err := NewNotifier(Notify{URLs: []string{hook.URL}, On: "failure,recovery"})
+	assert.NilError(t, err)
+	job := &ContainerJob{Schedule: "0 2 * * *", Action: "exec", Container: container.Summary{Names: []string{"/db"}}}
+	run := func(err string) Run {
+		return Run{Job: "db-exec", Source: "container", ExitCode: 1, Output: strings.Repeat("a", notifyOutput) + "end", Error: err}
+	}
+
+	// Act
+	n.notify(job, run("exit status 1"))
+
+	// Assert
+	var got Notification
+	assert.NilError(t, json.Unmarshal([]byte(hook.next(t)), &got))
+	assert.Equal(t, got.Job, "db-exec")
+	assert.Equal(t, got.Source, "container")
+	assert.Equal(t, got.Container, "db")
+	assert.Equal(t, got.Schedule, "0 2 * * *")
+	assert.Equal(t, got.Status, NotifyFailure)
+	assert.Equal(t, got.Error, "exit status 1")
+	assert.Equal(t, len(got.Output), notifyOutput)
+	assert.Assert(t, strings.HasSuffix(got.Output, "end"))
+
+	// Act
+	n.notify(job, run(""))
+
+	// Assert
+	assert.NilError(t, json.Unmarshal([]byte(hook.next(t)), &got))
+	assert.Equal(t, got.Status, NotifySuccess)
+
+	// Act
+	n.notify(job, run(""))
+
+	// Assert
+	hook.none(t)
+}
+
+func TestNotifierNotifyJobOverride(t *testing.T) {
+	// Arrange
+	hook := newWebhook()
+	defer hook.Close()
+
+	n, err := NewNotifier(Notify{On: "failure"})
+	assert.NilError(t, err)
+	job := &Job{Schedule: "* * * * *", Command: "backup.sh", Notify: Notify{URLs: []string{hook.URL}, On: "success", Template: "{{.Job}} {{.Status}}"}}
+
+	// Act
+	n.notify(job, Run{Job: "backup.sh", Source: "file", Error: "failed"})
+	n.notify(job, Run{Job: "backup.sh", Source: "file"})
+
+	// Assert
+	assert.Equal(t, hook.next(t), "backup.sh success")
+	hook.none(t)
+}
+
+func TestNotifierClose(t *testing.T) {
+	// Arrange
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+
+	hook := newWebhook()
+	defer hook.Close()
+
+	n, err := NewNotifier(Notify{URLs: []string{hook.URL}, On: "always"})
+	assert.NilError(t, err)
+	job := &Job{Schedule: "* * * * *", Command: "backup.sh"}
+	n.notify(job, Run{Job: "backup.sh"})
+
+	// Act
+	n.close()
+	n.close()
+
+	// Assert
+	assert.Equal(t, len(hook.bodies), 1)
+
+	// Act
+	n.notify(job, Run{Job: "backup.sh"})
+
+	// Assert
+	assert.Assert(t, is.Contains(out.String(), "notification dropped, notifier is closed"))
+}
+
+func TestCronRecordNotify(t *testing.T) {
+	// Arrange
+	hook := newWebhook()
+	defer hook.Close()
+
+	n, err := NewNotifier(Notify{URLs: []string{hook.URL}, On: "always"})
+	assert.NilError(t, err)
+	c := &Cron{notifier: n}
+	job := &ServiceJob{Schedule: "* * * * *", Action: "update", ServiceName: "web"}
+
+	// Act
+	c.record(job, time.Now(), "", nil)
+	n.close()
+
+	// Assert
+	var got Notification
+	assert.NilError(t, json.Unmarshal([]byte(hook.next(t)), &got))
+	assert.Equal(t, got.Job, "web-update")
+	assert.Equal(t, got.Service, "web")
+	assert.Equal(t, got.Status, NotifySuccess)
+}
+
+func TestLabelNotify(t *testing.T) {
+	l := labelJob{name: "dump", labels: map[string]string{
+		"mobycron.dump.notify.urls":     "http://a, http://b",
+		"mobycron.dump.notify.on":       "always",
+		"mobycron.dump.notify.template": "{{.Job}}",
+	}}
+
+	assert.Assert(t, is.DeepEqual(labelNotify(l), Notify{URLs: []string{"http://a", "http://b"}, On: "always", Template: "{{.Job}}"}))
+	assert.Assert(t, is.DeepEqual(labelNotify(labelJob{labels: map[string]string{}}), Notify{}))
+}
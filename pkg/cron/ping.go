@@ -0,0 +1,91 @@
+package cron
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// pingOutput is the maximum size of the output sent in a ping body.
+const pingOutput = 10 * 1024
+
+// pingClient send the pings, with a short timeout to never hold a job run.
+var pingClient = &http.Client{Timeout: 5 * time.Second}
+
+// Ping configure a healthcheck URL, like healthchecks.io or Cronitor, pinged
+// around each run of a job: URL/start when the run starts, URL on success and
+// URL/fail on failure. When Body is true, the exit code and the tail of the
+// output are sent in the body of the last ping.
+type Ping struct {
+	URL  string `json:"url"`
+	Body bool   `json:"body"`
+}
+
+func (p Ping) validate() error {
+	if p.URL == "" {
+		return nil
+	}
+	if u, err := url.Parse(p.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("invalid ping url '%s', only http and https urls are permitted", p.URL)
+	}
+	return nil
+}
+
+// start ping the start of a run.
+func (p Ping) start(log *log.Entry) {
+	if p.URL == "" {
+		return
+	}
+	p.send(log, "/start", "")
+}
+
+// finish ping the outcome of a run.
+func (p Ping) finish(log *log.Entry, out string, err error) {
+	if p.URL == "" {
+		return
+	}
+	suffix := ""
+	if err != nil {
+		suffix = "/fail"
+	}
+	body := ""
+	if p.Body {
+		if len(out) > pingOutput {
+			out = out[len(out)-pingOutput:]
+		}
+		body = fmt.Sprintf("exit code %d\n%s", ExitCode(err), out)
+	}
+	p.send(log, suffix, body)
+}
+
+// send the ping, only logging a failure so that the outcome of the job is
+// never changed.
+func (p Ping) send(log *log.Entry, suffix string, body string) {
+	u := strings.TrimSuffix(p.URL, "/") + suffix
+	log = log.WithField("ping", u)
+
+	resp, err := pingClient.Post(u, "text/plain", strings.NewReader(body))
+	if err != nil {
+		log.WithError(err).Warnln("failed to ping healthcheck")
+		return
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		log.WithError(errors.Errorf("healthcheck returned status %d", resp.StatusCode)).Warnln("failed to ping healthcheck")
+	}
+}
+
+// labelPing read the ping of a job from its 'ping.*' labels.
+func labelPing(l labelJob) Ping {
+	return Ping{
+		URL:  strings.TrimSpace(l.get("ping.url")),
+		Body: l.get("ping.body") == "true",
+	}
+}
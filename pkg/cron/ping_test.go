@@ -0,0 +1,151 @@
+package cron
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// healthcheck is a test server recording the pings received.
+type healthcheck struct {
+	*httptest.Server
+	status int
+	mu     sync.Mutex
+	pings  []string
+}
+
+func newHealthcheck(status int) *healthcheck {
+	h := &healthcheck{status: status}
+	h.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		h.mu.Lock()
+		h.pings = append(h.pings, r.Method+" "+r.URL.Path+" "+string(body))
+		h.mu.Unlock()
+		rw.WriteHeader(h.status)
+	}))
+	return h
+}
+
+func TestPingValidate(t *testing.T) {
+	assert.NilError(t, Ping{}.validate())
+	assert.NilError(t, Ping{URL: "https://hc-ping.com/uuid"}.validate())
+	assert.Error(t, Ping{URL: "hc-ping.com/uuid"}.validate(), "invalid ping url 'hc-ping.com/uuid', only http and https urls are permitted")
+}
+
+func TestPing(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   bool
+		out    string
+		err    error
+		status int
+		pings  []string
+		logs   int
+	}{
+		{
+			name:   "success",
+			out:    "done",
+			status: http.StatusOK,
+			pings:  []string{"POST /uuid/start ", "POST /uuid "},
+		},
+		{
+			name:   "failure",
+			err:    &exitError{2},
+			status: http.StatusOK,
+			pings:  []string{"POST /uuid/start ", "POST /uuid/fail "},
+		},
+		{
+			name:   "failure with body",
+			body:   true,
+			out:    strings.Repeat("a", pingOutput) + "error",
+			err:    &exitError{2},
+			status: http.StatusOK,
+			pings:  []string{"POST /uuid/start ", "POST /uuid/fail exit code 2\n" + strings.Repeat("a", pingOutput-5) + "error"},
+		},
+		{
+			name:   "healthcheck in error",
+			status: http.StatusNotFound,
+			pings:  []string{"POST /uuid/start ", "POST /uuid "},
+			logs:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			out := &bytes.Buffer{}
+			log.SetOutput(out)
+
+			hc := newHealthcheck(tt.status)
+			defer hc.Close()
+			p := Ping{URL: hc.URL + "/uuid/", Body: tt.body}
+			entry := log.WithField("func", "test")
+
+			// Act
+			p.start(entry)
+			p.finish(entry, tt.out, tt.err)
+
+			// Assert
+			assert.Assert(t, is.DeepEqual(hc.pings, tt.pings))
+			assert.Equal(t, strings.Count(out.String(), "failed to ping healthcheck"), tt.logs)
+		})
+	}
+}
+
+func TestPingUnreachable(t *testing.T) {
+	// Arrange
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+
+	hc := newHealthcheck(http.StatusOK)
+	hc.Close()
+
+	// Act
+	Ping{URL: hc.URL}.finish(log.WithField("func", "test"), "", nil)
+
+	// Assert
+	assert.Assert(t, is.Contains(out.String(), "failed to ping healthcheck"))
+}
+
+func TestJobRunPing(t *testing.T) {
+	// Arrange
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+
+	hc := newHealthcheck(http.StatusInternalServerError)
+	defer hc.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	s := NewMockJobSynchroniser(ctrl)
+	s.EXPECT().Add(1)
+	s.EXPECT().Done()
+
+	j := &Job{Schedule: "* * * * *", Command: "echo", Ping: Ping{URL: hc.URL}, cron: &Cron{sync: s}}
+
+	// Act
+	j.Run()
+
+	// Assert
+	assert.Assert(t, is.DeepEqual(hc.pings, []string{"POST /start ", "POST / "}))
+	assert.Assert(t, is.Contains(out.String(), "job completed successfully"))
+}
+
+func TestLabelPing(t *testing.T) {
+	l := labelJob{name: "dump", labels: map[string]string{
+		"mobycron.dump.ping.url":  " https://hc-ping.com/uuid ",
+		"mobycron.dump.ping.body": "true",
+	}}
+
+	assert.Equal(t, labelPing(l), Ping{URL: "https://hc-ping.com/uuid", Body: true})
+	assert.Equal(t, labelPing(labelJob{labels: map[string]string{}}), Ping{})
+}
@@ -0,0 +1,257 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cron "github.com/robfig/cron/v3"
+	"github.com/spf13/afero"
+)
+
+// Preview is the description of a schedule and its next times.
+type Preview struct {
+	Name        string      `json:"name,omitempty"`
+	Schedule    string      `json:"schedule"`
+	Description string      `json:"description"`
+	Next        []time.Time `json:"next"`
+}
+
+// descriptors map the predefined schedules to their equivalent expression.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = []string{"", "January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	dowNames   = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+)
+
+// field describe a field of a schedule expression. List is the format of a
+// list of values and min the first value of the field.
+type field struct {
+	unit  string
+	list  string
+	min   int
+	names []string
+}
+
+var (
+	secondField = field{unit: "second", list: "at second %s"}
+	minuteField = field{unit: "minute", list: "at minute %s"}
+	hourField   = field{unit: "hour", list: "at hour %s"}
+	domField    = field{unit: "day", list: "on day %s of the month", min: 1}
+	monthField  = field{unit: "month", list: "in %s", min: 1, names: monthNames}
+	dowField    = field{unit: "day of the week", list: "on %s", names: dowNames}
+)
+
+// PreviewSchedule return the description of the schedule spec and its next
+// count times after from. A seconds field is expected when parseSecond is
+// true, like with NewCron.
+func PreviewSchedule(spec string, parseSecond bool, from time.Time, count int) (Preview, error) {
+	schedule, err := newParser(parseSecond).Parse(spec)
+	if err != nil {
+		return Preview{}, errors.Wrap(err, "invalid schedule")
+	}
+	return Preview{
+		Schedule:    spec,
+		Description: describe(spec, parseSecond),
+		Next:        nextTimes(schedule, from, count),
+	}, nil
+}
+
+// PreviewConfig return the preview of the next count times after from of all
+// jobs of the config file.
+func (c *Cron) PreviewConfig(filename string, from time.Time, count int) ([]Preview, error) {
+	config, err := afero.ReadFile(c.fs, filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read config file")
+	}
+
+	jobs, err := parseConfig(config, c.configFormat(filename), c.second)
+	if err != nil {
+		return nil, err
+	}
+
+	previews := []Preview{}
+	for i := range jobs {
+		name := jobs[i].labels()["name"]
+		p, err := PreviewSchedule(jobs[i].Schedule, c.second, from, count)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to preview job %s", name)
+		}
+		p.Name = name
+		previews = append(previews, p)
+	}
+	return previews, nil
+}
+
+// nextTimes return the next count times of schedule after from. A schedule
+// never matching, like the 30th of February, has no next time.
+func nextTimes(schedule cron.Schedule, from time.Time, count int) []time.Time {
+	next := []time.Time{}
+	for i := 0; i < count; i++ {
+		if from = schedule.Next(from); from.IsZero() {
+			break
+		}
+		next = append(next, from)
+	}
+	return next
+}
+
+// describe return a human-readable description of a valid schedule spec.
+func describe(spec string, parseSecond bool) string {
+	zone := ""
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		i := strings.Index(spec, " ")
+		zone = spec[strings.Index(spec, "=")+1 : i]
+		spec = strings.TrimSpace(spec[i:])
+	}
+
+	var desc string
+	if strings.HasPrefix(spec, "@every ") {
+		d, _ := time.ParseDuration(strings.TrimPrefix(spec, "@every "))
+		desc = "every " + d.String()
+	} else {
+		if e, ok := descriptors[spec]; ok {
+			spec, parseSecond = e, false
+		}
+		fields := strings.Fields(spec)
+		if !parseSecond {
+			fields = append([]string{"0"}, fields...)
+		}
+		desc = describeFields(fields)
+	}
+
+	if zone != "" {
+		desc += " (" + zone + ")"
+	}
+	return strings.ToUpper(desc[:1]) + desc[1:]
+}
+
+// describeFields describe the six fields of an expression, from the seconds
+// to the day of the week.
+func describeFields(f []string) string {
+	parts := []string{}
+	if times, ok := clockTimes(f[0], f[1], f[2]); ok {
+		parts = append(parts, "at "+join(times))
+	} else {
+		if f[0] != "0" {
+			parts = append(parts, secondField.describe(f[0]))
+		}
+		if f[0] == "0" || f[1] != "*" {
+			parts = append(parts, minuteField.describe(f[1]))
+		}
+		if f[2] != "*" {
+			parts = append(parts, hourField.describe(f[2]))
+		}
+	}
+
+	dom := f[3] != "*" && f[3] != "?"
+	dow := f[5] != "*" && f[5] != "?"
+	switch {
+	case dom && dow:
+		// Like a classic cron, the job run when either of the days match.
+		parts = append(parts, domField.describe(f[3])+" or "+dowField.describe(f[5]))
+	case dom:
+		parts = append(parts, domField.describe(f[3]))
+	case dow:
+		parts = append(parts, dowField.describe(f[5]))
+	}
+	if f[4] != "*" {
+		parts = append(parts, monthField.describe(f[4]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// clockTimes return the times of day of the seconds, minutes and hours fields
+// when they are plain values.
+func clockTimes(second, minute, hours string) ([]string, bool) {
+	s, err := strconv.Atoi(second)
+	if err != nil {
+		return nil, false
+	}
+	m, err := strconv.Atoi(minute)
+	if err != nil {
+		return nil, false
+	}
+
+	times := []string{}
+	for _, hour := range strings.Split(hours, ",") {
+		h, err := strconv.Atoi(hour)
+		if err != nil {
+			return nil, false
+		}
+		if s == 0 {
+			times = append(times, fmt.Sprintf("%02d:%02d", h, m))
+		} else {
+			times = append(times, fmt.Sprintf("%02d:%02d:%02d", h, m, s))
+		}
+	}
+	return times, true
+}
+
+// describe return the description of expr, a value of the field.
+func (f field) describe(expr string) string {
+	if expr == "*" || expr == "?" {
+		return "every " + f.unit
+	}
+
+	if base, step, ok := strings.Cut(expr, "/"); ok {
+		every := "every " + step + " " + f.unit + "s"
+		if step == "1" {
+			every = "every " + f.unit
+		}
+		switch from, to, ok := strings.Cut(base, "-"); {
+		case ok:
+			return every + " from " + f.name(from) + " through " + f.name(to)
+		case base == "*" || base == strconv.Itoa(f.min):
+			return every
+		default:
+			return every + " starting at " + f.unit + " " + f.name(base)
+		}
+	}
+
+	values := []string{}
+	for _, v := range strings.Split(expr, ",") {
+		if from, to, ok := strings.Cut(v, "-"); ok {
+			values = append(values, f.name(from)+" through "+f.name(to))
+		} else {
+			values = append(values, f.name(v))
+		}
+	}
+	return fmt.Sprintf(f.list, join(values))
+}
+
+// name return the name of the value v of the field, like 'January' for a
+// month, or v itself.
+func (f field) name(v string) string {
+	if f.names == nil {
+		return v
+	}
+	if i, err := strconv.Atoi(v); err == nil && i >= 0 && i < len(f.names) && f.names[i] != "" {
+		return f.names[i]
+	}
+	for _, n := range f.names {
+		if n != "" && strings.EqualFold(n[:3], v) {
+			return n
+		}
+	}
+	return v
+}
+
+// join return the values separated by commas, with 'and' before the last.
+func join(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return strings.Join(values[:len(values)-1], ", ") + " and " + values[len(values)-1]
+}
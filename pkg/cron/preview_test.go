@@ -0,0 +1,181 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		spec   string
+		second bool
+		want   string
+	}{
+		{spec: "* * * * *", want: "Every minute"},
+		{spec: "0 2 * * *", want: "At 02:00"},
+		{spec: "0/2 * * * *", want: "Every 2 minutes"},
+		{spec: "5/10 * * * *", want: "Every 10 minutes starting at minute 5"},
+		{spec: "5 * * * *", want: "At minute 5"},
+		{spec: "*/15 9-17 * * MON-FRI", want: "Every 15 minutes, at hour 9 through 17, on Monday through Friday"},
+		{spec: "0 9,17 * * 1-5", want: "At 09:00 and 17:00, on Monday through Friday"},
+		{spec: "0 0 1 * 1", want: "At 00:00, on day 1 of the month or on Monday"},
+		{spec: "0 0 */2 * *", want: "At 00:00, every 2 days"},
+		{spec: "0 0 * 1-3,12 *", want: "At 00:00, in January through March and December"},
+		{spec: "CRON_TZ=America/New_York 30 6 1,15 * *", want: "At 06:30, on day 1 and 15 of the month (America/New_York)"},
+		{spec: "@yearly", want: "At 00:00, on day 1 of the month, in January"},
+		{spec: "@every 90m", want: "Every 1h30m0s"},
+		{spec: "*/10 * * * * *", second: true, want: "Every 10 seconds"},
+		{spec: "30 0 2 * * *", second: true, want: "At 02:00:30"},
+		{spec: "@weekly", second: true, want: "At 00:00, on Sunday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			assert.Equal(t, describe(tt.spec, tt.second), tt.want)
+		})
+	}
+}
+
+func TestPreviewSchedule(t *testing.T) {
+	from := time.Date(2020, 1, 1, 0, 30, 0, 0, time.UTC)
+	ny, _ := time.LoadLocation("America/New_York")
+
+	tests := []struct {
+		name   string
+		spec   string
+		second bool
+		count  int
+		want   Preview
+		err    string
+	}{
+		{
+			name:  "next times",
+			spec:  "0 2 * * 1-5",
+			count: 3,
+			want: Preview{
+				Schedule:    "0 2 * * 1-5",
+				Description: "At 02:00, on Monday through Friday",
+				Next: []time.Time{
+					time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC),
+					time.Date(2020, 1, 2, 2, 0, 0, 0, time.UTC),
+					time.Date(2020, 1, 3, 2, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name:  "time zone",
+			spec:  "CRON_TZ=America/New_York 0 2 * * *",
+			count: 1,
+			want: Preview{
+				Schedule:    "CRON_TZ=America/New_York 0 2 * * *",
+				Description: "At 02:00 (America/New_York)",
+				Next:        []time.Time{time.Date(2020, 1, 1, 2, 0, 0, 0, ny)},
+			},
+		},
+		{
+			name:   "with seconds",
+			spec:   "*/20 * * * * *",
+			second: true,
+			count:  2,
+			want: Preview{
+				Schedule:    "*/20 * * * * *",
+				Description: "Every 20 seconds",
+				Next: []time.Time{
+					time.Date(2020, 1, 1, 0, 30, 20, 0, time.UTC),
+					time.Date(2020, 1, 1, 0, 30, 40, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name:  "never",
+			spec:  "0 0 30 2 *",
+			count: 5,
+			want:  Preview{Schedule: "0 0 30 2 *", Description: "At 00:00, on day 30 of the month, in February", Next: []time.Time{}},
+		},
+		{
+			name: "seconds without parse second",
+			spec: "*/20 * * * * *",
+			err:  "invalid schedule",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			p, err := PreviewSchedule(tt.spec, tt.second, from, tt.count)
+
+			// Assert
+			if tt.err != "" {
+				assert.Assert(t, is.ErrorContains(err, tt.err))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, p.Schedule, tt.want.Schedule)
+			assert.Equal(t, p.Description, tt.want.Description)
+			assert.Equal(t, len(p.Next), len(tt.want.Next))
+			for i := range p.Next {
+				assert.Assert(t, p.Next[i].Equal(tt.want.Next[i]), "%s != %s", p.Next[i], tt.want.Next[i])
+			}
+		})
+	}
+}
+
+func TestPreviewConfig(t *testing.T) {
+	from := time.Date(2020, 1, 1, 0, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		config string
+		want   []Preview
+		err    string
+	}{
+		{
+			name:   "all jobs",
+			config: `[{"schedule": "0 2 * * *", "command": "backup.sh"}, {"schedule": "@hourly", "command": "clean.sh"}]`,
+			want: []Preview{
+				{Name: "backup.sh", Schedule: "0 2 * * *", Description: "At 02:00", Next: []time.Time{time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC)}},
+				{Name: "clean.sh", Schedule: "@hourly", Description: "At minute 0", Next: []time.Time{time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC)}},
+			},
+		},
+		{
+			name:   "invalid schedule",
+			config: `[{"schedule": "0 25 * * *", "command": "backup.sh"}]`,
+			err:    "failed to preview job backup.sh: invalid schedule",
+		},
+		{
+			name:   "invalid file",
+			config: `[{"schedule": }]`,
+			err:    "failed to parse JSON data from config file",
+		},
+		{
+			name: "file not found",
+			err:  "failed to read config file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			fs := afero.NewMemMapFs()
+			if tt.config != "" {
+				afero.WriteFile(fs, "/configs/config.json", []byte(tt.config), 0640)
+			}
+			c := &Cron{fs: fs}
+
+			// Act
+			p, err := c.PreviewConfig("/configs/config.json", from, 1)
+
+			// Assert
+			if tt.err != "" {
+				assert.Assert(t, is.ErrorContains(err, tt.err))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Assert(t, is.DeepEqual(p, tt.want))
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package cron
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Default backoff of the retries of a failed job run.
+const (
+	defaultRetryDelay      = 10 * time.Second
+	defaultRetryMultiplier = 2
+)
+
+// retryAfter wait for the delay before the next attempt of a job run.
+var retryAfter = time.After
+
+// Retry configure the new attempts of a failed job run. Attempts is the
+// maximum number of attempts, including the first one. The delay before each
+// new attempt start at Delay and is multiplied by Multiplier up to MaxDelay.
+// When ExitCodes is set, only the attempts failed with one of these exit
+// codes are retried.
+type Retry struct {
+	Attempts   int     `json:"attempts"`
+	Delay      string  `json:"delay"`
+	Multiplier float64 `json:"multiplier"`
+	MaxDelay   string  `json:"maxdelay"`
+	ExitCodes  []int   `json:"exitcodes"`
+}
+
+func (r Retry) validate() error {
+	if r.Attempts < 0 {
+		return errors.New("invalid retry attempts, only positive integer are permitted")
+	}
+	for _, d := range []string{r.Delay, r.MaxDelay} {
+		if d == "" {
+			continue
+		}
+		if v, err := time.ParseDuration(d); err != nil || v <= 0 {
+			return errors.New("invalid retry delay, only positive duration like '30s' or '5m' are permitted")
+		}
+	}
+	if r.Multiplier != 0 && r.Multiplier < 1 {
+		return errors.New("invalid retry multiplier, only number greater or equal to 1 are permitted")
+	}
+	return nil
+}
+
+// delay return the delay before the attempt following the failed attempt n.
+func (r Retry) delay(n int) time.Duration {
+	delay := defaultRetryDelay
+	if r.Delay != "" {
+		delay, _ = time.ParseDuration(r.Delay)
+	}
+	multiplier := float64(defaultRetryMultiplier)
+	if r.Multiplier != 0 {
+		multiplier = r.Multiplier
+	}
+
+	d := time.Duration(float64(delay) * math.Pow(multiplier, float64(n-1)))
+	if r.MaxDelay != "" {
+		max, _ := time.ParseDuration(r.MaxDelay)
+		if d > max || d <= 0 {
+			d = max
+		}
+	}
+	return d
+}
+
+// retryable tell if an attempt failed with err can be retried.
+func (r Retry) retryable(err error) bool {
+	if len(r.ExitCodes) == 0 {
+		return true
+	}
+	code := ExitCode(err)
+	for _, c := range r.ExitCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// do call fn until it succeeds, the attempts are exhausted, the error is not
+// retryable or ctx is canceled. The output and error of the last attempt are
+// returned along with its number.
+func (r Retry) do(ctx context.Context, log *log.Entry, fn func(context.Context) (string, error)) (string, int, error) {
+	for n := 1; ; n++ {
+		out, err := fn(ctx)
+		if err == nil || n >= r.Attempts || !r.retryable(err) || ctx.Err() != nil {
+			return out, n, err
+		}
+
+		delay := r.delay(n)
+		log.WithField("attempt", n).WithField("delay", delay.String()).WithField("output", out).
+			WithError(err).Warnln("job attempt failed, retry after delay")
+
+		select {
+		case <-ctx.Done():
+			return out, n, err
+		case <-retryAfter(delay):
+		}
+	}
+}
+
+// labelRetry read the retry of a job from its 'retry.*' labels.
+func labelRetry(l labelJob) (Retry, error) {
+	r := Retry{
+		Delay:    l.get("retry.delay"),
+		MaxDelay: l.get("retry.maxdelay"),
+	}
+
+	var err error
+	if v := l.get("retry.attempts"); v != "" {
+		if r.Attempts, err = strconv.Atoi(v); err != nil {
+			return Retry{}, errors.New("invalid retry attempts, only positive integer are permitted")
+		}
+	}
+	if v := l.get("retry.multiplier"); v != "" {
+		if r.Multiplier, err = strconv.ParseFloat(v, 64); err != nil {
+			return Retry{}, errors.New("invalid retry multiplier, only number greater or equal to 1 are permitted")
+		}
+	}
+	for _, v := range splitList(l.get("retry.exitcodes")) {
+		code, err := strconv.Atoi(v)
+		if err != nil {
+			return Retry{}, errors.New("invalid retry exit codes, only comma separated integers are permitted")
+		}
+		r.ExitCodes = append(r.ExitCodes, code)
+	}
+	return r, nil
+}
@@ -0,0 +1,213 @@
+package cron
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// noRetryDelay make the retries immediate until the returned func is called.
+func noRetryDelay() func() {
+	retryAfter = func(time.Duration) <-chan time.Time {
+		c := make(chan time.Time, 1)
+		c <- time.Now()
+		return c
+	}
+	return func() { retryAfter = time.After }
+}
+
+func TestRetryValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		retry Retry
+		err   string
+	}{
+		{name: "no retry"},
+		{name: "valid", retry: Retry{Attempts: 3, Delay: "1s", Multiplier: 1.5, MaxDelay: "1m", ExitCodes: []int{1}}},
+		{name: "negative attempts", retry: Retry{Attempts: -1}, err: "invalid retry attempts, only positive integer are permitted"},
+		{name: "invalid delay", retry: Retry{Delay: "soon"}, err: "invalid retry delay, only positive duration like '30s' or '5m' are permitted"},
+		{name: "negative max delay", retry: Retry{MaxDelay: "-1s"}, err: "invalid retry delay, only positive duration like '30s' or '5m' are permitted"},
+		{name: "invalid multiplier", retry: Retry{Multiplier: 0.5}, err: "invalid retry multiplier, only number greater or equal to 1 are permitted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.retry.validate()
+			if tt.err == "" {
+				assert.NilError(t, err)
+			} else {
+				assert.Error(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, Retry{}.delay(1), 10*time.Second)
+	assert.Equal(t, Retry{}.delay(3), 40*time.Second)
+	assert.Equal(t, Retry{Delay: "1s", Multiplier: 3}.delay(3), 9*time.Second)
+	assert.Equal(t, Retry{Delay: "1s", Multiplier: 1}.delay(5), time.Second)
+	assert.Equal(t, Retry{Delay: "1s", MaxDelay: "5s"}.delay(4), 5*time.Second)
+}
+
+func TestRetryDo(t *testing.T) {
+	defer noRetryDelay()()
+
+	tests := []struct {
+		name    string
+		retry   Retry
+		errs    []error
+		cancel  bool
+		attempt int
+		err     string
+		logs    int
+	}{
+		{
+			name:    "success without retry",
+			retry:   Retry{},
+			errs:    []error{nil},
+			attempt: 1,
+		},
+		{
+			name:    "failure without retry",
+			retry:   Retry{},
+			errs:    []error{errors.New("failed")},
+			attempt: 1,
+			err:     "failed",
+		},
+		{
+			name:    "success after retries",
+			retry:   Retry{Attempts: 3},
+			errs:    []error{errors.New("failed 1"), errors.New("failed 2"), nil},
+			attempt: 3,
+			logs:    2,
+		},
+		{
+			name:    "attempts exhausted",
+			retry:   Retry{Attempts: 2},
+			errs:    []error{errors.New("failed 1"), errors.New("failed 2")},
+			attempt: 2,
+			err:     "failed 2",
+			logs:    1,
+		},
+		{
+			name:    "retry on exit code",
+			retry:   Retry{Attempts: 3, ExitCodes: []int{75}},
+			errs:    []error{&exitError{75}, &exitError{1}},
+			attempt: 2,
+			err:     "exit status 1",
+			logs:    1,
+		},
+		{
+			name:    "canceled",
+			retry:   Retry{Attempts: 3},
+			errs:    []error{errors.New("failed")},
+			cancel:  true,
+			attempt: 1,
+			err:     "failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			out := &bytes.Buffer{}
+			log.SetOutput(out)
+			log.SetFormatter(&log.JSONFormatter{})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			calls := 0
+			fn := func(ctx context.Context) (string, error) {
+				err := tt.errs[calls]
+				calls++
+				return "output", err
+			}
+
+			// Act
+			output, attempt, err := tt.retry.do(ctx, log.WithField("func", "test"), fn)
+
+			// Assert
+			assert.Equal(t, output, "output")
+			assert.Equal(t, attempt, tt.attempt)
+			assert.Equal(t, calls, tt.attempt)
+			if tt.err == "" {
+				assert.NilError(t, err)
+			} else {
+				assert.Error(t, err, tt.err)
+			}
+			assert.Equal(t, strings.Count(out.String(), "job attempt failed, retry after delay"), tt.logs)
+			if tt.logs > 0 {
+				assert.Assert(t, is.Contains(out.String(), "\"attempt\":1"))
+			}
+		})
+	}
+}
+
+func TestLabelRetry(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   Retry
+		err    string
+	}{
+		{
+			name:   "no retry",
+			labels: map[string]string{"mobycron.schedule": "* * * * *"},
+		},
+		{
+			name: "all labels",
+			labels: map[string]string{
+				"mobycron.retry.attempts":   "3",
+				"mobycron.retry.delay":      "5s",
+				"mobycron.retry.multiplier": "1.5",
+				"mobycron.retry.maxdelay":   "1m",
+				"mobycron.retry.exitcodes":  "1, 75",
+			},
+			want: Retry{Attempts: 3, Delay: "5s", Multiplier: 1.5, MaxDelay: "1m", ExitCodes: []int{1, 75}},
+		},
+		{
+			name:   "invalid attempts",
+			labels: map[string]string{"mobycron.retry.attempts": "many"},
+			err:    "invalid retry attempts, only positive integer are permitted",
+		},
+		{
+			name:   "invalid multiplier",
+			labels: map[string]string{"mobycron.retry.multiplier": "double"},
+			err:    "invalid retry multiplier, only number greater or equal to 1 are permitted",
+		},
+		{
+			name:   "invalid exit codes",
+			labels: map[string]string{"mobycron.retry.exitcodes": "1,x"},
+			err:    "invalid retry exit codes, only comma separated integers are permitted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retry, err := labelRetry(labelJob{labels: tt.labels})
+			if tt.err == "" {
+				assert.NilError(t, err)
+				assert.Assert(t, is.DeepEqual(retry, tt.want))
+			} else {
+				assert.Error(t, err, tt.err)
+			}
+		})
+	}
+
+	// Named job
+	retry, err := labelRetry(labelJob{name: "dump", labels: map[string]string{"mobycron.dump.retry.attempts": "2"}})
+	assert.NilError(t, err)
+	assert.Equal(t, retry.Attempts, 2)
+}
@@ -0,0 +1,167 @@
+package cron
+
+import (
+	context "context"
+	"io"
+	"sort"
+	"strings"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/pkg/errors"
+)
+
+// newDockerClient create the docker client used by the jobs of the config
+// file run in a container.
+var newDockerClient = func() (DockerClient, error) {
+	return client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+}
+
+// runOptions describe an ephemeral container.
+type runOptions struct {
+	Image      string
+	Cmd        []string
+	Env        []string
+	Mounts     []string
+	Network    string
+	AutoRemove bool
+}
+
+// runLabel is set on the containers created by the 'run' action.
+const runLabel = "mobycron.run"
+
+// runContainer create a container, wait for it to exit and return its logs,
+// streamed to w when set. The image is pulled when it is missing. The
+// container is stopped when ctx is canceled and removed on exit when
+// AutoRemove is set.
+func runContainer(ctx context.Context, cli DockerClient, opts runOptions, w io.Writer) (string, error) {
+	config := &container.Config{
+		Image:  opts.Image,
+		Cmd:    opts.Cmd,
+		Env:    opts.Env,
+		Labels: map[string]string{runLabel: "true"},
+	}
+	hostConfig := &container.HostConfig{
+		Binds:       opts.Mounts,
+		NetworkMode: container.NetworkMode(opts.Network),
+	}
+
+	createResp, err := cli.ContainerCreate(ctx, config, hostConfig, &network.NetworkingConfig{}, nil, "")
+	if cerrdefs.IsNotFound(err) {
+		if err := pullImage(ctx, cli, opts.Image); err != nil {
+			return "", err
+		}
+		createResp, err = cli.ContainerCreate(ctx, config, hostConfig, &network.NetworkingConfig{}, nil, "")
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create container")
+	}
+	ID := createResp.ID
+
+	if opts.AutoRemove {
+		defer cli.ContainerRemove(context.Background(), ID, container.RemoveOptions{Force: true})
+	}
+
+	return startAndWait(ctx, cli, ID, container.LogsOptions{ShowStdout: true, ShowStderr: true}, w)
+}
+
+// pullImage pull the image ref, like 'docker run' when the image is missing.
+func pullImage(ctx context.Context, cli DockerClient, ref string) error {
+	progress, err := cli.ImagePull(ctx, ref, image.PullOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to pull image")
+	}
+	defer progress.Close()
+
+	// The pull is done once its progress is read, an error of the pull is
+	// reported in the progress.
+	if err := jsonmessage.DisplayJSONMessagesStream(progress, io.Discard, 0, false, nil); err != nil {
+		return errors.Wrap(err, "failed to pull image")
+	}
+	return nil
+}
+
+// startAndWait start a container, wait for it to exit and return its logs
+// read with options, streamed to w when set. The container is stopped when
+// ctx is canceled.
+func startAndWait(ctx context.Context, cli DockerClient, ID string, options container.LogsOptions, w io.Writer) (string, error) {
+	// Register the wait before the start to not miss a fast exit.
+	statusCh, errCh := cli.ContainerWait(ctx, ID, container.WaitConditionNextExit)
+
+	if err := cli.ContainerStart(ctx, ID, container.StartOptions{}); err != nil {
+		return "", errors.Wrap(err, "failed to start container")
+	}
+
+	// The logs are followed until the container exit, so they are streamed
+	// while it is running.
+	var out strings.Builder
+	options.Follow = true
+	logs, logsErr := cli.ContainerLogs(ctx, ID, options)
+	if logsErr == nil {
+		stream := outputWriter(&out, w)
+		_, logsErr = stdcopy.StdCopy(stream, stream, logs)
+		logs.Close()
+	}
+
+	var status container.WaitResponse
+	var err error
+	select {
+	case status = <-statusCh:
+	case err = <-errCh:
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			cli.ContainerStop(context.Background(), ID, container.StopOptions{})
+		}
+		return out.String(), errors.Wrap(err, "failed to wait container")
+	}
+	if logsErr != nil {
+		return out.String(), errors.Wrap(logsErr, "failed to read container logs")
+	}
+
+	if status.Error != nil {
+		return out.String(), errors.New(status.Error.Message)
+	}
+	if status.StatusCode != 0 {
+		return out.String(), &exitError{int(status.StatusCode)}
+	}
+	return out.String(), nil
+}
+
+// outputWriter return the writer of the output of a run, copied to w when
+// set.
+func outputWriter(out *strings.Builder, w io.Writer) io.Writer {
+	if w == nil {
+		return out
+	}
+	return io.MultiWriter(out, w)
+}
+
+// envList convert env to a sorted list of 'key=value'.
+func envList(env map[string]string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+	list := make([]string, 0, len(env))
+	for k, v := range env {
+		list = append(list, k+"="+v)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// splitList split a comma separated label value, ignoring empty items.
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
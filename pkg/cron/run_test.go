@@ -0,0 +1,312 @@
+package cron
+
+import (
+	"bytes"
+	context "context"
+	"io"
+	"strings"
+	"testing"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// logStream return a multiplexed stream of container logs.
+func logStream(stdout string, stderr string) io.ReadCloser {
+	var b bytes.Buffer
+	frame := func(stream byte, data string) {
+		if data == "" {
+			return
+		}
+		n := len(data)
+		b.Write([]byte{stream, 0, 0, 0, byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)})
+		b.WriteString(data)
+	}
+	frame(1, stdout)
+	frame(2, stderr)
+	return io.NopCloser(&b)
+}
+
+// followStream return a stream of the logs of a running container, followed
+// until ctx is canceled.
+func followStream(ctx context.Context) io.ReadCloser {
+	r, w := io.Pipe()
+	context.AfterFunc(ctx, func() { w.CloseWithError(ctx.Err()) })
+	return r
+}
+
+// waitResult return the channels of a completed container wait.
+func waitResult(status container.WaitResponse, err error) (<-chan container.WaitResponse, <-chan error) {
+	statusCh := make(chan container.WaitResponse, 1)
+	errCh := make(chan error, 1)
+	if err != nil {
+		errCh <- err
+	} else {
+		statusCh <- status
+	}
+	return statusCh, errCh
+}
+
+func TestRunContainer(t *testing.T) {
+	type checkFunc func(*testing.T, string, error)
+	check := func(fns ...checkFunc) []checkFunc { return fns }
+
+	type mockFunc func(*MockDockerClient)
+
+	hasOutput := func(want string) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.Equal(t, out, want)
+		}
+	}
+
+	hasError := func(want string) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.Assert(t, is.ErrorContains(err, want))
+		}
+	}
+
+	hasNilError := func() checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.NilError(t, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		opts   runOptions
+		cancel bool
+		mock   mockFunc
+		checks []checkFunc
+	}{
+		{
+			name: "run and remove container",
+			opts: runOptions{
+				Image:      "alpine",
+				Cmd:        []string{"echo", "hello"},
+				Env:        []string{"A=1"},
+				Mounts:     []string{"/data:/data:ro"},
+				Network:    "backend",
+				AutoRemove: true,
+			},
+			mock: func(cli *MockDockerClient) {
+				config := &container.Config{Image: "alpine", Cmd: []string{"echo", "hello"}, Env: []string{"A=1"}, Labels: map[string]string{"mobycron.run": "true"}}
+				hostConfig := &container.HostConfig{Binds: []string{"/data:/data:ro"}, NetworkMode: "backend"}
+				gomock.InOrder(
+					cli.EXPECT().ContainerCreate(gomock.Any(), config, hostConfig, &network.NetworkingConfig{}, nil, "").Return(container.CreateResponse{ID: "c1"}, nil),
+					cli.EXPECT().ContainerWait(gomock.Any(), "c1", container.WaitConditionNextExit).Return(waitResult(container.WaitResponse{StatusCode: 0}, nil)),
+					cli.EXPECT().ContainerStart(gomock.Any(), "c1", container.StartOptions{}),
+					cli.EXPECT().ContainerLogs(gomock.Any(), "c1", container.LogsOptions{ShowStdout: true, ShowStderr: true, Follow: true}).Return(logStream("hello\n", "warn\n"), nil),
+					cli.EXPECT().ContainerRemove(gomock.Any(), "c1", container.RemoveOptions{Force: true}),
+				)
+			},
+			checks: check(
+				hasNilError(),
+				hasOutput("hello\nwarn\n"),
+			),
+		},
+		{
+			name: "exit code not zero",
+			opts: runOptions{Image: "alpine"},
+			mock: func(cli *MockDockerClient) {
+				cli.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(container.CreateResponse{ID: "c1"}, nil)
+				cli.EXPECT().ContainerWait(gomock.Any(), gomock.Any(), gomock.Any()).Return(waitResult(container.WaitResponse{StatusCode: 2}, nil))
+				cli.EXPECT().ContainerStart(gomock.Any(), gomock.Any(), gomock.Any())
+				cli.EXPECT().ContainerLogs(gomock.Any(), gomock.Any(), gomock.Any()).Return(logStream("", "failed\n"), nil)
+			},
+			checks: check(
+				hasError("exit status 2"),
+				hasOutput("failed\n"),
+			),
+		},
+		{
+			name: "wait status error",
+			opts: runOptions{Image: "alpine"},
+			mock: func(cli *MockDockerClient) {
+				cli.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(container.CreateResponse{ID: "c1"}, nil)
+				cli.EXPECT().ContainerWait(gomock.Any(), gomock.Any(), gomock.Any()).Return(waitResult(container.WaitResponse{StatusCode: 1, Error: &container.WaitExitError{Message: "oom"}}, nil))
+				cli.EXPECT().ContainerStart(gomock.Any(), gomock.Any(), gomock.Any())
+				cli.EXPECT().ContainerLogs(gomock.Any(), gomock.Any(), gomock.Any()).Return(logStream("", ""), nil)
+			},
+			checks: check(
+				hasError("oom"),
+			),
+		},
+		{
+			name: "create error",
+			opts: runOptions{Image: "alpine", AutoRemove: true},
+			mock: func(cli *MockDockerClient) {
+				cli.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(container.CreateResponse{}, errors.New("no such image"))
+			},
+			checks: check(
+				hasError("failed to create container: no such image"),
+			),
+		},
+		{
+			name: "pull missing image",
+			opts: runOptions{Image: "alpine:3"},
+			mock: func(cli *MockDockerClient) {
+				gomock.InOrder(
+					cli.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(container.CreateResponse{}, cerrdefs.ErrNotFound.WithMessage("No such image: alpine:3")),
+					cli.EXPECT().ImagePull(gomock.Any(), "alpine:3", image.PullOptions{}).Return(io.NopCloser(strings.NewReader(`{"status":"Pull complete"}`)), nil),
+					cli.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(container.CreateResponse{ID: "c1"}, nil),
+					cli.EXPECT().ContainerWait(gomock.Any(), "c1", gomock.Any()).Return(waitResult(container.WaitResponse{StatusCode: 0}, nil)),
+					cli.EXPECT().ContainerStart(gomock.Any(), "c1", gomock.Any()),
+					cli.EXPECT().ContainerLogs(gomock.Any(), "c1", gomock.Any()).Return(logStream("hello\n", ""), nil),
+				)
+			},
+			checks: check(
+				hasNilError(),
+				hasOutput("hello\n"),
+			),
+		},
+		{
+			name: "pull error",
+			opts: runOptions{Image: "alpine:3"},
+			mock: func(cli *MockDockerClient) {
+				cli.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(container.CreateResponse{}, cerrdefs.ErrNotFound)
+				cli.EXPECT().ImagePull(gomock.Any(), "alpine:3", gomock.Any()).Return(nil, errors.New("registry down"))
+			},
+			checks: check(
+				hasError("failed to pull image: registry down"),
+			),
+		},
+		{
+			name: "pull error in progress",
+			opts: runOptions{Image: "alpine:3"},
+			mock: func(cli *MockDockerClient) {
+				cli.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(container.CreateResponse{}, cerrdefs.ErrNotFound)
+				cli.EXPECT().ImagePull(gomock.Any(), "alpine:3", gomock.Any()).Return(io.NopCloser(strings.NewReader(`{"errorDetail":{"message":"manifest unknown"},"error":"manifest unknown"}`)), nil)
+			},
+			checks: check(
+				hasError("failed to pull image: manifest unknown"),
+			),
+		},
+		{
+			name: "start error",
+			opts: runOptions{Image: "alpine", AutoRemove: true},
+			mock: func(cli *MockDockerClient) {
+				cli.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(container.CreateResponse{ID: "c1"}, nil)
+				cli.EXPECT().ContainerWait(gomock.Any(), gomock.Any(), gomock.Any()).Return(make(chan container.WaitResponse), make(chan error))
+				cli.EXPECT().ContainerStart(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("start error"))
+				cli.EXPECT().ContainerRemove(gomock.Any(), "c1", container.RemoveOptions{Force: true})
+			},
+			checks: check(
+				hasError("failed to start container: start error"),
+			),
+		},
+		{
+			name:   "canceled while running",
+			opts:   runOptions{Image: "alpine"},
+			cancel: true,
+			mock: func(cli *MockDockerClient) {
+				cli.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(container.CreateResponse{ID: "c1"}, nil)
+				cli.EXPECT().ContainerWait(gomock.Any(), gomock.Any(), gomock.Any()).Return(waitResult(container.WaitResponse{}, context.Canceled))
+				cli.EXPECT().ContainerStart(gomock.Any(), gomock.Any(), gomock.Any())
+				cli.EXPECT().ContainerLogs(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, ID string, options container.LogsOptions) (io.ReadCloser, error) {
+					return followStream(ctx), nil
+				})
+				cli.EXPECT().ContainerStop(context.Background(), "c1", container.StopOptions{})
+			},
+			checks: check(
+				hasError("failed to wait container: context canceled"),
+			),
+		},
+		{
+			name: "logs error",
+			opts: runOptions{Image: "alpine"},
+			mock: func(cli *MockDockerClient) {
+				cli.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(container.CreateResponse{ID: "c1"}, nil)
+				cli.EXPECT().ContainerWait(gomock.Any(), gomock.Any(), gomock.Any()).Return(waitResult(container.WaitResponse{}, nil))
+				cli.EXPECT().ContainerStart(gomock.Any(), gomock.Any(), gomock.Any())
+				cli.EXPECT().ContainerLogs(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("logs error"))
+			},
+			checks: check(
+				hasError("failed to read container logs: logs error"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			cli := NewMockDockerClient(ctrl)
+			if tt.mock != nil {
+				tt.mock(cli)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			// Act
+			out, err := runContainer(ctx, cli, tt.opts, nil)
+
+			// Assert
+			for _, check := range tt.checks {
+				check(t, out, err)
+			}
+		})
+	}
+}
+
+// signalWriter signal each write done on it.
+type signalWriter struct {
+	bytes.Buffer
+	written chan struct{}
+}
+
+func (w *signalWriter) Write(p []byte) (int, error) {
+	n, err := w.Buffer.Write(p)
+	w.written <- struct{}{}
+	return n, err
+}
+
+func TestStartAndWaitStream(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cli := NewMockDockerClient(ctrl)
+
+	logs, running := io.Pipe()
+	statusCh := make(chan container.WaitResponse, 1)
+	cli.EXPECT().ContainerWait(gomock.Any(), "c1", gomock.Any()).Return(statusCh, make(chan error))
+	cli.EXPECT().ContainerStart(gomock.Any(), "c1", gomock.Any())
+	cli.EXPECT().ContainerLogs(gomock.Any(), "c1", container.LogsOptions{ShowStdout: true, Follow: true}).Return(logs, nil)
+
+	w := &signalWriter{written: make(chan struct{}, 10)}
+	go func() {
+		io.Copy(running, logStream("hello\n", ""))
+		// The container exit only once its output is streamed to w.
+		<-w.written
+		running.Close()
+		statusCh <- container.WaitResponse{}
+	}()
+
+	// Act
+	out, err := startAndWait(context.Background(), cli, "c1", container.LogsOptions{ShowStdout: true}, w)
+
+	// Assert
+	assert.NilError(t, err)
+	assert.Equal(t, out, "hello\n")
+	assert.Equal(t, w.String(), "hello\n")
+}
+
+func TestEnvList(t *testing.T) {
+	assert.Assert(t, is.Nil(envList(nil)))
+	assert.Assert(t, is.DeepEqual(envList(map[string]string{"B": "2", "A": "1"}), []string{"A=1", "B=2"}))
+}
+
+func TestSplitList(t *testing.T) {
+	assert.Assert(t, is.Nil(splitList("")))
+	assert.Assert(t, is.DeepEqual(splitList("a, b,,c "), []string{"a", "b", "c"}))
+}
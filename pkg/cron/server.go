@@ -0,0 +1,183 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+// Server expose the jobs of cron through an HTTP JSON API.
+type Server struct {
+	cron Scheduler
+	srv  *http.Server
+}
+
+// NewServer returns a HTTP server listening on addr.
+func NewServer(cron Scheduler, addr string) *Server {
+	s := &Server{cron: cron}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /jobs", s.listJobs)
+	mux.HandleFunc("GET /jobs/{id}", s.getJob)
+	mux.HandleFunc("POST /jobs/{id}/run", s.triggerJob)
+	mux.HandleFunc("POST /jobs/{id}/pause", s.pauseJob)
+	mux.HandleFunc("POST /jobs/{id}/resume", s.resumeJob)
+	mux.Handle("GET /metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+
+	s.srv = &http.Server{Addr: addr, Handler: mux}
+	return s
+}
+
+// ListenAndServe listen on the address of the server and serve the API.
+func (s *Server) ListenAndServe() error {
+	log.WithFields(log.Fields{
+		"func": "Server.ListenAndServe",
+		"addr": s.srv.Addr,
+	}).Infoln("start http server")
+
+	if err := s.srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Shutdown gracefully stop the server.
+func (s *Server) Shutdown(ctx context.Context) error {
+	log.WithFields(log.Fields{"func": "Server.Shutdown"}).Infoln("stop http server")
+	return s.srv.Shutdown(ctx)
+}
+
+func (s *Server) listJobs(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.cron.Jobs())
+}
+
+// jobID return the ID of the job of the request, identified by its ID or its
+// name.
+func (s *Server) jobID(r *http.Request) (int, error) {
+	if ID, err := strconv.Atoi(r.PathValue("id")); err == nil {
+		return ID, nil
+	}
+	job, err := s.cron.FindJob(r.PathValue("id"))
+	if err != nil {
+		return 0, err
+	}
+	return job.ID, nil
+}
+
+func (s *Server) getJob(w http.ResponseWriter, r *http.Request) {
+	ID, err := s.jobID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	job, err := s.cron.Job(ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (s *Server) triggerJob(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("wait") == "true" {
+		s.runJob(w, r)
+		return
+	}
+	s.control(w, r, s.cron.TriggerJob, http.StatusAccepted)
+}
+
+// runJob run the job in the foreground and stream its output in the response
+// body. The exit code and error of the run are sent in the trailers.
+func (s *Server) runJob(w http.ResponseWriter, r *http.Request) {
+	ID, err := s.jobID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	job, err := s.cron.Job(ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if job.Paused {
+		writeError(w, ErrJobPaused)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Trailer", ExitCodeTrailer+", "+ErrorTrailer)
+	w.WriteHeader(http.StatusOK)
+
+	err = s.cron.RunJob(ID, flushWriter{w})
+	w.Header().Set(ExitCodeTrailer, strconv.Itoa(ExitCode(err)))
+	if err != nil {
+		w.Header().Set(ErrorTrailer, strings.ReplaceAll(err.Error(), "\n", " "))
+	}
+}
+
+func (s *Server) pauseJob(w http.ResponseWriter, r *http.Request) {
+	s.control(w, r, s.cron.PauseJob, http.StatusOK)
+}
+
+func (s *Server) resumeJob(w http.ResponseWriter, r *http.Request) {
+	s.control(w, r, s.cron.ResumeJob, http.StatusOK)
+}
+
+func (s *Server) control(w http.ResponseWriter, r *http.Request, action func(int) error, status int) {
+	ID, err := s.jobID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := action(ID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	job, err := s.cron.Job(ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, status, job)
+}
+
+// flushWriter send each write to the client without buffering.
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if fl, ok := f.w.(http.Flusher); ok {
+		fl.Flush()
+	}
+	return n, err
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.WithFields(log.Fields{"func": "cron.writeJSON"}).WithError(err).Errorln("failed to write http response")
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch errors.Cause(err) {
+	case ErrJobNotFound:
+		status = http.StatusNotFound
+	case ErrJobPaused:
+		status = http.StatusConflict
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,259 @@
+package cron
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestServer(t *testing.T) {
+	type checkFunc func(*testing.T, *httptest.ResponseRecorder)
+	check := func(fns ...checkFunc) []checkFunc { return fns }
+
+	type mockFunc func(*MockScheduler)
+
+	hasStatus := func(want int) checkFunc {
+		return func(t *testing.T, rec *httptest.ResponseRecorder) {
+			assert.Assert(t, is.Equal(rec.Code, want))
+			assert.Assert(t, is.Equal(rec.Header().Get("Content-Type"), "application/json"))
+		}
+	}
+
+	hasBody := func(want string) checkFunc {
+		return func(t *testing.T, rec *httptest.ResponseRecorder) {
+			assert.Assert(t, is.Equal(strings.TrimSpace(rec.Body.String()), want))
+		}
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		mock   mockFunc
+		checks []checkFunc
+	}{
+		{
+			name:   "list jobs",
+			method: http.MethodGet,
+			path:   "/jobs",
+			mock: func(s *MockScheduler) {
+				s.EXPECT().Jobs().Return([]JobInfo{{ID: 1, Source: "file", Schedule: "1 * * * *", Command: "echo"}})
+			},
+			checks: check(
+				hasStatus(http.StatusOK),
+				hasBody(`[{"id":1,"source":"file","schedule":"1 * * * *","command":"echo","paused":false,"next":"0001-01-01T00:00:00Z","prev":"0001-01-01T00:00:00Z"}]`),
+			),
+		},
+		{
+			name:   "get job",
+			method: http.MethodGet,
+			path:   "/jobs/2",
+			mock: func(s *MockScheduler) {
+				s.EXPECT().Job(2).Return(JobInfo{ID: 2, Source: "service", Schedule: "2 * * * *", Action: "update", Service: "s1"}, nil)
+			},
+			checks: check(
+				hasStatus(http.StatusOK),
+				hasBody(`{"id":2,"source":"service","schedule":"2 * * * *","action":"update","service":"s1","paused":false,"next":"0001-01-01T00:00:00Z","prev":"0001-01-01T00:00:00Z"}`),
+			),
+		},
+		{
+			name:   "get job not found",
+			method: http.MethodGet,
+			path:   "/jobs/2",
+			mock: func(s *MockScheduler) {
+				s.EXPECT().Job(2).Return(JobInfo{}, ErrJobNotFound)
+			},
+			checks: check(
+				hasStatus(http.StatusNotFound),
+				hasBody(`{"error":"job not found"}`),
+			),
+		},
+		{
+			name:   "get job with invalid id",
+			method: http.MethodGet,
+			path:   "/jobs/abc",
+			mock: func(s *MockScheduler) {
+				s.EXPECT().FindJob("abc").Return(JobInfo{}, ErrJobNotFound)
+			},
+			checks: check(
+				hasStatus(http.StatusNotFound),
+			),
+		},
+		{
+			name:   "get job by name",
+			method: http.MethodGet,
+			path:   "/jobs/backup.sh",
+			mock: func(s *MockScheduler) {
+				s.EXPECT().FindJob("backup.sh").Return(JobInfo{ID: 2}, nil)
+				s.EXPECT().Job(2).Return(JobInfo{ID: 2, Name: "backup.sh", Source: "file", Schedule: "2 * * * *", Command: "backup.sh"}, nil)
+			},
+			checks: check(
+				hasStatus(http.StatusOK),
+				hasBody(`{"id":2,"name":"backup.sh","source":"file","schedule":"2 * * * *","command":"backup.sh","paused":false,"next":"0001-01-01T00:00:00Z","prev":"0001-01-01T00:00:00Z"}`),
+			),
+		},
+		{
+			name:   "run paused job and wait",
+			method: http.MethodPost,
+			path:   "/jobs/1/run?wait=true",
+			mock: func(s *MockScheduler) {
+				s.EXPECT().Job(1).Return(JobInfo{ID: 1, Paused: true}, nil)
+			},
+			checks: check(
+				hasStatus(http.StatusConflict),
+				hasBody(`{"error":"job is paused"}`),
+			),
+		},
+		{
+			name:   "trigger job",
+			method: http.MethodPost,
+			path:   "/jobs/1/run",
+			mock: func(s *MockScheduler) {
+				s.EXPECT().TriggerJob(1)
+				s.EXPECT().Job(1).Return(JobInfo{ID: 1}, nil)
+			},
+			checks: check(
+				hasStatus(http.StatusAccepted),
+			),
+		},
+		{
+			name:   "trigger paused job",
+			method: http.MethodPost,
+			path:   "/jobs/1/run",
+			mock: func(s *MockScheduler) {
+				s.EXPECT().TriggerJob(1).Return(ErrJobPaused)
+			},
+			checks: check(
+				hasStatus(http.StatusConflict),
+				hasBody(`{"error":"job is paused"}`),
+			),
+		},
+		{
+			name:   "pause job",
+			method: http.MethodPost,
+			path:   "/jobs/1/pause",
+			mock: func(s *MockScheduler) {
+				s.EXPECT().PauseJob(1)
+				s.EXPECT().Job(1).Return(JobInfo{ID: 1, Paused: true}, nil)
+			},
+			checks: check(
+				hasStatus(http.StatusOK),
+				hasBody(`{"id":1,"source":"","schedule":"","paused":true,"next":"0001-01-01T00:00:00Z","prev":"0001-01-01T00:00:00Z"}`),
+			),
+		},
+		{
+			name:   "resume job",
+			method: http.MethodPost,
+			path:   "/jobs/1/resume",
+			mock: func(s *MockScheduler) {
+				s.EXPECT().ResumeJob(1)
+				s.EXPECT().Job(1).Return(JobInfo{ID: 1}, nil)
+			},
+			checks: check(
+				hasStatus(http.StatusOK),
+			),
+		},
+		{
+			name:   "metrics",
+			method: http.MethodGet,
+			path:   "/metrics",
+			checks: check(
+				func(t *testing.T, rec *httptest.ResponseRecorder) {
+					assert.Assert(t, is.Equal(rec.Code, http.StatusOK))
+					assert.Assert(t, is.Contains(rec.Body.String(), "mobycron_jobs_running"))
+				},
+			),
+		},
+		{
+			name:   "unknown error",
+			method: http.MethodPost,
+			path:   "/jobs/1/resume",
+			mock: func(s *MockScheduler) {
+				s.EXPECT().ResumeJob(1).Return(errors.New("unknown"))
+			},
+			checks: check(
+				hasStatus(http.StatusInternalServerError),
+				hasBody(`{"error":"unknown"}`),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			out := &bytes.Buffer{}
+			log.SetOutput(out)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			s := NewMockScheduler(ctrl)
+			if tt.mock != nil {
+				tt.mock(s)
+			}
+
+			srv := NewServer(s, ":0")
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+
+			// Act
+			srv.srv.Handler.ServeHTTP(rec, req)
+
+			// Assert
+			for _, check := range tt.checks {
+				check(t, rec)
+			}
+		})
+	}
+}
+
+func TestServerRunJob(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		exitCode string
+		error    string
+	}{
+		{name: "success", exitCode: "0"},
+		{name: "exit code", err: &exitError{3}, exitCode: "3", error: "exit status 3"},
+		{name: "error", err: errors.New("failed\nto run"), exitCode: "-1", error: "failed to run"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			s := NewMockScheduler(ctrl)
+			s.EXPECT().FindJob("backup.sh").Return(JobInfo{ID: 1}, nil)
+			s.EXPECT().Job(1).Return(JobInfo{ID: 1}, nil)
+			s.EXPECT().RunJob(1, gomock.Any()).DoAndReturn(func(ID int, w io.Writer) error {
+				io.WriteString(w, "output\n")
+				return tt.err
+			})
+
+			srv := NewServer(s, ":0")
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/jobs/backup.sh/run?wait=true", nil)
+
+			// Act
+			srv.srv.Handler.ServeHTTP(rec, req)
+
+			// Assert
+			res := rec.Result()
+			assert.Equal(t, res.StatusCode, http.StatusOK)
+			assert.Equal(t, res.Header.Get("Content-Type"), "text/plain; charset=utf-8")
+			assert.Equal(t, rec.Body.String(), "output\n")
+			assert.Equal(t, res.Trailer.Get(ExitCodeTrailer), tt.exitCode)
+			assert.Equal(t, res.Trailer.Get(ErrorTrailer), tt.error)
+		})
+	}
+}
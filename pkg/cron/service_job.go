@@ -2,32 +2,94 @@ package cron
 
 import (
 	context "context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/swarm"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 )
 
-// ServiceJob run a docker service task on a schedule.
+// replicasLabel is the service label where the 'scaledown' action keep the
+// original number of tasks of the service.
+const replicasLabel = "mobycron.scaledown.replicas"
+
+// maxConflicts is the number of times the action of a service job is done
+// when the service is changed during the update.
+const maxConflicts = 3
+
+// taskPollInterval is the delay between two checks of the tasks of a
+// job-mode service.
+const taskPollInterval = time.Second
+
+// defaultRunTimeout is the maximum time the 'run' action wait for the tasks
+// of the service when the job has no timeout.
+const defaultRunTimeout = time.Hour
+
+// taskPoll wait for the next check of the tasks of a job-mode service.
+var taskPoll = time.After
+
+// ServiceJob run a docker service task on a schedule. Name is the name of the
+// job, by default the service name and the action. Replicas is the number of
+// tasks of the 'scale' and 'scaledown' actions. The 'scaledown' action keep
+// the original number of tasks in the service labels and the 'scaleup' action
+// restore it. The 'run' action start a job-mode service and wait for its tasks
+// to complete, at most Timeout seconds or one hour. The 'exec' action run Command
+// in a running task of the service on the local node, the task of Slot when
+// set. ServiceVersion is the version of the service when the job was added,
+// each run read the current service.
 type ServiceJob struct {
+	Name             string
 	Schedule         string
 	Action           string
+	Command          string
+	Slot             string
+	Replicas         string
+	Timeout          string
+	Concurrency      string
+	Catchup          string
+	Retry            Retry
+	Notify           Notify
+	Ping             Ping
+	MailTo           string
+	MailOn           string
 	ServiceID        string
 	ServiceName      string
 	ServiceVersion   swarm.Version
 	ServiceCreatedAt time.Time
-	Service          swarm.Service
 	cron             *Cron
 	cli              DockerClient
+	guard            *guard
 }
 
 // Run a docker container and log the output.
 func (j *ServiceJob) Run() {
+	j.runWith(nil)
+}
+
+// runWith run the job like the scheduler, streaming its output to w when set,
+// and return its error. Only the 'exec' action, with the output of the
+// command, and the 'run' action, with the report of the tasks of the service,
+// have an output.
+func (j *ServiceJob) runWith(w io.Writer) error {
 	log := log.WithFields(log.Fields{
 		"func":         "ServiceJob.Run",
+		"name":         j.labels()["name"],
 		"schedule":     j.Schedule,
 		"action":       j.Action,
+		"command":      j.Command,
+		"slot":         j.Slot,
+		"replicas":     j.Replicas,
+		"timeout":      j.Timeout,
+		"concurrency":  j.Concurrency,
 		"service.ID":   j.ServiceID,
 		"service.Name": j.ServiceName,
 	})
@@ -35,22 +97,473 @@ func (j *ServiceJob) Run() {
 
 	j.cron.sync.Add(1)
 	defer j.cron.sync.Done()
+
+	ctx, release, ok := j.guard.acquire(log)
+	if !ok {
+		return ErrJobSkipped
+	}
+	defer release()
+
+	jobsRunning.Inc()
+	defer jobsRunning.Dec()
+	start := time.Now()
+
 	defer j.cli.Close()
-	var err error
+
+	j.Ping.start(log)
+	out, attempt, err := j.Retry.do(ctx, log, func(ctx context.Context) (string, error) {
+		return j.attempt(ctx, log, w)
+	})
+
+	if j.Retry.Attempts > 1 {
+		log = log.WithField("attempt", attempt)
+	}
+	if out != "" {
+		log = log.WithField("output", out)
+	}
+	if err != nil {
+		log.WithError(err).Errorln("service job completed with error")
+	} else {
+		log.Infoln("service action completed successfully")
+	}
+
+	j.Ping.finish(log, out, err)
+	j.cron.record(j, start, out, err)
+	return err
+}
+
+// attempt do the action of the job once, writing its output to w when set.
+// The action is done again on the new version of the service when the service
+// is changed during the update.
+func (j *ServiceJob) attempt(ctx context.Context, log *log.Entry, w io.Writer) (string, error) {
+	for n := 1; ; n++ {
+		out, err := j.do(ctx, log, w)
+		if !outOfSequence(err) || n >= maxConflicts || ctx.Err() != nil {
+			return out, err
+		}
+		log.WithField("conflict", n).WithError(err).Warnln("service changed during the update, retry on its new version")
+	}
+}
+
+// outOfSequence tell if err is the error of an update done on a version of
+// the service that is no longer the current one.
+func outOfSequence(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "update out of sequence")
+}
+
+// do the action of the job on the current version of the service. The output
+// of the 'exec' action is streamed to w, while the report of the 'run' action
+// is written once the tasks are completed.
+func (j *ServiceJob) do(ctx context.Context, log *log.Entry, w io.Writer) (string, error) {
+	// The service may have changed since the job was added, so only the
+	// change of the action is applied to its current spec.
+	service, _, err := j.cli.ServiceInspectWithRaw(ctx, j.ServiceID, types.ServiceInspectOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to inspect service")
+	}
+	spec := service.Spec
+	options := types.ServiceUpdateOptions{}
 
 	switch j.Action {
 	case "update":
-		var r swarm.ServiceUpdateResponse
-		j.Service.Spec.TaskTemplate.ForceUpdate = j.ServiceVersion.Index
-		r, err = j.cli.ServiceUpdate(context.Background(), j.ServiceID, j.ServiceVersion, j.Service.Spec, types.ServiceUpdateOptions{})
-		for _, w := range r.Warnings {
-			log.Warning(w)
+		spec.TaskTemplate.ForceUpdate++
+	case "exec":
+		return j.exec(ctx, w)
+	case "run":
+		out, err := j.run(ctx, log, service)
+		if w != nil {
+			io.WriteString(w, out)
+		}
+		return out, err
+	case "rollback":
+		if service.PreviousSpec == nil {
+			return "", errors.New("service has no previous spec to roll back to")
+		}
+		options.Rollback = "previous"
+	case "scale", "scaledown", "scaleup":
+		if spec.Mode.Replicated == nil {
+			return "", errors.New("only a replicated service can be scaled")
 		}
+		replicas, ok := j.replicas(&spec)
+		if !ok {
+			log.Infoln("service is not scaled down, nothing to scale up")
+			return "", nil
+		}
+		log = log.WithField("service.Replicas", replicas)
+		spec.Mode.Replicated.Replicas = &replicas
+	}
+
+	r, err := j.cli.ServiceUpdate(ctx, j.ServiceID, service.Version, spec, options)
+	for _, w := range r.Warnings {
+		log.Warning(w)
 	}
+	return "", err
+}
 
+// exec run the command in a running task of the service on the local node, as
+// the 'exec' action of a container job.
+func (j *ServiceJob) exec(ctx context.Context, w io.Writer) (string, error) {
+	info, err := j.cli.Info(ctx)
 	if err != nil {
-		log.WithError(err).Errorln("service job completed with error")
-	} else {
-		log.Infoln("service action completed successfully")
+		return "", errors.Wrap(err, "failed to get local node")
+	}
+	if info.Swarm.NodeID == "" {
+		return "", errors.New("local node is not a swarm node")
+	}
+
+	f := filters.NewArgs()
+	f.Add("service", j.ServiceID)
+	f.Add("node", info.Swarm.NodeID)
+	f.Add("desired-state", "running")
+	tasks, err := j.cli.TaskList(ctx, types.TaskListOptions{Filters: f})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list service tasks")
+	}
+	sort.Slice(tasks, func(a, b int) bool { return tasks[a].Slot < tasks[b].Slot })
+
+	slot, _ := strconv.Atoi(j.Slot)
+	for _, t := range tasks {
+		if t.Status.State != swarm.TaskStateRunning || t.Status.ContainerStatus == nil {
+			continue
+		}
+		if slot != 0 && t.Slot != slot {
+			continue
+		}
+		c := ContainerJob{
+			Command:   j.Command,
+			Container: container.Summary{ID: t.Status.ContainerStatus.ContainerID},
+			cli:       j.cli,
+		}
+		return c.exec(ctx, w)
+	}
+
+	if slot != 0 {
+		return "", errors.Errorf("no running task of service %s in slot %d on this node", j.ServiceName, slot)
+	}
+	return "", errors.Errorf("no running task of service %s on this node", j.ServiceName)
+}
+
+// run start a new iteration of the job-mode service and wait for its tasks to
+// complete. The output report the state and exit code of each task.
+func (j *ServiceJob) run(ctx context.Context, log *log.Entry, service swarm.Service) (string, error) {
+	mode := service.Spec.Mode
+	if mode.ReplicatedJob == nil && mode.GlobalJob == nil {
+		return "", errors.New("only a job-mode service can be run")
+	}
+
+	timeout := defaultRunTimeout
+	if j.Timeout != "" {
+		seconds, _ := strconv.Atoi(j.Timeout)
+		timeout = time.Duration(seconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Like 'docker service update --force', a new iteration of the job is
+	// started by changing ForceUpdate.
+	spec := service.Spec
+	spec.TaskTemplate.ForceUpdate++
+	r, err := j.cli.ServiceUpdate(ctx, j.ServiceID, service.Version, spec, types.ServiceUpdateOptions{})
+	for _, w := range r.Warnings {
+		log.Warning(w)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	service, _, err = j.cli.ServiceInspectWithRaw(ctx, j.ServiceID, types.ServiceInspectOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to inspect service")
+	}
+	if service.JobStatus == nil {
+		return "", errors.New("service has no job status")
+	}
+	iteration := service.JobStatus.JobIteration.Index
+
+	f := filters.NewArgs()
+	f.Add("service", j.ServiceID)
+	for {
+		all, err := j.cli.TaskList(ctx, types.TaskListOptions{Filters: f})
+		if err != nil && ctx.Err() == nil {
+			return "", errors.Wrap(err, "failed to list service tasks")
+		}
+
+		tasks := []swarm.Task{}
+		for _, t := range all {
+			if t.JobIteration != nil && t.JobIteration.Index == iteration {
+				tasks = append(tasks, t)
+			}
+		}
+
+		if jobCompleted(service.Spec, tasks) {
+			return j.report(tasks)
+		}
+
+		select {
+		case <-ctx.Done():
+			out, _ := j.report(tasks)
+			if ctx.Err() == context.DeadlineExceeded {
+				return out, errors.Errorf("timed out after %s", timeout)
+			}
+			return out, ctx.Err()
+		case <-taskPoll(taskPollInterval):
+		}
+	}
+}
+
+// terminalStates are the states of a task that no longer run.
+var terminalStates = map[swarm.TaskState]bool{
+	swarm.TaskStateComplete: true,
+	swarm.TaskStateShutdown: true,
+	swarm.TaskStateFailed:   true,
+	swarm.TaskStateRejected: true,
+	swarm.TaskStateRemove:   true,
+	swarm.TaskStateOrphaned: true,
+}
+
+// jobCompleted tell if the tasks of an iteration of the job-mode service with
+// spec are all done. A replicated job is done once the total completions are
+// reached, unless failed tasks are never restarted or the failed tasks reach
+// the maximum attempts of the restart policy.
+func jobCompleted(spec swarm.ServiceSpec, tasks []swarm.Task) bool {
+	if len(tasks) == 0 {
+		return false
+	}
+
+	completed, failed := uint64(0), uint64(0)
+	for _, t := range tasks {
+		if !terminalStates[t.Status.State] {
+			return false
+		}
+		if t.Status.State == swarm.TaskStateComplete {
+			completed++
+		} else {
+			failed++
+		}
 	}
+
+	job := spec.Mode.ReplicatedJob
+	if job == nil {
+		return true
+	}
+	if p := spec.TaskTemplate.RestartPolicy; p != nil {
+		if p.Condition == swarm.RestartPolicyConditionNone {
+			return true
+		}
+		if p.MaxAttempts != nil && *p.MaxAttempts > 0 && failed >= *p.MaxAttempts {
+			return true
+		}
+	}
+	total := uint64(1)
+	switch {
+	case job.TotalCompletions != nil:
+		total = *job.TotalCompletions
+	case job.MaxConcurrent != nil:
+		total = *job.MaxConcurrent
+	}
+	return completed >= total
+}
+
+// report return a line by task with its state and exit code, and an error
+// when a task did not complete. The error has the exit code of the first
+// failed task.
+func (j *ServiceJob) report(tasks []swarm.Task) (string, error) {
+	sort.Slice(tasks, func(a, b int) bool {
+		if tasks[a].Slot != tasks[b].Slot {
+			return tasks[a].Slot < tasks[b].Slot
+		}
+		if tasks[a].NodeID != tasks[b].NodeID {
+			return tasks[a].NodeID < tasks[b].NodeID
+		}
+		return tasks[a].CreatedAt.Before(tasks[b].CreatedAt)
+	})
+
+	var out strings.Builder
+	var err error
+	failed := 0
+	for _, t := range tasks {
+		name := j.ServiceName + "." + t.NodeID
+		if t.Slot != 0 {
+			name = j.ServiceName + "." + strconv.Itoa(t.Slot)
+		}
+		code := -1
+		if t.Status.ContainerStatus != nil {
+			code = t.Status.ContainerStatus.ExitCode
+		}
+
+		fmt.Fprintf(&out, "%s %s exit code %d", name, t.Status.State, code)
+		if t.Status.Err != "" {
+			fmt.Fprintf(&out, ": %s", t.Status.Err)
+		}
+		out.WriteString("\n")
+
+		if t.Status.State != swarm.TaskStateComplete {
+			switch {
+			case failed > 0:
+			case t.Status.ContainerStatus != nil:
+				err = &exitError{code}
+			default:
+				err = errors.Errorf("task %s", t.Status.State)
+			}
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return out.String(), errors.Wrapf(err, "%d of %d tasks failed", failed, len(tasks))
+	}
+	return out.String(), nil
+}
+
+// replicas return the number of tasks of the service after the scale action
+// and update the labels of spec where the original number is kept. It
+// return false when there is no original number to restore.
+func (j *ServiceJob) replicas(spec *swarm.ServiceSpec) (uint64, bool) {
+	replicas, _ := strconv.ParseUint(j.Replicas, 10, 64)
+
+	switch j.Action {
+	case "scaledown":
+		// A service already scaled down keep its original number.
+		if _, ok := spec.Labels[replicasLabel]; !ok {
+			spec.Labels = copyLabels(spec.Labels)
+			spec.Labels[replicasLabel] = strconv.FormatUint(*spec.Mode.Replicated.Replicas, 10)
+		}
+	case "scaleup":
+		original, ok := spec.Labels[replicasLabel]
+		if !ok {
+			return replicas, j.Replicas != ""
+		}
+		spec.Labels = copyLabels(spec.Labels)
+		delete(spec.Labels, replicasLabel)
+		if n, err := strconv.ParseUint(original, 10, 64); err == nil {
+			replicas = n
+		}
+	}
+	return replicas, true
+}
+
+// copyLabels return a copy of labels that can be changed.
+func copyLabels(labels map[string]string) map[string]string {
+	c := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		c[k] = v
+	}
+	return c
+}
+
+// validate check the fields of the service job.
+func (j *ServiceJob) validate() error {
+	if err := validateName(j.Name); err != nil {
+		return err
+	}
+
+	if j.Schedule == "" {
+		return errors.New("schedule is required")
+	}
+
+	if err := validateConcurrency(j.Concurrency); err != nil {
+		return err
+	}
+
+	if err := validateCatchup(j.Catchup); err != nil {
+		return err
+	}
+
+	if err := j.Retry.validate(); err != nil {
+		return err
+	}
+
+	if err := j.Notify.validate(); err != nil {
+		return err
+	}
+
+	if err := j.Ping.validate(); err != nil {
+		return err
+	}
+
+	if err := validateMailTo(j.MailTo); err != nil {
+		return err
+	}
+
+	if err := validateMailOn(j.MailOn); err != nil {
+		return err
+	}
+
+	if j.Replicas != "" {
+		if _, err := strconv.ParseUint(j.Replicas, 10, 64); err != nil {
+			return errors.New("invalid service replicas, only positive integer are permitted")
+		}
+	}
+
+	if j.Slot != "" {
+		if slot, err := strconv.Atoi(j.Slot); err != nil || slot < 1 {
+			return errors.New("invalid service slot, only positive integer are permitted")
+		}
+		if j.Action != "exec" {
+			return errors.New("a slot can be specified only with 'exec' action")
+		}
+	}
+
+	if j.Command != "" && j.Action != "exec" {
+		return errors.New("a command can be specified only with 'exec' action")
+	}
+
+	if j.Timeout != "" {
+		if _, err := strconv.ParseInt(j.Timeout, 10, 0); err != nil {
+			return errors.New("invalid service timeout, only integer are permitted")
+		}
+		if j.Action != "run" {
+			return errors.New("a timeout can be specified only with 'run' action")
+		}
+	}
+
+	switch j.Action {
+	case "update", "run", "rollback", "scaledown", "scaleup":
+	case "exec":
+		if j.Command == "" {
+			return errors.New("command is required")
+		}
+	case "scale":
+		if j.Replicas == "" {
+			return errors.New("replicas is required")
+		}
+	default:
+		return errors.New("invalid service action, only 'update', 'exec', 'run', 'scale', 'rollback', 'scaledown' and 'scaleup' are permitted")
+	}
+	return nil
+}
+
+// labels identify the job in metrics.
+func (j *ServiceJob) labels() prometheus.Labels {
+	name := j.Name
+	if name == "" {
+		name = j.ServiceName + "-" + j.Action
+	}
+	return prometheus.Labels{"name": name, "source": "service", "container": "", "service": j.ServiceName}
+}
+
+// id identify the job across restarts by its service and action.
+func (j *ServiceJob) id() string {
+	return "service/" + j.ServiceName + "-" + j.Action
+}
+
+// scheduleSpec return the schedule of the job.
+func (j *ServiceJob) scheduleSpec() string {
+	return j.Schedule
+}
+
+// catchupPolicy return the policy applied to the runs missed by the job.
+func (j *ServiceJob) catchupPolicy() string {
+	return j.Catchup
+}
+
+// notifyPolicy return the webhooks notified of the runs of the job.
+func (j *ServiceJob) notifyPolicy() Notify {
+	return j.Notify
+}
+
+// mailPolicy return the addresses and the condition of the mails of the runs
+// of the job.
+func (j *ServiceJob) mailPolicy() (string, string) {
+	return j.MailTo, j.MailOn
 }
@@ -1,13 +1,19 @@
 package cron
 
 import (
+	"bufio"
 	"bytes"
 	context "context"
 	"fmt"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/api/types/system"
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -35,8 +41,8 @@ func TestServiceJobRun(t *testing.T) {
 		command        string
 		serviceID      string
 		serviceName    string
+		replicas       string
 		serviceVersion swarm.Version
-		service        swarm.Service
 		mock           mockFunc
 		checks         []checkFunc
 	}{
@@ -48,10 +54,10 @@ func TestServiceJobRun(t *testing.T) {
 			serviceID:      "ID1",
 			serviceName:    "s1",
 			serviceVersion: swarm.Version{Index: 1},
-			service:        swarm.Service{},
 			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
 				s.EXPECT().Add(1)
-				cli.EXPECT().ServiceUpdate(context.Background(), "ID1", swarm.Version{Index: 1}, swarm.ServiceSpec{TaskTemplate: swarm.TaskSpec{ForceUpdate: 1}}, types.ServiceUpdateOptions{})
+				cli.EXPECT().ServiceInspectWithRaw(context.Background(), "ID1", types.ServiceInspectOptions{}).Return(swarm.Service{ID: "ID1", Meta: swarm.Meta{Version: swarm.Version{Index: 7}}, Spec: swarm.ServiceSpec{TaskTemplate: swarm.TaskSpec{ForceUpdate: 2}}}, nil, nil)
+				cli.EXPECT().ServiceUpdate(context.Background(), "ID1", swarm.Version{Index: 7}, swarm.ServiceSpec{TaskTemplate: swarm.TaskSpec{ForceUpdate: 3}}, types.ServiceUpdateOptions{})
 				cli.EXPECT().Close()
 				s.EXPECT().Done()
 			},
@@ -65,11 +71,11 @@ func TestServiceJobRun(t *testing.T) {
 			),
 		},
 		{
-			name:    "service update error",
-			action:  "update",
-			service: swarm.Service{},
+			name:   "service update error",
+			action: "update",
 			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
 				s.EXPECT().Add(1)
+				cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), gomock.Any(), gomock.Any())
 				cli.EXPECT().ServiceUpdate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(swarm.ServiceUpdateResponse{}, errors.New("update error"))
 				cli.EXPECT().Close()
 				s.EXPECT().Done()
@@ -81,9 +87,8 @@ func TestServiceJobRun(t *testing.T) {
 			),
 		},
 		{
-			name:    "service update warnings",
-			action:  "update",
-			service: swarm.Service{},
+			name:   "service update warnings",
+			action: "update",
 			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
 				r := swarm.ServiceUpdateResponse{
 					Warnings: []string{
@@ -93,6 +98,7 @@ func TestServiceJobRun(t *testing.T) {
 				}
 
 				s.EXPECT().Add(1)
+				cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), gomock.Any(), gomock.Any())
 				cli.EXPECT().ServiceUpdate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(r, nil)
 				cli.EXPECT().Close()
 				s.EXPECT().Done()
@@ -103,6 +109,186 @@ func TestServiceJobRun(t *testing.T) {
 				hasLogField("msg", "w2"),
 			),
 		},
+		{
+			name:      "service changed during update",
+			action:    "update",
+			serviceID: "ID1",
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				deployed := replicatedService(2, map[string]string{"stack": "v2"})
+				deployed.Version.Index = 9
+				want := replicatedService(2, map[string]string{"stack": "v2"}).Spec
+				want.TaskTemplate.ForceUpdate = 1
+
+				s.EXPECT().Add(1)
+				gomock.InOrder(
+					cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any()).Return(replicatedService(2, nil), nil, nil),
+					cli.EXPECT().ServiceUpdate(gomock.Any(), "ID1", swarm.Version{Index: 7}, gomock.Any(), gomock.Any()).Return(swarm.ServiceUpdateResponse{}, errors.New("Error response from daemon: rpc error: code = Unknown desc = update out of sequence")),
+					cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any()).Return(deployed, nil, nil),
+					cli.EXPECT().ServiceUpdate(gomock.Any(), "ID1", swarm.Version{Index: 9}, want, types.ServiceUpdateOptions{}),
+				)
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasLogField("msg", "service changed during the update, retry on its new version"),
+				hasLogField("msg", "service action completed successfully"),
+			),
+		},
+		{
+			name:      "service always changed during update",
+			action:    "update",
+			serviceID: "ID1",
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				s.EXPECT().Add(1)
+				cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any()).Return(replicatedService(2, nil), nil, nil).Times(3)
+				cli.EXPECT().ServiceUpdate(gomock.Any(), "ID1", gomock.Any(), gomock.Any(), gomock.Any()).Return(swarm.ServiceUpdateResponse{}, errors.New("update out of sequence")).Times(3)
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasLogField("msg", "service job completed with error"),
+				hasLogField("error", "update out of sequence"),
+			),
+		},
+		{
+			name:      "service inspect error",
+			action:    "update",
+			serviceID: "ID1",
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				s.EXPECT().Add(1)
+				cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any()).Return(swarm.Service{}, nil, errors.New("no such service"))
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasLogField("level", "error"),
+				hasLogField("error", "failed to inspect service: no such service"),
+			),
+		},
+		{
+			name:      "service scale",
+			action:    "scale",
+			replicas:  "3",
+			serviceID: "ID1",
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				s.EXPECT().Add(1)
+				cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any()).Return(replicatedService(1, nil), nil, nil)
+				cli.EXPECT().ServiceUpdate(gomock.Any(), "ID1", swarm.Version{Index: 7}, replicatedService(3, nil).Spec, types.ServiceUpdateOptions{})
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasLogField("msg", "service action completed successfully"),
+			),
+		},
+		{
+			name:      "service scale not replicated",
+			action:    "scale",
+			replicas:  "3",
+			serviceID: "ID1",
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				s.EXPECT().Add(1)
+				cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any()).Return(swarm.Service{Spec: swarm.ServiceSpec{Mode: swarm.ServiceMode{Global: &swarm.GlobalService{}}}}, nil, nil)
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasLogField("error", "only a replicated service can be scaled"),
+			),
+		},
+		{
+			name:      "service scale down",
+			action:    "scaledown",
+			serviceID: "ID1",
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				s.EXPECT().Add(1)
+				cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any()).Return(replicatedService(4, map[string]string{"mobycron.action": "scaledown"}), nil, nil)
+				cli.EXPECT().ServiceUpdate(gomock.Any(), "ID1", swarm.Version{Index: 7}, replicatedService(0, map[string]string{"mobycron.action": "scaledown", "mobycron.scaledown.replicas": "4"}).Spec, types.ServiceUpdateOptions{})
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasLogField("msg", "service action completed successfully"),
+			),
+		},
+		{
+			name:      "service already scaled down",
+			action:    "scaledown",
+			replicas:  "1",
+			serviceID: "ID1",
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				s.EXPECT().Add(1)
+				cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any()).Return(replicatedService(0, map[string]string{"mobycron.scaledown.replicas": "4"}), nil, nil)
+				cli.EXPECT().ServiceUpdate(gomock.Any(), "ID1", gomock.Any(), replicatedService(1, map[string]string{"mobycron.scaledown.replicas": "4"}).Spec, gomock.Any())
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasLogField("msg", "service action completed successfully"),
+			),
+		},
+		{
+			name:      "service scale up",
+			action:    "scaleup",
+			serviceID: "ID1",
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				s.EXPECT().Add(1)
+				cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any()).Return(replicatedService(0, map[string]string{"mobycron.action": "scaledown", "mobycron.scaledown.replicas": "4"}), nil, nil)
+				cli.EXPECT().ServiceUpdate(gomock.Any(), "ID1", swarm.Version{Index: 7}, replicatedService(4, map[string]string{"mobycron.action": "scaledown"}).Spec, types.ServiceUpdateOptions{})
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasLogField("msg", "service action completed successfully"),
+			),
+		},
+		{
+			name:      "service scale up not scaled down",
+			action:    "scaleup",
+			serviceID: "ID1",
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				s.EXPECT().Add(1)
+				cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any()).Return(replicatedService(2, nil), nil, nil)
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasLogField("msg", "service is not scaled down, nothing to scale up"),
+				hasLogField("msg", "service action completed successfully"),
+			),
+		},
+		{
+			name:      "service rollback",
+			action:    "rollback",
+			serviceID: "ID1",
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				service := replicatedService(2, nil)
+				previous := replicatedService(1, nil)
+				service.PreviousSpec = &previous.Spec
+				s.EXPECT().Add(1)
+				cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any()).Return(service, nil, nil)
+				cli.EXPECT().ServiceUpdate(gomock.Any(), "ID1", swarm.Version{Index: 7}, service.Spec, types.ServiceUpdateOptions{Rollback: "previous"})
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasLogField("msg", "service action completed successfully"),
+			),
+		},
+		{
+			name:      "service rollback without previous spec",
+			action:    "rollback",
+			serviceID: "ID1",
+			mock: func(s *MockJobSynchroniser, cli *MockDockerClient) {
+				s.EXPECT().Add(1)
+				cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any()).Return(replicatedService(2, nil), nil, nil)
+				cli.EXPECT().Close()
+				s.EXPECT().Done()
+			},
+			checks: check(
+				hasLogField("error", "service has no previous spec to roll back to"),
+			),
+		},
 	}
 
 	for _, tt := range tests {
@@ -122,14 +308,14 @@ func TestServiceJobRun(t *testing.T) {
 				tt.mock(s, cli)
 			}
 
-			c := &Cron{nil, s, nil, nil, nil}
+			c := &Cron{sync: s}
 			j := &ServiceJob{
 				Schedule:       tt.schedule,
 				Action:         tt.action,
+				Replicas:       tt.replicas,
 				ServiceID:      tt.serviceID,
 				ServiceName:    tt.serviceName,
 				ServiceVersion: tt.serviceVersion,
-				Service:        tt.service,
 				cron:           c,
 				cli:            cli,
 			}
@@ -144,3 +330,385 @@ func TestServiceJobRun(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceJobRunRetry(t *testing.T) {
+	// Arrange
+	defer noRetryDelay()()
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+	log.SetFormatter(&log.JSONFormatter{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	s := NewMockJobSynchroniser(ctrl)
+	cli := NewMockDockerClient(ctrl)
+	s.EXPECT().Add(1)
+	cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any()).Times(2)
+	cli.EXPECT().ServiceUpdate(gomock.Any(), "ID1", gomock.Any(), gomock.Any(), gomock.Any()).Return(swarm.ServiceUpdateResponse{}, errors.New("update error")).Times(2)
+	cli.EXPECT().Close()
+	s.EXPECT().Done()
+
+	j := &ServiceJob{
+		Schedule:    "1 * * * *",
+		Action:      "update",
+		Retry:       Retry{Attempts: 2},
+		ServiceID:   "ID1",
+		ServiceName: "name1",
+		cron:        &Cron{sync: s},
+		cli:         cli,
+	}
+
+	// Act
+	j.Run()
+
+	// Assert
+	assert.Assert(t, is.Contains(out.String(), "job attempt failed, retry after delay"))
+	assert.Assert(t, is.Contains(out.String(), "\"attempt\":2"))
+	assert.Assert(t, is.Contains(out.String(), "service job completed with error"))
+}
+
+// replicatedService return a service with the number of replicas and labels
+// at version 7.
+func replicatedService(replicas uint64, labels map[string]string) swarm.Service {
+	return swarm.Service{
+		ID:   "ID1",
+		Meta: swarm.Meta{Version: swarm.Version{Index: 7}},
+		Spec: swarm.ServiceSpec{
+			Annotations: swarm.Annotations{Labels: labels},
+			Mode:        swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &replicas}},
+		},
+	}
+}
+
+func TestServiceJobRunJobMode(t *testing.T) {
+	type checkFunc func(*testing.T, string, error)
+	check := func(fns ...checkFunc) []checkFunc { return fns }
+
+	hasOutput := func(want string) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.Equal(t, out, want)
+		}
+	}
+
+	hasError := func(want string) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.Error(t, err, want)
+		}
+	}
+
+	hasExitCode := func(want int) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.Equal(t, ExitCode(err), want)
+		}
+	}
+
+	hasNilError := func() checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.NilError(t, err)
+		}
+	}
+
+	job := swarm.Service{
+		ID:        "ID1",
+		Meta:      swarm.Meta{Version: swarm.Version{Index: 7}},
+		Spec:      swarm.ServiceSpec{Mode: swarm.ServiceMode{ReplicatedJob: &swarm.ReplicatedJob{}}},
+		JobStatus: &swarm.JobStatus{JobIteration: swarm.Version{Index: 8}},
+	}
+	task := func(slot int, iteration uint64, state swarm.TaskState, code int) swarm.Task {
+		return swarm.Task{
+			ID:           fmt.Sprintf("T%d", slot),
+			Slot:         slot,
+			JobIteration: &swarm.Version{Index: iteration},
+			Status:       swarm.TaskStatus{State: state, ContainerStatus: &swarm.ContainerStatus{ExitCode: code}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		timeout string
+		service swarm.Service
+		tasks   [][]swarm.Task
+		checks  []checkFunc
+	}{
+		{
+			name:    "tasks completed",
+			service: job,
+			tasks: [][]swarm.Task{
+				{task(1, 1, swarm.TaskStateComplete, 0)},
+				{task(1, 1, swarm.TaskStateComplete, 0), task(1, 8, swarm.TaskStateRunning, 0)},
+				{task(1, 1, swarm.TaskStateComplete, 0), task(1, 8, swarm.TaskStateComplete, 0)},
+			},
+			checks: check(
+				hasNilError(),
+				hasOutput("job.1 complete exit code 0\n"),
+			),
+		},
+		{
+			name:    "task failed",
+			service: job,
+			tasks: [][]swarm.Task{
+				{task(2, 8, swarm.TaskStateComplete, 0), task(1, 8, swarm.TaskStateFailed, 3)},
+			},
+			checks: check(
+				hasError("1 of 2 tasks failed: exit status 3"),
+				hasExitCode(3),
+				hasOutput("job.1 failed exit code 3\njob.2 complete exit code 0\n"),
+			),
+		},
+		{
+			name:    "timeout",
+			timeout: "0",
+			service: job,
+			tasks: [][]swarm.Task{
+				{task(1, 8, swarm.TaskStateRunning, 0)},
+			},
+			checks: check(
+				hasError("timed out after 0s"),
+				hasOutput("job.1 running exit code 0\n"),
+			),
+		},
+		{
+			name:    "not a job-mode service",
+			service: replicatedService(1, nil),
+			checks: check(
+				hasError("only a job-mode service can be run"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			log.SetOutput(&bytes.Buffer{})
+			taskPoll = func(time.Duration) <-chan time.Time {
+				c := make(chan time.Time, 1)
+				if tt.timeout == "" {
+					c <- time.Now()
+				}
+				return c
+			}
+			defer func() { taskPoll = time.After }()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			s := NewMockJobSynchroniser(ctrl)
+			cli := NewMockDockerClient(ctrl)
+			s.EXPECT().Add(1)
+			s.EXPECT().Done()
+			cli.EXPECT().Close()
+
+			cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any()).Return(tt.service, nil, nil)
+			if tt.tasks != nil {
+				spec := tt.service.Spec
+				spec.TaskTemplate.ForceUpdate = 1
+				cli.EXPECT().ServiceUpdate(gomock.Any(), "ID1", swarm.Version{Index: 7}, spec, types.ServiceUpdateOptions{})
+				cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any()).Return(tt.service, nil, nil)
+				f := filters.NewArgs()
+				f.Add("service", "ID1")
+				for _, tasks := range tt.tasks {
+					cli.EXPECT().TaskList(gomock.Any(), types.TaskListOptions{Filters: f}).Return(tasks, nil)
+				}
+			}
+
+			j := &ServiceJob{
+				Schedule:    "1 * * * *",
+				Action:      "run",
+				Timeout:     tt.timeout,
+				ServiceID:   "ID1",
+				ServiceName: "job",
+				cron:        &Cron{sync: s},
+				cli:         cli,
+			}
+			out := &bytes.Buffer{}
+
+			// Act
+			err := j.runWith(out)
+
+			// Assert
+			for _, check := range tt.checks {
+				check(t, out.String(), err)
+			}
+		})
+	}
+}
+
+func TestJobCompleted(t *testing.T) {
+	two := uint64(2)
+	done := swarm.Task{Status: swarm.TaskStatus{State: swarm.TaskStateComplete}}
+	failed := swarm.Task{Status: swarm.TaskStatus{State: swarm.TaskStateFailed}}
+	running := swarm.Task{Status: swarm.TaskStatus{State: swarm.TaskStateRunning}}
+	replicated := swarm.ServiceSpec{Mode: swarm.ServiceMode{ReplicatedJob: &swarm.ReplicatedJob{TotalCompletions: &two}}}
+	global := swarm.ServiceSpec{Mode: swarm.ServiceMode{GlobalJob: &swarm.GlobalJob{}}}
+	noRestart := replicated
+	noRestart.TaskTemplate.RestartPolicy = &swarm.RestartPolicy{Condition: swarm.RestartPolicyConditionNone}
+	maxAttempts := replicated
+	maxAttempts.TaskTemplate.RestartPolicy = &swarm.RestartPolicy{Condition: swarm.RestartPolicyConditionOnFailure, MaxAttempts: &two}
+
+	tests := []struct {
+		name  string
+		spec  swarm.ServiceSpec
+		tasks []swarm.Task
+		want  bool
+	}{
+		{name: "no task", spec: global, want: false},
+		{name: "running task", spec: global, tasks: []swarm.Task{done, running}, want: false},
+		{name: "global tasks done", spec: global, tasks: []swarm.Task{done, failed}, want: true},
+		{name: "total completions reached", spec: replicated, tasks: []swarm.Task{failed, done, done}, want: true},
+		{name: "failed task restarted", spec: replicated, tasks: []swarm.Task{failed, done}, want: false},
+		{name: "failed task never restarted", spec: noRestart, tasks: []swarm.Task{failed, done}, want: true},
+		{name: "failed task below max attempts", spec: maxAttempts, tasks: []swarm.Task{failed, done}, want: false},
+		{name: "failed tasks reach max attempts", spec: maxAttempts, tasks: []swarm.Task{failed, done, failed}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, jobCompleted(tt.spec, tt.tasks), tt.want)
+		})
+	}
+}
+
+func TestServiceJobExec(t *testing.T) {
+	type checkFunc func(*testing.T, string, error)
+	check := func(fns ...checkFunc) []checkFunc { return fns }
+
+	hasOutput := func(want string) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.Equal(t, out, want)
+		}
+	}
+
+	hasError := func(want string) checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.Error(t, err, want)
+		}
+	}
+
+	hasNilError := func() checkFunc {
+		return func(t *testing.T, out string, err error) {
+			assert.NilError(t, err)
+		}
+	}
+
+	task := func(slot int, state swarm.TaskState, containerID string) swarm.Task {
+		return swarm.Task{
+			Slot:   slot,
+			Status: swarm.TaskStatus{State: state, ContainerStatus: &swarm.ContainerStatus{ContainerID: containerID}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		slot   string
+		nodeID string
+		tasks  []swarm.Task
+		exec   string
+		checks []checkFunc
+	}{
+		{
+			name:   "first running task",
+			nodeID: "N1",
+			tasks:  []swarm.Task{task(3, swarm.TaskStateRunning, "c3"), task(2, swarm.TaskStateStarting, "c2"), task(1, swarm.TaskStateRunning, "c1")},
+			exec:   "c1",
+			checks: check(
+				hasNilError(),
+				hasOutput("dump done"),
+			),
+		},
+		{
+			name:   "task of slot",
+			slot:   "3",
+			nodeID: "N1",
+			tasks:  []swarm.Task{task(1, swarm.TaskStateRunning, "c1"), task(3, swarm.TaskStateRunning, "c3")},
+			exec:   "c3",
+			checks: check(
+				hasNilError(),
+				hasOutput("dump done"),
+			),
+		},
+		{
+			name:   "no local task",
+			nodeID: "N1",
+			tasks:  []swarm.Task{},
+			checks: check(
+				hasError("no running task of service db on this node"),
+			),
+		},
+		{
+			name:   "no local task of slot",
+			slot:   "2",
+			nodeID: "N1",
+			tasks:  []swarm.Task{task(1, swarm.TaskStateRunning, "c1")},
+			checks: check(
+				hasError("no running task of service db in slot 2 on this node"),
+			),
+		},
+		{
+			name: "not a swarm node",
+			checks: check(
+				hasError("local node is not a swarm node"),
+			),
+		},
+	}
+
+	defer func(f func() string) { newExecToken = f }(newExecToken)
+	newExecToken = func() string { return "token" }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			log.SetOutput(&bytes.Buffer{})
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			s := NewMockJobSynchroniser(ctrl)
+			cli := NewMockDockerClient(ctrl)
+			s.EXPECT().Add(1)
+			s.EXPECT().Done()
+			cli.EXPECT().Close()
+			cli.EXPECT().ServiceInspectWithRaw(gomock.Any(), "ID1", gomock.Any())
+
+			info := system.Info{}
+			info.Swarm.NodeID = tt.nodeID
+			cli.EXPECT().Info(gomock.Any()).Return(info, nil)
+			if tt.tasks != nil {
+				f := filters.NewArgs()
+				f.Add("service", "ID1")
+				f.Add("node", "N1")
+				f.Add("desired-state", "running")
+				cli.EXPECT().TaskList(gomock.Any(), types.TaskListOptions{Filters: f}).Return(tt.tasks, nil)
+			}
+			if tt.exec != "" {
+				server, client := net.Pipe()
+				go func() {
+					server.Write([]byte{1, 0, 0, 0, 0, 0, 0, 9})
+					server.Write([]byte("dump done"))
+					server.Close()
+				}()
+				cli.EXPECT().ContainerInspect(gomock.Any(), tt.exec)
+				cli.EXPECT().ContainerExecCreate(gomock.Any(), tt.exec, container.ExecOptions{AttachStdout: true, AttachStderr: true, Cmd: []string{"pg_dump", "app"}, Env: []string{"MOBYCRON_EXEC=token"}}).Return(types.IDResponse{ID: "exec1"}, nil)
+				cli.EXPECT().ContainerExecAttach(gomock.Any(), "exec1", gomock.Any()).Return(types.HijackedResponse{Conn: client, Reader: bufio.NewReader(client)}, nil)
+				cli.EXPECT().ContainerExecInspect(gomock.Any(), "exec1")
+			}
+
+			j := &ServiceJob{
+				Schedule:    "1 * * * *",
+				Action:      "exec",
+				Command:     "pg_dump app",
+				Slot:        tt.slot,
+				ServiceID:   "ID1",
+				ServiceName: "db",
+				cron:        &Cron{sync: s},
+				cli:         cli,
+			}
+			out := &bytes.Buffer{}
+
+			// Act
+			err := j.runWith(out)
+
+			// Assert
+			for _, check := range tt.checks {
+				check(t, out.String(), err)
+			}
+		})
+	}
+}
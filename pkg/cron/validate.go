@@ -0,0 +1,87 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// validateNext is the number of next times computed for a valid schedule.
+const validateNext = 5
+
+// Validation is the result of the validation of a job: the next times of its
+// schedule when it is valid, or the reason it cannot be scheduled.
+type Validation struct {
+	Name     string
+	Source   string
+	Schedule string
+	Next     []time.Time
+	Err      error
+}
+
+// check validate job and its schedule, err being an error found while
+// reading the job.
+func (c *Cron) check(job scheduledJob, source string, err error) Validation {
+	v := Validation{
+		Name:     job.labels()["name"],
+		Source:   source,
+		Schedule: job.scheduleSpec(),
+		Err:      err,
+	}
+	if v.Err == nil {
+		v.Err = job.validate()
+	}
+	if v.Err != nil {
+		return v
+	}
+
+	schedule, err := newParser(c.second).Parse(v.Schedule)
+	if err != nil {
+		v.Err = errors.Wrap(err, "invalid schedule")
+		return v
+	}
+
+	v.Next = nextTimes(schedule, time.Now(), validateNext)
+	return v
+}
+
+// ValidateConfig validate all jobs of the config file, without adding them to
+// the Cron. An error is returned only when the file cannot be read or parsed.
+func (c *Cron) ValidateConfig(filename string) ([]Validation, error) {
+	config, err := afero.ReadFile(c.fs, filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read config file")
+	}
+
+	jobs, err := parseConfig(config, c.configFormat(filename), c.second)
+	if err != nil {
+		return nil, err
+	}
+
+	validations := []Validation{}
+	names := map[string]bool{}
+	for i := range jobs {
+		var err error
+		if name := jobs[i].Name; name != "" {
+			if names[name] {
+				err = errors.Errorf("job name '%s' is already used by another job", name)
+			}
+			names[name] = true
+		}
+		validations = append(validations, c.check(&jobs[i], "file", err))
+	}
+	return validations, nil
+}
+
+// ValidateContainerJob validate a container job without adding it to the
+// Cron, err being an error found while reading its labels.
+func (c *Cron) ValidateContainerJob(job ContainerJob, err error) Validation {
+	return c.check(&job, "container", err)
+}
+
+// ValidateServiceJob validate a service job without adding it to the Cron,
+// err being an error found while reading its labels.
+func (c *Cron) ValidateServiceJob(job ServiceJob, err error) Validation {
+	return c.check(&job, "service", err)
+}
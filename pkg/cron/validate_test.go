@@ -0,0 +1,178 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestValidateConfig(t *testing.T) {
+	type checkFunc func(*testing.T, []Validation, error)
+	check := func(fns ...checkFunc) []checkFunc { return fns }
+
+	hasError := func(want string) checkFunc {
+		return func(t *testing.T, v []Validation, err error) {
+			assert.Assert(t, is.ErrorContains(err, want))
+		}
+	}
+
+	hasNilError := func() checkFunc {
+		return func(t *testing.T, v []Validation, err error) {
+			assert.NilError(t, err)
+		}
+	}
+
+	hasJobs := func(want int) checkFunc {
+		return func(t *testing.T, v []Validation, err error) {
+			assert.Equal(t, len(v), want)
+		}
+	}
+
+	hasValid := func(i int, name string, schedule string, next int) checkFunc {
+		return func(t *testing.T, v []Validation, err error) {
+			assert.NilError(t, v[i].Err)
+			assert.Equal(t, v[i].Name, name)
+			assert.Equal(t, v[i].Source, "file")
+			assert.Equal(t, v[i].Schedule, schedule)
+			assert.Equal(t, len(v[i].Next), next)
+			for k := 1; k < len(v[i].Next); k++ {
+				assert.Assert(t, v[i].Next[k].After(v[i].Next[k-1]))
+			}
+		}
+	}
+
+	hasInvalid := func(i int, name string, want string) checkFunc {
+		return func(t *testing.T, v []Validation, err error) {
+			assert.Equal(t, v[i].Name, name)
+			assert.Assert(t, is.ErrorContains(v[i].Err, want))
+			assert.Assert(t, is.Len(v[i].Next, 0))
+		}
+	}
+
+	tests := []struct {
+		name     string
+		filename string
+		second   bool
+		config   string
+		checks   []checkFunc
+	}{
+		{
+			name:     "all jobs are reported",
+			filename: "/configs/config.json",
+			config: `[
+						{"schedule": "0 2 * * *", "command": "backup.sh"},
+						{"schedule": "0 2 * * *", "command": "clean.sh", "action": "start"},
+						{"schedule": "0 25 * * *", "command": "report.sh"},
+						{"schedule": "0 0 30 2 *", "command": "never.sh"}
+					]`,
+			checks: check(
+				hasNilError(),
+				hasJobs(4),
+				hasValid(0, "backup.sh", "0 2 * * *", validateNext),
+				hasInvalid(1, "clean.sh", "invalid job action, only 'run' is permitted"),
+				hasInvalid(2, "report.sh", "invalid schedule"),
+				hasValid(3, "never.sh", "0 0 30 2 *", 0),
+			),
+		},
+		{
+			name:     "duplicate names",
+			filename: "/configs/config.json",
+			config: `[
+						{"name": "backup", "schedule": "0 2 * * *", "command": "backup.sh"},
+						{"name": "backup", "schedule": "0 3 * * *", "command": "backup.sh"}
+					]`,
+			checks: check(
+				hasNilError(),
+				hasJobs(2),
+				hasValid(0, "backup", "0 2 * * *", validateNext),
+				hasInvalid(1, "backup", "job name 'backup' is already used by another job"),
+			),
+		},
+		{
+			name:     "schedule with second",
+			filename: "/configs/config.json",
+			second:   true,
+			config:   `[{"schedule": "*/10 * * * * *", "command": "ping.sh"}]`,
+			checks: check(
+				hasNilError(),
+				hasJobs(1),
+				hasValid(0, "ping.sh", "*/10 * * * * *", validateNext),
+			),
+		},
+		{
+			name:     "file not found",
+			filename: "/configs/config.json",
+			checks: check(
+				hasError("failed to read config file"),
+			),
+		},
+		{
+			name:     "invalid file",
+			filename: "/configs/config.json",
+			config:   `[{"schedule": }]`,
+			checks: check(
+				hasError("failed to parse JSON data from config file"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			fs := afero.NewMemMapFs()
+			if tt.config != "" {
+				afero.WriteFile(fs, tt.filename, []byte(tt.config), 0640)
+			}
+			c := &Cron{fs: fs, second: tt.second}
+
+			// Act
+			v, err := c.ValidateConfig(tt.filename)
+
+			// Assert
+			for _, check := range tt.checks {
+				check(t, v, err)
+			}
+		})
+	}
+}
+
+func TestValidateContainerJob(t *testing.T) {
+	// Arrange
+	c := NewCron(false)
+	db := container.Summary{Names: []string{"/db"}}
+
+	// Act
+	valid := c.ValidateContainerJob(ContainerJob{Schedule: "@daily", Action: "start", Container: db}, nil)
+	invalid := c.ValidateContainerJob(ContainerJob{Schedule: "@daily", Action: "kill", Container: db}, nil)
+	label := c.ValidateContainerJob(ContainerJob{Schedule: "@daily", Action: "start", Container: db}, errors.New("invalid retry attempts"))
+
+	// Assert
+	assert.NilError(t, valid.Err)
+	assert.Equal(t, valid.Name, "db-start")
+	assert.Equal(t, valid.Source, "container")
+	assert.Equal(t, len(valid.Next), validateNext)
+	assert.Assert(t, valid.Next[0].After(time.Now()))
+	assert.Assert(t, is.ErrorContains(invalid.Err, "invalid container action"))
+	assert.Assert(t, is.ErrorContains(label.Err, "invalid retry attempts"))
+}
+
+func TestValidateServiceJob(t *testing.T) {
+	// Arrange
+	c := NewCron(false)
+
+	// Act
+	valid := c.ValidateServiceJob(ServiceJob{Schedule: "@hourly", Action: "update", ServiceName: "web"}, nil)
+	invalid := c.ValidateServiceJob(ServiceJob{Schedule: "@hourly", ServiceName: "web"}, nil)
+
+	// Assert
+	assert.NilError(t, valid.Err)
+	assert.Equal(t, valid.Name, "web-update")
+	assert.Equal(t, valid.Source, "service")
+	assert.Equal(t, len(valid.Next), validateNext)
+	assert.Assert(t, is.ErrorContains(invalid.Err, "invalid service action"))
+}